@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/ordersync"
+)
+
+// runBackfill implements `paddy-cap backfill --since 2023-01-01 --channel orderspace|woocommerce|all`
+func runBackfill(ctx context.Context, logger *slog.Logger, orderService *order.OrderService, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	sinceFlag := fs.String("since", "", "backfill orders created on or after this date (YYYY-MM-DD)")
+	channel := fs.String("channel", "all", "channel to backfill: orderspace, woocommerce or all")
+	pageSize := fs.Int("page-size", 100, "orders requested per API call")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *sinceFlag == "" {
+		fs.Usage()
+		return fmt.Errorf("--since is required")
+	}
+	since, err := time.Parse("2006-01-02", *sinceFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --since %q: %w", *sinceFlag, err)
+	}
+
+	switch *channel {
+	case order.Orderspace, order.WooCommerce, "all":
+	default:
+		return fmt.Errorf("invalid --channel %q: must be orderspace, woocommerce or all", *channel)
+	}
+
+	// Stop cleanly on Ctrl-C; the next run resumes from the last checkpoint
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backfiller := ordersync.NewBackfiller(logger, orderService, *pageSize)
+	return backfiller.Run(ctx, *channel, since)
+}
@@ -1,15 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
+	_ "time/tzdata" // so TIMEZONE works on hosts without a zoneinfo database
 
+	"github.com/dukerupert/paddy-cap/db"
 	"github.com/dukerupert/paddy-cap/server"
+	"github.com/dukerupert/paddy-cap/service/customer"
+	"github.com/dukerupert/paddy-cap/service/money"
 	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/ordersync"
+	"github.com/dukerupert/paddy-cap/service/planning"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+	"github.com/dukerupert/paddy-cap/service/shipping/easypost"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/text/language"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -22,12 +37,27 @@ type Config struct {
 	OrderspaceBaseURL      string
 	OrderspaceClientID     string
 	OrderspaceClientSecret string
+	OrderspaceTokenURL     string
+	OrderspaceRateLimit    float64
+	OrderspaceRateBurst    int
 	// Woocommerce Client
 	WooBaseURL        string
 	WooConsumerKey    string
 	WooConsumerSecret string
+	WooRateLimit      float64
+	WooRateBurst      int
 	// Database
 	ConnectionString string
+	// Sync
+	SyncInterval time.Duration
+	// Planning
+	WooLeadDays int
+	// Display
+	Locale   language.Tag
+	Location *time.Location
+	// Shipping labels, off without an API key
+	EasyPostAPIKey string
+	ShipFrom       shipping.Address
 }
 
 func GetEnv() Config {
@@ -47,6 +77,9 @@ func GetEnv() Config {
 	if orderspaceBaseURL == "" || orderspaceClientID == "" || orderspaceClientSecret == "" {
 		log.Fatal("Missing orderspace environment variables")
 	}
+	orderspaceTokenURL := os.Getenv("ORDERSPACE_TOKEN_URL")
+	orderspaceRateLimit := envFloat("ORDERSPACE_RATE_LIMIT", defaultRateLimit)
+	orderspaceRateBurst := envInt("ORDERSPACE_RATE_BURST", defaultRateBurst)
 
 	wooBaseURL := os.Getenv("WOO_BASE_URL")
 	wooConsumerKey := os.Getenv("WOO_CONSUMER_KEY")
@@ -55,45 +88,169 @@ func GetEnv() Config {
 	if wooBaseURL == "" || wooConsumerKey == "" || wooConsumerSecret == "" {
 		log.Fatal("Missing woocommerce environment variables")
 	}
+	wooRateLimit := envFloat("WOO_RATE_LIMIT", defaultRateLimit)
+	wooRateBurst := envInt("WOO_RATE_BURST", defaultRateBurst)
 
 	dbConnectionString := os.Getenv("DB_CONNECTION_STRING")
 
+	if dbConnectionString == "" {
+		log.Fatal("Missing database environment variables")
+	}
+
+	syncInterval := 5 * time.Minute
+	if v := os.Getenv("SYNC_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid SYNC_INTERVAL %q: %v", v, err)
+		}
+		syncInterval = d
+	}
+
+	wooLeadDays := envInt("WOO_LEAD_DAYS", defaultWooLeadDays)
+
+	locale := money.DefaultLanguage
+	if v := os.Getenv("LOCALE"); v != "" {
+		tag, err := language.Parse(v)
+		if err != nil {
+			log.Fatalf("Invalid LOCALE %q: %v", v, err)
+		}
+		locale = tag
+	}
+
+	// The business timezone decides which day an order was placed or is due on
+	location := time.Local
+	if v := os.Getenv("TIMEZONE"); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			log.Fatalf("Invalid TIMEZONE %q: %v", v, err)
+		}
+		location = loc
+	}
+
+	easyPostAPIKey := os.Getenv("EASYPOST_API_KEY")
+	shipFrom := shipping.Address{
+		Name:       os.Getenv("SHIP_FROM_NAME"),
+		Company:    os.Getenv("SHIP_FROM_COMPANY"),
+		Line1:      os.Getenv("SHIP_FROM_LINE1"),
+		Line2:      os.Getenv("SHIP_FROM_LINE2"),
+		City:       os.Getenv("SHIP_FROM_CITY"),
+		State:      os.Getenv("SHIP_FROM_STATE"),
+		PostalCode: os.Getenv("SHIP_FROM_POSTAL_CODE"),
+		Country:    os.Getenv("SHIP_FROM_COUNTRY"),
+		Email:      os.Getenv("SHIP_FROM_EMAIL"),
+		Phone:      os.Getenv("SHIP_FROM_PHONE"),
+	}
+	if shipFrom.Country == "" {
+		shipFrom.Country = "GB"
+	}
+	if easyPostAPIKey != "" {
+		normalized, err := shipping.NormalizeAddress(shipFrom)
+		if err != nil {
+			log.Fatalf("Invalid SHIP_FROM address: %v", err)
+		}
+		shipFrom = normalized
+	}
+
 	return Config{
-		Host:					host,
+		Host:                   host,
 		Port:                   port,
 		OrderspaceBaseURL:      orderspaceBaseURL,
 		OrderspaceClientID:     orderspaceClientID,
 		OrderspaceClientSecret: orderspaceClientSecret,
+		OrderspaceTokenURL:     orderspaceTokenURL,
+		OrderspaceRateLimit:    orderspaceRateLimit,
+		OrderspaceRateBurst:    orderspaceRateBurst,
 		WooBaseURL:             wooBaseURL,
 		WooConsumerKey:         wooConsumerKey,
 		WooConsumerSecret:      wooConsumerSecret,
+		WooRateLimit:           wooRateLimit,
+		WooRateBurst:           wooRateBurst,
 		ConnectionString:       dbConnectionString,
+		SyncInterval:           syncInterval,
+		WooLeadDays:            wooLeadDays,
+		Locale:                 locale,
+		Location:               location,
+		EasyPostAPIKey:         easyPostAPIKey,
+		ShipFrom:               shipFrom,
+	}
+}
+
+// Default client-side API limits, overridable per channel. A rate of 0 disables limiting.
+const (
+	defaultRateLimit = 5.0 // requests per second
+	defaultRateBurst = 10
+)
+
+// defaultWooLeadDays is how long after being placed a WooCommerce order is expected to be delivered
+const defaultWooLeadDays = 2
+
+func envFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", key, v, err)
+	}
+	return f
+}
+
+func envInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
 	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", key, v, err)
+	}
+	return n
 }
 
 func main() {
 	// getEnv
 	cfg := GetEnv()
+	money.DefaultLanguage = cfg.Locale
 
 	// Setup logger
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	slog.SetDefault(logger)
 
+	// Cancelled on Ctrl-C or SIGTERM, which stops the sync worker and the server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	pool := connectDB(ctx, cfg)
+	defer pool.Close()
+
 	// Init Services
-	orderService := order.New(logger, order.OrderServiceConfig{
-		WooBaseURL: cfg.WooBaseURL,
-		WooConsumerKey: cfg.WooConsumerKey,
-		WooConsumerSecret: cfg.WooConsumerSecret,
-		OrderspaceBaseURL: cfg.OrderspaceBaseURL,
-		OrderspaceClientID: cfg.OrderspaceClientID,
-		OrderspaceClientSecret: cfg.OrderspaceClientSecret,
-	})
+	orderService := newOrderService(logger, cfg, pool)
+
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(ctx, logger, orderService, os.Args[2:]); err != nil {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+		return
+	}
+
+	// Start background order sync
+	syncWorker := ordersync.New(logger, ordersync.WorkerConfig{
+		Interval: cfg.SyncInterval,
+	}, orderService)
+	var workers sync.WaitGroup
+	workers.Go(func() { syncWorker.Run(ctx) })
 
 	// Init server handler
+	customerService := customer.New(logger, orderService.OrderspaceClient, orderService.WooClient, orderService.Queries)
+	planner := planning.New(logger, planning.Config{
+		WooLeadDays: cfg.WooLeadDays,
+		Location:    cfg.Location,
+	}, orderService.Queries)
 	srv := server.New(logger, server.ServerConfig{
 		Host: cfg.Host,
 		Port: cfg.Port,
-	}, orderService)
+	}, orderService, customerService, planner)
 
 	// Start server
 	s := &http.Server{
@@ -104,5 +261,61 @@ func main() {
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	log.Fatal(s.ListenAndServe())
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- s.ListenAndServe() }()
+	select {
+	case err := <-serveErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server shutdown failed", "error_message", err)
+	}
+	// Wait for the sync in progress to stop before the pool is closed
+	workers.Wait()
+}
+
+// connectDB opens the Postgres pool and applies pending migrations
+func connectDB(ctx context.Context, cfg Config) *pgxpool.Pool {
+	pool, err := pgxpool.New(ctx, cfg.ConnectionString)
+	if err != nil {
+		log.Fatalf("Unable to create connection pool: %v", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+
+	if err := db.Migrate(ctx, pool); err != nil {
+		log.Fatalf("Unable to migrate database: %v", err)
+	}
+
+	return pool
+}
+
+func newOrderService(logger *slog.Logger, cfg Config, pool *pgxpool.Pool) *order.OrderService {
+	var carrier shipping.Carrier
+	if cfg.EasyPostAPIKey != "" {
+		carrier = easypost.NewClient(cfg.EasyPostAPIKey)
+	}
+	return order.New(logger, order.OrderServiceConfig{
+		WooBaseURL:             cfg.WooBaseURL,
+		WooConsumerKey:         cfg.WooConsumerKey,
+		WooConsumerSecret:      cfg.WooConsumerSecret,
+		OrderspaceBaseURL:      cfg.OrderspaceBaseURL,
+		OrderspaceClientID:     cfg.OrderspaceClientID,
+		OrderspaceClientSecret: cfg.OrderspaceClientSecret,
+		OrderspaceTokenURL:     cfg.OrderspaceTokenURL,
+		OrderspaceRateLimit:    cfg.OrderspaceRateLimit,
+		OrderspaceRateBurst:    cfg.OrderspaceRateBurst,
+		WooRateLimit:           cfg.WooRateLimit,
+		WooRateBurst:           cfg.WooRateBurst,
+		Location:               cfg.Location,
+		Carrier:                carrier,
+		ShipFrom:               cfg.ShipFrom,
+	}, pool)
 }
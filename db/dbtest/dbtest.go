@@ -0,0 +1,228 @@
+// Package dbtest provides an in-memory stand-in for the Postgres pool, so code
+// built on the generated queries can be tested without a database.
+//
+// The fake doesn't run SQL. It records every query by its name in db/queries
+// and answers it from a Handler registered for that name. Queries without a
+// handler succeed: Query returns no rows, and QueryRow returns a row that
+// scans nothing, leaving the destination zero.
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Handler answers a query from its arguments with the rows it returns, each
+// holding the values of the query's columns in order. Returning no rows to a
+// :one query makes it fail with pgx.ErrNoRows.
+type Handler func(args []any) ([][]any, error)
+
+// Call is one query run against the DB
+type Call struct {
+	Name string
+	Args []any
+}
+
+// DB is a fake database. It satisfies db.DBTX and hands out transactions
+// through BeginTx like a *pgxpool.Pool.
+type DB struct {
+	mu       sync.Mutex
+	handlers map[string]Handler
+	calls    []Call
+	commits  int
+}
+
+func New() *DB {
+	return &DB{handlers: map[string]Handler{}}
+}
+
+// Handle answers the named query with h from now on
+func (d *DB) Handle(name string, h Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[name] = h
+}
+
+// Calls returns every run of the named query, oldest first
+func (d *DB) Calls(name string) []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var calls []Call
+	for _, c := range d.calls {
+		if c.Name == name {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Commits returns how many transactions have been committed
+func (d *DB) Commits() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.commits
+}
+
+func (d *DB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	_, err := d.run(sql, args)
+	return pgconn.CommandTag{}, err
+}
+
+func (d *DB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	values, err := d.run(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{values: values, next: -1}, nil
+}
+
+func (d *DB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	values, err := d.run(sql, args)
+	switch {
+	case err != nil:
+		return row{err: err}
+	case values == nil:
+		return row{}
+	case len(values) == 0:
+		return row{err: pgx.ErrNoRows}
+	}
+	return row{values: values[0]}
+}
+
+// BeginTx starts a transaction. Queries in it are recorded as they run, and
+// rolling back doesn't undo them.
+func (d *DB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return &tx{db: d}, nil
+}
+
+// run records a query and answers it. The rows are nil when nothing handles
+// the query, and non-nil but empty when its handler returned no rows.
+func (d *DB) run(sql string, args []any) ([][]any, error) {
+	name := queryName(sql)
+	d.mu.Lock()
+	d.calls = append(d.calls, Call{Name: name, Args: args})
+	h := d.handlers[name]
+	d.mu.Unlock()
+
+	if h == nil {
+		return nil, nil
+	}
+	values, err := h(args)
+	if err == nil && values == nil {
+		values = [][]any{}
+	}
+	return values, err
+}
+
+// queryName reads the name sqlc puts at the top of every query
+func queryName(sql string) string {
+	name, ok := strings.CutPrefix(sql, "-- name: ")
+	if !ok {
+		return ""
+	}
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// tx is a transaction on the fake DB. Methods the generated queries don't use
+// are left to the embedded nil interface.
+type tx struct {
+	pgx.Tx
+	db *DB
+}
+
+func (t *tx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.db.Exec(ctx, sql, args...)
+}
+
+func (t *tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.db.Query(ctx, sql, args...)
+}
+
+func (t *tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.db.QueryRow(ctx, sql, args...)
+}
+
+func (t *tx) Commit(ctx context.Context) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.commits++
+	return nil
+}
+
+func (t *tx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+type row struct {
+	values []any
+	err    error
+}
+
+func (r row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scan(r.values, dest)
+}
+
+// rows iterates over a handler's rows. Like row, methods the generated
+// queries don't use are left to the embedded nil interface.
+type rows struct {
+	pgx.Rows
+	values [][]any
+	next   int
+}
+
+func (r *rows) Next() bool {
+	r.next++
+	return r.next < len(r.values)
+}
+
+func (r *rows) Scan(dest ...any) error {
+	return scan(r.values[r.next], dest)
+}
+
+func (r *rows) Close()     {}
+func (r *rows) Err() error { return nil }
+
+// scan copies column values into the destinations of a Scan. A nil value
+// leaves its destination untouched; numbers convert between Go types.
+func scan(values, dest []any) error {
+	if len(values) > 0 && len(values) != len(dest) {
+		return fmt.Errorf("dbtest: row has %d values, scanning %d columns", len(values), len(dest))
+	}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		to := reflect.ValueOf(dest[i]).Elem()
+		from := reflect.ValueOf(v)
+		switch {
+		case from.Type().AssignableTo(to.Type()):
+			to.Set(from)
+		case numeric(from.Kind()) && numeric(to.Kind()):
+			to.Set(from.Convert(to.Type()))
+		default:
+			return fmt.Errorf("dbtest: can't scan %T into %s for column %d", v, to.Type(), i)
+		}
+	}
+	return nil
+}
+
+func numeric(k reflect.Kind) bool {
+	return slices.Contains([]reflect.Kind{
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+	}, k)
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS addresses;
+DROP TABLE IF EXISTS order_lines;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS customers;
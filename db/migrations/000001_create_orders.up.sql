@@ -0,0 +1,70 @@
+CREATE TABLE customers (
+    id           BIGSERIAL PRIMARY KEY,
+    origin       TEXT        NOT NULL,
+    external_id  TEXT        NOT NULL,
+    company_name TEXT        NOT NULL DEFAULT '',
+    contact_name TEXT        NOT NULL DEFAULT '',
+    email        TEXT        NOT NULL DEFAULT '',
+    phone        TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (origin, external_id)
+);
+
+CREATE TABLE orders (
+    id             BIGSERIAL PRIMARY KEY,
+    origin         TEXT           NOT NULL,
+    external_id    TEXT           NOT NULL,
+    number         TEXT           NOT NULL DEFAULT '',
+    status         TEXT           NOT NULL DEFAULT '',
+    customer_id    BIGINT REFERENCES customers (id) ON DELETE SET NULL,
+    currency       TEXT           NOT NULL DEFAULT '',
+    net_total      NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    tax_total      NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    shipping_total NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    gross_total    NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    delivery_date  DATE,
+    customer_note  TEXT           NOT NULL DEFAULT '',
+    placed_at      TIMESTAMPTZ    NOT NULL,
+    modified_at    TIMESTAMPTZ,
+    raw            JSONB          NOT NULL,
+    synced_at      TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    UNIQUE (origin, external_id)
+);
+
+CREATE INDEX orders_placed_at_idx ON orders (placed_at DESC);
+CREATE INDEX orders_customer_id_idx ON orders (customer_id);
+
+CREATE TABLE order_lines (
+    id          BIGSERIAL PRIMARY KEY,
+    order_id    BIGINT         NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    external_id TEXT           NOT NULL,
+    sku         TEXT           NOT NULL DEFAULT '',
+    name        TEXT           NOT NULL DEFAULT '',
+    options     TEXT           NOT NULL DEFAULT '',
+    quantity    INTEGER        NOT NULL DEFAULT 0,
+    unit_price  NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    sub_total   NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    tax_amount  NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    dispatched  INTEGER        NOT NULL DEFAULT 0,
+    UNIQUE (order_id, external_id)
+);
+
+CREATE INDEX order_lines_sku_idx ON order_lines (sku);
+
+CREATE TABLE addresses (
+    id           BIGSERIAL PRIMARY KEY,
+    order_id     BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    kind         TEXT   NOT NULL CHECK (kind IN ('billing', 'shipping')),
+    company_name TEXT   NOT NULL DEFAULT '',
+    contact_name TEXT   NOT NULL DEFAULT '',
+    line1        TEXT   NOT NULL DEFAULT '',
+    line2        TEXT   NOT NULL DEFAULT '',
+    city         TEXT   NOT NULL DEFAULT '',
+    state        TEXT   NOT NULL DEFAULT '',
+    postal_code  TEXT   NOT NULL DEFAULT '',
+    country      TEXT   NOT NULL DEFAULT '',
+    email        TEXT   NOT NULL DEFAULT '',
+    phone        TEXT   NOT NULL DEFAULT '',
+    UNIQUE (order_id, kind)
+);
@@ -0,0 +1,6 @@
+CREATE TABLE sync_state (
+    channel         TEXT PRIMARY KEY,
+    high_water_mark TIMESTAMPTZ NOT NULL,
+    last_run_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    orders_synced   INTEGER     NOT NULL DEFAULT 0
+);
@@ -0,0 +1,8 @@
+CREATE TABLE backfill_state (
+    channel        TEXT PRIMARY KEY,
+    since          TIMESTAMPTZ NOT NULL,
+    cursor         TEXT        NOT NULL DEFAULT '',
+    orders_written INTEGER     NOT NULL DEFAULT 0,
+    completed_at   TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,21 @@
+CREATE TABLE inventory_items (
+    sku             TEXT PRIMARY KEY,
+    name            TEXT        NOT NULL DEFAULT '',
+    tracked_since   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    pushed_quantity INTEGER,
+    pushed_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE inventory_movements (
+    id         BIGSERIAL PRIMARY KEY,
+    sku        TEXT        NOT NULL REFERENCES inventory_items (sku) ON DELETE CASCADE,
+    kind       TEXT        NOT NULL CHECK (kind IN ('order', 'adjustment', 'stocktake')),
+    quantity   INTEGER     NOT NULL,
+    order_id   BIGINT REFERENCES orders (id) ON DELETE CASCADE,
+    note       TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (order_id, sku)
+);
+
+CREATE INDEX inventory_movements_sku_idx ON inventory_movements (sku, created_at DESC);
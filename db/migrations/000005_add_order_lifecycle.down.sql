@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orders_lifecycle_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS lifecycle;
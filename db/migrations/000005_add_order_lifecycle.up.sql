@@ -0,0 +1,47 @@
+-- lifecycle is the order's status mapped to the lifecycle shared by every
+-- channel (see order.Status), so orders can be filtered by it across channels.
+ALTER TABLE orders ADD COLUMN lifecycle TEXT NOT NULL DEFAULT '';
+
+UPDATE orders SET lifecycle = CASE
+    WHEN origin = 'orderspace' THEN CASE status
+        WHEN 'new' THEN 'new'
+        WHEN 'awaiting_payment' THEN 'awaiting_payment'
+        WHEN 'released' THEN 'ready_to_fulfil'
+        WHEN 'dispatched' THEN 'fulfilled'
+        WHEN 'fulfilled' THEN 'fulfilled'
+        WHEN 'completed' THEN 'fulfilled'
+        WHEN 'cancelled' THEN 'cancelled'
+        WHEN 'refunded' THEN 'refunded'
+        ELSE 'unknown'
+    END
+    WHEN origin = 'woocommerce' THEN CASE status
+        WHEN 'checkout-draft' THEN 'new'
+        WHEN 'pending' THEN 'awaiting_payment'
+        WHEN 'on-hold' THEN 'awaiting_payment'
+        WHEN 'failed' THEN 'awaiting_payment'
+        WHEN 'processing' THEN 'ready_to_fulfil'
+        WHEN 'completed' THEN 'fulfilled'
+        WHEN 'cancelled' THEN 'cancelled'
+        WHEN 'trash' THEN 'cancelled'
+        WHEN 'refunded' THEN 'refunded'
+        ELSE 'unknown'
+    END
+    ELSE 'unknown'
+END;
+
+-- Open Orderspace orders with some, but not all, goods dispatched
+UPDATE orders o SET lifecycle = 'partially_dispatched'
+WHERE o.origin = 'orderspace'
+  AND o.lifecycle IN ('new', 'awaiting_payment', 'ready_to_fulfil')
+  AND EXISTS (
+      SELECT 1 FROM jsonb_array_elements(o.raw -> 'order_lines') l
+      WHERE NOT coalesce((l ->> 'shipping')::boolean, false)
+        AND (l ->> 'dispatched')::int > 0
+  )
+  AND EXISTS (
+      SELECT 1 FROM jsonb_array_elements(o.raw -> 'order_lines') l
+      WHERE NOT coalesce((l ->> 'shipping')::boolean, false)
+        AND coalesce((l ->> 'dispatched')::int, 0) < (l ->> 'quantity')::int
+  );
+
+CREATE INDEX orders_lifecycle_idx ON orders (lifecycle, placed_at DESC);
@@ -0,0 +1,16 @@
+-- fulfilments records each time staff marked an order shipped from this app,
+-- keyed by the channel's order ID so it doesn't depend on the order having synced
+CREATE TABLE fulfilments (
+    id              BIGSERIAL PRIMARY KEY,
+    origin          TEXT        NOT NULL,
+    external_id     TEXT        NOT NULL,
+    dispatch_id     TEXT        NOT NULL DEFAULT '',
+    lines           JSONB       NOT NULL DEFAULT '[]',
+    carrier         TEXT        NOT NULL DEFAULT '',
+    tracking_number TEXT        NOT NULL DEFAULT '',
+    tracking_url    TEXT        NOT NULL DEFAULT '',
+    fulfilled_by    TEXT        NOT NULL,
+    fulfilled_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX fulfilments_order_idx ON fulfilments (origin, external_id, fulfilled_at DESC);
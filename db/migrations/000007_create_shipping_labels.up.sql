@@ -0,0 +1,22 @@
+-- shipping_labels records postage bought for an order through a shipping
+-- provider, keyed by the channel's order ID like fulfilments
+CREATE TABLE shipping_labels (
+    id              BIGSERIAL PRIMARY KEY,
+    origin          TEXT          NOT NULL,
+    external_id     TEXT          NOT NULL,
+    provider        TEXT          NOT NULL,
+    label_id        TEXT          NOT NULL,
+    carrier         TEXT          NOT NULL DEFAULT '',
+    service         TEXT          NOT NULL DEFAULT '',
+    tracking_number TEXT          NOT NULL DEFAULT '',
+    tracking_url    TEXT          NOT NULL DEFAULT '',
+    label_url       TEXT          NOT NULL DEFAULT '',
+    price           NUMERIC(12,2) NOT NULL,
+    currency        TEXT          NOT NULL,
+    bought_by       TEXT          NOT NULL,
+    bought_at       TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    voided_at       TIMESTAMPTZ,
+    UNIQUE (provider, label_id)
+);
+
+CREATE INDEX shipping_labels_order_idx ON shipping_labels (origin, external_id, bought_at DESC);
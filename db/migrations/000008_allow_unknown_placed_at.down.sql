@@ -0,0 +1,2 @@
+UPDATE orders SET placed_at = synced_at WHERE placed_at IS NULL;
+ALTER TABLE orders ALTER COLUMN placed_at SET NOT NULL;
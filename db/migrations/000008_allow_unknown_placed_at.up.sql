@@ -0,0 +1,4 @@
+-- placed_at is NULL when the channel sent a creation date that couldn't be
+-- parsed, so the order is still stored and the order list can say the date is
+-- unknown rather than sync stalling on it
+ALTER TABLE orders ALTER COLUMN placed_at DROP NOT NULL;
@@ -0,0 +1,12 @@
+-- shipping_quotes remembers which order each carrier quote was made for, so a
+-- label can only be bought from a quote for the same order's address and parcel
+CREATE TABLE shipping_quotes (
+    id          BIGSERIAL PRIMARY KEY,
+    origin      TEXT        NOT NULL,
+    external_id TEXT        NOT NULL,
+    provider    TEXT        NOT NULL,
+    quote_id    TEXT        NOT NULL,
+    rate_ids    TEXT[]      NOT NULL,
+    quoted_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (provider, quote_id)
+);
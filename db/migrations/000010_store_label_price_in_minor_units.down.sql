@@ -0,0 +1,9 @@
+ALTER TABLE shipping_labels ADD COLUMN price NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+UPDATE shipping_labels SET price = CASE
+    WHEN currency IN ('JPY', 'KRW', 'ISK', 'CLP', 'VND', 'UGX', 'XAF', 'XOF') THEN price_minor
+    WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN price_minor / 1000.0
+    ELSE price_minor / 100.0
+END;
+
+ALTER TABLE shipping_labels DROP COLUMN price_minor;
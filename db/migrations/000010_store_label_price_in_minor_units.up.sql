@@ -0,0 +1,11 @@
+-- price_minor is a label's price in its currency's minor units, as money.Money
+-- holds it, so currencies without two decimal places are stored exactly
+ALTER TABLE shipping_labels ADD COLUMN price_minor BIGINT NOT NULL DEFAULT 0;
+
+UPDATE shipping_labels SET price_minor = CASE
+    WHEN currency IN ('JPY', 'KRW', 'ISK', 'CLP', 'VND', 'UGX', 'XAF', 'XOF') THEN round(price)
+    WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN round(price * 1000)
+    ELSE round(price * 100)
+END;
+
+ALTER TABLE shipping_labels DROP COLUMN price;
@@ -0,0 +1,18 @@
+-- product_listings mirrors every product variant listed in either channel. The
+-- sync worker refreshes it now and then, so searching for products to order
+-- doesn't walk both channels' catalogues on every keystroke.
+CREATE TABLE product_listings (
+    origin      TEXT        NOT NULL,
+    product_id  TEXT        NOT NULL,
+    variant_id  TEXT        NOT NULL DEFAULT '',
+    sku         TEXT        NOT NULL DEFAULT '',
+    name        TEXT        NOT NULL,
+    price_minor BIGINT      NOT NULL,
+    currency    TEXT        NOT NULL,
+    active      BOOLEAN     NOT NULL,
+    orderable   BOOLEAN     NOT NULL,
+    synced_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (origin, product_id, variant_id)
+);
+
+CREATE INDEX product_listings_orderable_idx ON product_listings (origin, name) WHERE orderable;
@@ -0,0 +1,38 @@
+ALTER TABLE orders ADD COLUMN net_total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN shipping_total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN gross_total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN unit_price NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN sub_total NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+CREATE TEMPORARY TABLE order_scales ON COMMIT DROP AS
+SELECT id, CASE
+    WHEN currency IN ('JPY', 'KRW', 'ISK', 'CLP', 'VND', 'UGX', 'XAF', 'XOF') THEN 1.0
+    WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1000.0
+    ELSE 100.0
+END AS scale
+FROM orders;
+
+UPDATE orders o SET
+    net_total      = o.net_total_minor / s.scale,
+    tax_total      = o.tax_total_minor / s.scale,
+    shipping_total = o.shipping_total_minor / s.scale,
+    gross_total    = o.gross_total_minor / s.scale
+FROM order_scales s
+WHERE s.id = o.id;
+
+UPDATE order_lines l SET
+    unit_price = l.unit_price_minor / s.scale,
+    sub_total  = l.sub_total_minor / s.scale,
+    tax_amount = l.tax_amount_minor / s.scale
+FROM order_scales s
+WHERE s.id = l.order_id;
+
+ALTER TABLE orders DROP COLUMN net_total_minor;
+ALTER TABLE orders DROP COLUMN tax_total_minor;
+ALTER TABLE orders DROP COLUMN shipping_total_minor;
+ALTER TABLE orders DROP COLUMN gross_total_minor;
+ALTER TABLE order_lines DROP COLUMN unit_price_minor;
+ALTER TABLE order_lines DROP COLUMN sub_total_minor;
+ALTER TABLE order_lines DROP COLUMN tax_amount_minor;
@@ -0,0 +1,41 @@
+-- Order and line amounts are stored in their currency's minor units, as
+-- money.Money holds them, like shipping_labels.price_minor. NUMERIC(12, 2)
+-- couldn't hold currencies with three decimal places.
+ALTER TABLE orders ADD COLUMN net_total_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_total_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN shipping_total_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN gross_total_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN unit_price_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN sub_total_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN tax_amount_minor BIGINT NOT NULL DEFAULT 0;
+
+CREATE TEMPORARY TABLE order_scales ON COMMIT DROP AS
+SELECT id, CASE
+    WHEN currency IN ('JPY', 'KRW', 'ISK', 'CLP', 'VND', 'UGX', 'XAF', 'XOF') THEN 1
+    WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 1000
+    ELSE 100
+END AS scale
+FROM orders;
+
+UPDATE orders o SET
+    net_total_minor      = round(o.net_total * s.scale),
+    tax_total_minor      = round(o.tax_total * s.scale),
+    shipping_total_minor = round(o.shipping_total * s.scale),
+    gross_total_minor    = round(o.gross_total * s.scale)
+FROM order_scales s
+WHERE s.id = o.id;
+
+UPDATE order_lines l SET
+    unit_price_minor = round(l.unit_price * s.scale),
+    sub_total_minor  = round(l.sub_total * s.scale),
+    tax_amount_minor = round(l.tax_amount * s.scale)
+FROM order_scales s
+WHERE s.id = l.order_id;
+
+ALTER TABLE orders DROP COLUMN net_total;
+ALTER TABLE orders DROP COLUMN tax_total;
+ALTER TABLE orders DROP COLUMN shipping_total;
+ALTER TABLE orders DROP COLUMN gross_total;
+ALTER TABLE order_lines DROP COLUMN unit_price;
+ALTER TABLE order_lines DROP COLUMN sub_total;
+ALTER TABLE order_lines DROP COLUMN tax_amount;
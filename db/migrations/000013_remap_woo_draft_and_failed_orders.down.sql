@@ -0,0 +1,6 @@
+-- Failed orders hold stock again once the sync next saves them
+UPDATE orders SET lifecycle = 'new'
+WHERE origin = 'woocommerce' AND status = 'checkout-draft';
+
+UPDATE orders SET lifecycle = 'awaiting_payment'
+WHERE origin = 'woocommerce' AND status = 'failed';
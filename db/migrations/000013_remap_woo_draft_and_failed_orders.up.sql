@@ -0,0 +1,16 @@
+-- A WooCommerce checkout draft hasn't been paid for, and a failed order's
+-- payment was declined, so neither is an order to fulfil. Drafts wait for
+-- payment like pending orders; failed orders are treated as cancelled and
+-- give back the stock they held.
+UPDATE orders SET lifecycle = 'awaiting_payment'
+WHERE origin = 'woocommerce' AND status = 'checkout-draft';
+
+UPDATE orders SET lifecycle = 'cancelled'
+WHERE origin = 'woocommerce' AND status = 'failed';
+
+DELETE FROM inventory_movements m
+USING orders o
+WHERE m.order_id = o.id
+  AND m.kind = 'order'
+  AND o.origin = 'woocommerce'
+  AND o.status = 'failed';
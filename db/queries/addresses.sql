@@ -0,0 +1,28 @@
+-- name: UpsertAddress :one
+INSERT INTO addresses (
+    order_id, kind, company_name, contact_name, line1, line2,
+    city, state, postal_code, country, email, phone
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (order_id, kind) DO UPDATE
+SET company_name = EXCLUDED.company_name,
+    contact_name = EXCLUDED.contact_name,
+    line1        = EXCLUDED.line1,
+    line2        = EXCLUDED.line2,
+    city         = EXCLUDED.city,
+    state        = EXCLUDED.state,
+    postal_code  = EXCLUDED.postal_code,
+    country      = EXCLUDED.country,
+    email        = EXCLUDED.email,
+    phone        = EXCLUDED.phone
+RETURNING *;
+
+-- name: ListAddressesByOrder :many
+SELECT * FROM addresses
+WHERE order_id = $1
+ORDER BY kind;
+
+-- name: ListShippingAddressesByOrders :many
+SELECT * FROM addresses
+WHERE order_id = ANY(@order_ids::bigint[])
+  AND kind = 'shipping';
@@ -0,0 +1,27 @@
+-- name: GetBackfillState :one
+SELECT * FROM backfill_state
+WHERE channel = $1;
+
+-- name: StartBackfill :one
+INSERT INTO backfill_state (channel, since)
+VALUES ($1, $2)
+ON CONFLICT (channel) DO UPDATE
+SET since          = EXCLUDED.since,
+    cursor         = '',
+    orders_written = 0,
+    completed_at   = NULL,
+    updated_at     = now()
+RETURNING *;
+
+-- name: UpdateBackfillCursor :exec
+UPDATE backfill_state
+SET cursor         = $2,
+    orders_written = $3,
+    updated_at     = now()
+WHERE channel = $1;
+
+-- name: CompleteBackfill :exec
+UPDATE backfill_state
+SET completed_at = now(),
+    updated_at   = now()
+WHERE channel = $1;
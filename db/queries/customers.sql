@@ -0,0 +1,50 @@
+-- name: UpsertCustomer :one
+INSERT INTO customers (origin, external_id, company_name, contact_name, email, phone)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (origin, external_id) DO UPDATE
+SET company_name = EXCLUDED.company_name,
+    contact_name = EXCLUDED.contact_name,
+    email        = EXCLUDED.email,
+    phone        = EXCLUDED.phone,
+    updated_at   = now()
+RETURNING *;
+
+-- name: GetCustomer :one
+SELECT * FROM customers
+WHERE id = $1;
+
+-- name: GetCustomerByExternalID :one
+SELECT * FROM customers
+WHERE origin = $1 AND external_id = $2;
+
+-- name: ListCustomers :many
+SELECT * FROM customers
+ORDER BY id;
+
+-- name: ListCustomerAddresses :many
+SELECT o.customer_id::bigint AS customer_id, a.company_name, a.line1, a.postal_code
+FROM addresses a
+JOIN orders o ON o.id = a.order_id
+WHERE o.customer_id IS NOT NULL
+GROUP BY o.customer_id, a.company_name, a.line1, a.postal_code;
+
+-- name: ListCustomerOrderTotals :many
+SELECT o.customer_id::bigint AS customer_id,
+       o.lifecycle,
+       o.currency,
+       count(*) AS order_count,
+       coalesce(sum(o.gross_total_minor), 0)::bigint AS lifetime_value_minor,
+       max(o.placed_at) AS last_order_at
+FROM orders o
+WHERE o.customer_id IS NOT NULL
+GROUP BY o.customer_id, o.lifecycle, o.currency;
+
+-- name: SearchCustomers :many
+SELECT * FROM customers
+WHERE origin = @origin::text
+  AND (company_name ILIKE '%' || @pattern::text || '%'
+       OR contact_name ILIKE '%' || @pattern::text || '%'
+       OR email ILIKE '%' || @pattern::text || '%'
+       OR phone ILIKE '%' || @pattern::text || '%')
+ORDER BY company_name, contact_name, id
+LIMIT @max_results::integer;
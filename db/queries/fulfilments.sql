@@ -0,0 +1,9 @@
+-- name: InsertFulfilment :one
+INSERT INTO fulfilments (origin, external_id, dispatch_id, lines, carrier, tracking_number, tracking_url, fulfilled_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListOrderFulfilments :many
+SELECT * FROM fulfilments
+WHERE origin = $1 AND external_id = $2
+ORDER BY fulfilled_at DESC;
@@ -0,0 +1,41 @@
+-- name: UpsertInventoryItem :one
+INSERT INTO inventory_items (sku, name)
+VALUES ($1, $2)
+ON CONFLICT (sku) DO UPDATE
+SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: ListInventoryItemsBySKUs :many
+SELECT * FROM inventory_items
+WHERE sku = ANY(@skus::text[]);
+
+-- name: ListInventoryLevels :many
+SELECT i.sku, i.name, i.tracked_since, i.pushed_quantity, i.pushed_at,
+       coalesce(sum(m.quantity), 0)::integer AS quantity
+FROM inventory_items i
+LEFT JOIN inventory_movements m ON m.sku = i.sku
+GROUP BY i.sku
+ORDER BY i.sku;
+
+-- name: GetInventoryLevel :one
+SELECT i.sku, i.name, i.tracked_since, i.pushed_quantity, i.pushed_at,
+       coalesce(sum(m.quantity), 0)::integer AS quantity
+FROM inventory_items i
+LEFT JOIN inventory_movements m ON m.sku = i.sku
+WHERE i.sku = $1
+GROUP BY i.sku;
+
+-- name: InsertInventoryMovement :one
+INSERT INTO inventory_movements (sku, kind, quantity, order_id, note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: DeleteOrderMovements :exec
+DELETE FROM inventory_movements
+WHERE order_id = $1;
+
+-- name: MarkInventoryPushed :exec
+UPDATE inventory_items
+SET pushed_quantity = $2,
+    pushed_at       = now()
+WHERE sku = $1;
@@ -0,0 +1,84 @@
+-- name: UpsertOrder :one
+INSERT INTO orders (
+    origin, external_id, number, status, lifecycle, customer_id, currency,
+    net_total_minor, tax_total_minor, shipping_total_minor, gross_total_minor,
+    delivery_date, customer_note, placed_at, modified_at, raw
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+ON CONFLICT (origin, external_id) DO UPDATE
+SET number               = EXCLUDED.number,
+    status               = EXCLUDED.status,
+    lifecycle            = EXCLUDED.lifecycle,
+    customer_id          = EXCLUDED.customer_id,
+    currency             = EXCLUDED.currency,
+    net_total_minor      = EXCLUDED.net_total_minor,
+    tax_total_minor      = EXCLUDED.tax_total_minor,
+    shipping_total_minor = EXCLUDED.shipping_total_minor,
+    gross_total_minor    = EXCLUDED.gross_total_minor,
+    delivery_date        = EXCLUDED.delivery_date,
+    customer_note        = EXCLUDED.customer_note,
+    placed_at            = EXCLUDED.placed_at,
+    modified_at          = EXCLUDED.modified_at,
+    raw                  = EXCLUDED.raw,
+    synced_at            = now()
+RETURNING *;
+
+-- name: GetOrder :one
+SELECT * FROM orders
+WHERE id = $1;
+
+-- name: GetOrderByExternalID :one
+SELECT * FROM orders
+WHERE origin = $1 AND external_id = $2;
+
+-- name: ListOrders :many
+SELECT * FROM orders
+ORDER BY placed_at DESC NULLS LAST
+LIMIT $1 OFFSET $2;
+
+-- name: ListOrdersByLifecycle :many
+SELECT * FROM orders
+WHERE lifecycle = $1
+ORDER BY placed_at DESC NULLS LAST
+LIMIT $2 OFFSET $3;
+
+-- name: ListOrdersByCustomer :many
+SELECT * FROM orders
+WHERE customer_id = $1
+ORDER BY placed_at DESC NULLS LAST;
+
+-- name: ListOrdersByCustomers :many
+SELECT * FROM orders
+WHERE customer_id = ANY(@customer_ids::bigint[])
+ORDER BY placed_at DESC NULLS LAST;
+
+-- name: CountOrders :one
+SELECT count(*) FROM orders;
+
+-- name: DeleteOrderLines :exec
+DELETE FROM order_lines
+WHERE order_id = $1;
+
+-- name: InsertOrderLine :one
+INSERT INTO order_lines (
+    order_id, external_id, sku, name, options, quantity,
+    unit_price_minor, sub_total_minor, tax_amount_minor, dispatched
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListOrderLines :many
+SELECT * FROM order_lines
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: ListOpenOrders :many
+SELECT * FROM orders
+WHERE lifecycle = ANY(@lifecycles::text[])
+ORDER BY placed_at;
+
+-- name: ListOrdersByDeliveryDate :many
+SELECT * FROM orders
+WHERE delivery_date BETWEEN @from_date::date AND @to_date::date
+  AND lifecycle <> ALL(@hidden_lifecycles::text[])
+ORDER BY delivery_date, placed_at;
@@ -0,0 +1,31 @@
+-- name: UpsertProductListing :exec
+INSERT INTO product_listings (origin, product_id, variant_id, sku, name, price_minor, currency, active, orderable, synced_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (origin, product_id, variant_id) DO UPDATE SET
+    sku         = EXCLUDED.sku,
+    name        = EXCLUDED.name,
+    price_minor = EXCLUDED.price_minor,
+    currency    = EXCLUDED.currency,
+    active      = EXCLUDED.active,
+    orderable   = EXCLUDED.orderable,
+    synced_at   = EXCLUDED.synced_at;
+
+-- name: DeleteProductListingsSyncedBefore :exec
+DELETE FROM product_listings
+WHERE origin = $1 AND synced_at < $2;
+
+-- name: SearchOrderableListings :many
+SELECT * FROM product_listings
+WHERE origin = @origin::text AND orderable
+  AND (name ILIKE '%' || @pattern::text || '%' OR sku ILIKE '%' || @pattern::text || '%')
+ORDER BY name, sku
+LIMIT @max_results::integer;
+
+-- name: GetProductListing :one
+SELECT * FROM product_listings
+WHERE origin = $1 AND product_id = $2 AND variant_id = $3;
+
+-- name: ListProductListingsBySKUs :many
+SELECT * FROM product_listings
+WHERE upper(btrim(sku)) = ANY(@skus::text[])
+ORDER BY origin, product_id, variant_id;
@@ -0,0 +1,18 @@
+-- name: InsertShippingLabel :one
+INSERT INTO shipping_labels (origin, external_id, provider, label_id, carrier, service, tracking_number, tracking_url, label_url, price_minor, currency, bought_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
+-- name: ListOrderShippingLabels :many
+SELECT * FROM shipping_labels
+WHERE origin = $1 AND external_id = $2
+ORDER BY bought_at DESC;
+
+-- name: GetShippingLabel :one
+SELECT * FROM shipping_labels
+WHERE origin = $1 AND external_id = $2 AND id = $3;
+
+-- name: VoidShippingLabel :one
+UPDATE shipping_labels SET voided_at = now()
+WHERE id = $1 AND voided_at IS NULL
+RETURNING *;
@@ -0,0 +1,7 @@
+-- name: InsertShippingQuote :exec
+INSERT INTO shipping_quotes (origin, external_id, provider, quote_id, rate_ids)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetShippingQuote :one
+SELECT * FROM shipping_quotes
+WHERE provider = $1 AND quote_id = $2;
@@ -0,0 +1,11 @@
+-- name: GetSyncState :one
+SELECT * FROM sync_state
+WHERE channel = $1;
+
+-- name: UpsertSyncState :exec
+INSERT INTO sync_state (channel, high_water_mark, orders_synced)
+VALUES ($1, $2, $3)
+ON CONFLICT (channel) DO UPDATE
+SET high_water_mark = EXCLUDED.high_water_mark,
+    orders_synced   = EXCLUDED.orders_synced,
+    last_run_at     = now();
@@ -8,7 +8,6 @@ import (
 	"io"
 	"net/http"
 	"path/filepath"
-	"strconv"
 	"strings"
 )
 
@@ -66,55 +65,7 @@ func (tr *TemplateRenderer) parseTemplates() error {
 			"even": func(i int) bool {
 				return i%2 == 0
 			},
-			"subtract": func(a, b float64) float64 {
-				return a - b
-			},
 			"title": strings.Title,
-			"subtractFloat": func(total, tax, shipping, shippingTax string) string {
-				// Convert strings to floats, subtract, return as string
-				// Convert strings to float64
-				totalFloat, err := strconv.ParseFloat(total, 64)
-				if err != nil {
-					return "0" // Return "0" on parsing error
-				}
-
-				taxFloat, err := strconv.ParseFloat(tax, 64)
-				if err != nil {
-					return "0"
-				}
-
-				shippingFloat, err := strconv.ParseFloat(shipping, 64)
-				if err != nil {
-					return "0"
-				}
-
-				shippingTaxFloat, err := strconv.ParseFloat(shippingTax, 64)
-				if err != nil {
-					return "0"
-				}
-
-				// Calculate: total - tax - shipping - shippingTax
-				result := totalFloat - taxFloat - shippingFloat - shippingTaxFloat
-
-				// Convert back to string with appropriate formatting
-				return fmt.Sprintf("%.2f", result)
-			},
-			"divideFloat": func(subtotal string, quantity int) float64 {
-				// Convert subtotal string to float, divide by quantity
-				/// Convert subtotal string to float64
-				subtotalFloat, err := strconv.ParseFloat(subtotal, 64)
-				if err != nil {
-					return 0.0 // Return 0.0 on parsing error
-				}
-
-				// Handle division by zero
-				if quantity == 0 {
-					return 0.0
-				}
-
-				// Divide subtotal by quantity
-				return subtotalFloat / float64(quantity)
-			},
 		}
 
 		// Parse the combined templates
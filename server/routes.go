@@ -1,21 +1,55 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"sort"
 	"strconv"
-	"sync"
+	"strings"
+	"time"
 
+	"github.com/dukerupert/paddy-cap/service/customer"
+	"github.com/dukerupert/paddy-cap/service/money"
 	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/planning"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+	"github.com/dukerupert/paddy-cap/service/transport"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
 )
 
-func addRoutes(l *slog.Logger, m *http.ServeMux, t *TemplateRenderer, o *order.OrderService) {
+func addRoutes(l *slog.Logger, m *http.ServeMux, t *TemplateRenderer, o *order.OrderService, c *customer.CustomerService, p *planning.Planner) {
 	m.Handle("GET /", handleHome(t))
 	m.Handle("GET /healthz", handleHealthZ())
+	m.Handle("GET /channels/status", handleChannelStatus(l, o))
 	m.Handle("GET /orders", handleGetOrders(l, t, o))
+	m.Handle("POST /orders", handleCreateOrder(l, o))
+	m.Handle("GET /orders/new", handleNewOrder(t))
+	m.Handle("GET /orders/new/customers", handleSearchOrderCustomers(l, c))
+	m.Handle("GET /orders/new/products", handleSearchOrderProducts(l, o))
 	m.Handle("GET /orders/{origin}/{id}", handleGetOrder(l, t, o))
+	m.Handle("GET /orders/{origin}/{id}/packing-slip", handleGetPackingSlip(l, t, o))
+	m.Handle("POST /orders/{origin}/{id}/fulfilments", handleFulfilOrder(l, o))
+	m.Handle("POST /orders/{origin}/{id}/shipping-quotes", handleQuoteShipping(l, o))
+	m.Handle("POST /orders/{origin}/{id}/shipping-labels", handleBuyShippingLabel(l, o))
+	m.Handle("POST /orders/{origin}/{id}/shipping-labels/{label}/void", handleVoidShippingLabel(l, o))
+	m.Handle("GET /orders/{origin}/{id}/shipping-labels/{label}/tracking", handleTrackShippingLabel(l, o))
+	m.Handle("GET /pick-list", handleGetPickList(l, t, o))
+	m.Handle("GET /customers", handleGetCustomers(l, t, c))
+	m.Handle("GET /customers/orderspace/{id}", handleGetOrderspaceCustomer(l, t, o, c))
+	m.Handle("GET /customers/all", handleGetUnifiedCustomers(l, t, o))
+	m.Handle("GET /customers/all/{id}", handleGetUnifiedCustomer(l, t, o))
+	m.Handle("GET /products", handleGetProducts(l, t, o))
+	m.Handle("GET /inventory", handleGetInventory(l, t, o))
+	m.Handle("POST /inventory", handleTrackSKU(l, o))
+	m.Handle("POST /inventory/push", handlePushStock(l, o))
+	m.Handle("POST /inventory/{sku}/adjustments", handleAdjustStock(l, o))
+	m.Handle("POST /inventory/{sku}/stocktakes", handleStockTake(l, o))
+	m.Handle("GET /planning", handleGetRoastPlan(l, t, p))
+	m.Handle("GET /deliveries", handleGetDeliveries(l, t, o))
+	m.Handle("GET /deliveries.ics", handleGetDeliveriesICS(l, o))
 
 }
 
@@ -47,55 +81,40 @@ func handleHealthZ() http.Handler {
 	})
 }
 
-func handleGetOrders(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+// handleChannelStatus reports rate limiting and retries per channel so the dashboard can show throttling
+func handleChannelStatus(l *slog.Logger, o *order.OrderService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var wg sync.WaitGroup
-		var mu sync.Mutex
-		orders := []order.Order{}
-
-		// Fetch and transform orders
-		wg.Go(func() {
-			res, err := o.OrderspaceClient.GetLast10Orders()
-			if err != nil {
-				l.Error("fetching orderspace orders failed", "error_message", err)
-			}
-			transformed := []order.Order{}
-			for _, v := range res.Orders {
-				o := o.ConvertOrderspaceOrder(v)
-				transformed = append(transformed, o)
-			}
-
-			for _, v := range transformed {
-				mu.Lock()
-				orders = append(orders, v)
-				mu.Unlock()
-			}
-		})
+		if err := encode(w, r, http.StatusOK, o.ChannelStatuses()); err != nil {
+			l.Error("encoding channel status failed", "error_message", err)
+		}
+	})
+}
 
-		wg.Go(func() {
-			res, err := o.WooClient.GetLast10Orders()
-			if err != nil {
-				l.Error("fetching woocommerce orders failed", "error_message", err)
-			}
-			transformed := []order.Order{}
-			for _, v := range res.Orders {
-				o := o.ConvertWooOrder(v)
-				transformed = append(transformed, o)
-			}
+// ordersPerPage is the number of orders shown per page on /orders
+const ordersPerPage = 50
 
-			for _, v := range transformed {
-				mu.Lock()
-				orders = append(orders, v)
-				mu.Unlock()
+func handleGetOrders(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+			page = p
+		}
+		var status order.Status
+		if v := r.URL.Query().Get("status"); v != "" {
+			var ok bool
+			if status, ok = order.ParseStatus(v); !ok {
+				http.Error(w, "invalid status", http.StatusBadRequest)
+				return
 			}
-		})
-
-		wg.Wait()
+		}
 
-		// Sort
-		sort.Slice(orders, func(i, j int) bool {
-			return orders[i].SortDate.After(orders[j].SortDate) // descending
-		})
+		// Orders are read from the local copy kept up to date by the sync worker
+		orders, err := o.ListStoredOrders(r.Context(), status, ordersPerPage, (page-1)*ordersPerPage)
+		if err != nil {
+			l.Error("listing stored orders failed", "error_message", err)
+			http.Error(w, "Failed to retrieve orders", http.StatusInternalServerError)
+			return
+		}
 
 		// Check content type header and return json or html
 		// contentType := getContentType(l, r)
@@ -110,8 +129,14 @@ func handleGetOrders(l *slog.Logger, t *TemplateRenderer, o *order.OrderService)
 
 		// case html
 		data := map[string]any{
-			"Title":  "Orders Page",
-			"Orders": orders,
+			"Title":    "Orders Page",
+			"Orders":   orders,
+			"Status":   status,
+			"Statuses": order.Statuses,
+			"Page":     page,
+			"PrevPage": page - 1,
+			"NextPage": page + 1,
+			"HasMore":  len(orders) == ordersPerPage,
 		}
 		if err := t.Render(w, "orders", data); err != nil {
 			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
@@ -120,6 +145,178 @@ func handleGetOrders(l *slog.Logger, t *TemplateRenderer, o *order.OrderService)
 	})
 }
 
+// newOrderSearchLimit is the number of customers or products offered per search on the new order page
+const newOrderSearchLimit = 20
+
+// handleNewOrder shows the form for placing an order in either channel
+func handleNewOrder(t *TemplateRenderer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.URL.Query().Get("origin")
+		if !validateOrigin(origin) {
+			origin = Orderspace
+		}
+		data := map[string]any{
+			"Title":  "New Order",
+			"Origin": origin,
+		}
+		if err := t.Render(w, "order-new", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// handleSearchOrderCustomers finds customers of one channel for the new order form
+func handleSearchOrderCustomers(l *slog.Logger, c *customer.CustomerService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, query := r.URL.Query().Get("origin"), r.URL.Query().Get("q")
+		if !validateOrigin(origin) {
+			http.Error(w, "invalid or missing origin", http.StatusBadRequest)
+			return
+		}
+		matches, err := c.Search(r.Context(), origin, query, newOrderSearchLimit)
+		if err != nil {
+			l.Error("searching customers failed", "error_message", err, "origin", origin, "query", query)
+			if err := encode(w, r, http.StatusBadGateway, map[string]any{"error": err.Error()}); err != nil {
+				l.Error("encoding customer search error failed", "error_message", err)
+			}
+			return
+		}
+		if err := encode(w, r, http.StatusOK, matches); err != nil {
+			l.Error("encoding customer search failed", "error_message", err)
+		}
+	})
+}
+
+// orderProduct is a product offered on the new order form
+type orderProduct struct {
+	ProductID string      `json:"product_id"`
+	VariantID string      `json:"variant_id,omitempty"`
+	SKU       string      `json:"sku"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"` // list price; the channel prices the order for the customer
+}
+
+// handleSearchOrderProducts finds products on sale in one channel for the new order form
+func handleSearchOrderProducts(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, query := r.URL.Query().Get("origin"), r.URL.Query().Get("q")
+		if !validateOrigin(origin) {
+			http.Error(w, "invalid or missing origin", http.StatusBadRequest)
+			return
+		}
+		listings, err := o.OrderProducts(r.Context(), origin, query, newOrderSearchLimit)
+		if err != nil {
+			l.Error("searching products failed", "error_message", err, "origin", origin, "query", query)
+			if err := encode(w, r, http.StatusBadGateway, map[string]any{"error": err.Error()}); err != nil {
+				l.Error("encoding product search error failed", "error_message", err)
+			}
+			return
+		}
+		products := make([]orderProduct, 0, len(listings))
+		for _, listing := range listings {
+			products = append(products, orderProduct{
+				ProductID: listing.ProductID,
+				VariantID: listing.VariantID,
+				SKU:       listing.SKU,
+				Name:      listing.Name,
+				Price:     listing.Price,
+			})
+		}
+		if err := encode(w, r, http.StatusOK, products); err != nil {
+			l.Error("encoding product search failed", "error_message", err)
+		}
+	})
+}
+
+// createOrderRequest is the body of POST /orders
+type createOrderRequest struct {
+	Origin       string               `json:"origin"`
+	CustomerID   string               `json:"customer_id"`
+	Lines        []order.NewOrderLine `json:"lines"`
+	DeliveryDate string               `json:"delivery_date"` // YYYY-MM-DD, Orderspace only
+	CustomerNote string               `json:"customer_note"`
+	InternalNote string               `json:"internal_note"`
+}
+
+func (req createOrderRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !validateOrigin(req.Origin) {
+		problems["origin"] = "must be orderspace or woocommerce"
+	}
+	if strings.TrimSpace(req.CustomerID) == "" {
+		problems["customer_id"] = "is required, choose a customer"
+	}
+	if len(req.Lines) == 0 {
+		problems["lines"] = "must include at least one product"
+	}
+	for i, line := range req.Lines {
+		if line.ProductID == "" {
+			problems[fmt.Sprintf("lines[%d]", i)] = "must name a product"
+		}
+		if line.Quantity < 1 {
+			problems[fmt.Sprintf("lines[%d].quantity", i)] = "must be at least 1"
+		}
+	}
+	if req.DeliveryDate != "" {
+		if req.Origin == WooCommerce {
+			problems["delivery_date"] = "can't be set on WooCommerce orders"
+		} else if _, err := time.Parse("2006-01-02", req.DeliveryDate); err != nil {
+			problems["delivery_date"] = "must be a date"
+		}
+	}
+	return problems
+}
+
+// handleCreateOrder places an order in its channel and responds with where to find it
+func handleCreateOrder(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, problems, err := decodeValid[createOrderRequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+
+		newOrder := order.NewOrder{
+			CustomerID:   req.CustomerID,
+			Lines:        req.Lines,
+			CustomerNote: req.CustomerNote,
+			InternalNote: req.InternalNote,
+		}
+		if req.DeliveryDate != "" {
+			newOrder.DeliveryDate, _ = time.Parse("2006-01-02", req.DeliveryDate)
+		}
+		created, err := o.CreateOrder(r.Context(), req.Origin, newOrder)
+		var orderErr *order.NewOrderError
+		switch {
+		case errors.As(err, &orderErr):
+			writeProblems(l, w, r, map[string]string{orderErr.Field: orderErr.Problem}, err)
+			return
+		case errors.Is(err, order.ErrInvalidOrderRef):
+			writeProblems(l, w, r, map[string]string{"origin": "must be orderspace or woocommerce"}, err)
+			return
+		case err != nil:
+			l.Error("creating order failed", "error_message", err, "origin", req.Origin, "customerID", req.CustomerID)
+			if err := encode(w, r, http.StatusBadGateway, map[string]any{"error": err.Error()}); err != nil {
+				l.Error("encoding order creation error failed", "error_message", err)
+			}
+			return
+		}
+
+		url := "/orders/" + created.Origin + "/" + created.ID
+		l.Info("order created", "orderID", created.ID, "origin", created.Origin, "order_number", created.OrderNumber)
+		w.Header().Set("Location", url)
+		if err := encode(w, r, http.StatusCreated, map[string]any{
+			"id":           created.ID,
+			"origin":       created.Origin,
+			"order_number": created.OrderNumber,
+			"url":          url,
+		}); err != nil {
+			l.Error("encoding created order failed", "error_message", err)
+		}
+	})
+}
+
 func handleGetOrder(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		orderID := r.PathValue("id")
@@ -130,30 +327,21 @@ func handleGetOrder(l *slog.Logger, t *TemplateRenderer, o *order.OrderService)
 			return
 		}
 		l.Info("Retrieve single order", "orderID", orderID, "origin", origin)
+		var detail order.Order
 		switch origin {
 		case Orderspace:
-			order, err := o.OrderspaceClient.GetOrder(orderID)
+			order, err := o.OrderspaceClient.GetOrder(r.Context(), orderID)
 			if err != nil {
-				l.Error("error retrieving order details", "error_message", err.Error(), "orderID", orderID, "origin", origin)
-				http.Error(w, "failed to retrieve order details", http.StatusInternalServerError)
+				renderOrderError(l, t, w, err, orderID, origin)
 				return
 			}
-			data := map[string]any{
-				"Title": "Orders Page",
-				"Order": order,
+			if err := o.SaveOrderspaceOrder(r.Context(), *order); err != nil {
+				l.Error("saving orderspace order failed", "error_message", err, "orderID", orderID)
 			}
-			if err := t.Render(w, "order-details-orderspace", data); err != nil {
-				http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			if detail, err = o.ConvertOrderspaceOrder(*order); err != nil {
+				renderOrderError(l, t, w, err, orderID, origin)
 				return
 			}
-			return
-			// err = encode(w, r, http.StatusOK, order)
-			// if err != nil {
-			// 	l.Error("failed to encode order details", "error_message", err.Error())
-			// 	http.Error(w, "failed to encode order details", http.StatusInternalServerError)
-			// 	return
-			// }
-			// return
 		case WooCommerce:
 			oid, err := strconv.Atoi(orderID)
 			if err != nil {
@@ -161,32 +349,895 @@ func handleGetOrder(l *slog.Logger, t *TemplateRenderer, o *order.OrderService)
 				http.Error(w, "invalid orderID", http.StatusBadRequest)
 				return
 			}
-			order, err := o.WooClient.GetOrder(oid)
+			order, err := o.WooClient.GetOrder(r.Context(), oid)
+			if err != nil {
+				renderOrderError(l, t, w, err, orderID, origin)
+				return
+			}
+			if err := o.SaveWooOrder(r.Context(), *order); err != nil {
+				l.Error("saving woocommerce order failed", "error_message", err, "orderID", orderID)
+			}
+			if detail, err = o.ConvertWooOrder(*order); err != nil {
+				renderOrderError(l, t, w, err, orderID, origin)
+				return
+			}
+		}
+
+		fulfilments, err := o.Fulfilments(r.Context(), origin, orderID)
+		if err != nil {
+			l.Error("listing fulfilments failed", "error_message", err, "orderID", orderID, "origin", origin)
+		}
+
+		var labels []order.ShippingLabel
+		if o.ShippingEnabled() {
+			if labels, err = o.ShippingLabels(r.Context(), origin, orderID); err != nil {
+				l.Error("listing shipping labels failed", "error_message", err, "orderID", orderID, "origin", origin)
+			}
+		}
+		// The newest label still in use fills in the tracking details when marking the order shipped
+		var latestLabel *order.ShippingLabel
+		for i := range labels {
+			if !labels[i].Voided() {
+				latestLabel = &labels[i]
+				break
+			}
+		}
+
+		data := map[string]any{
+			"Title":           "Orders Page",
+			"Order":           detail,
+			"Fulfilments":     fulfilments,
+			"ShippingEnabled": o.ShippingEnabled(),
+			"ShippingLabels":  labels,
+			"LatestLabel":     latestLabel,
+		}
+		if err := t.Render(w, "order-details", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// fulfilOrderRequest is the body of POST /orders/{origin}/{id}/fulfilments
+type fulfilOrderRequest struct {
+	Lines          []order.FulfilmentLine `json:"lines"` // Orderspace only, empty sends everything outstanding
+	Carrier        string                 `json:"carrier"`
+	TrackingNumber string                 `json:"tracking_number"`
+	TrackingURL    string                 `json:"tracking_url"`
+	FulfilledBy    string                 `json:"fulfilled_by"`
+}
+
+func (req fulfilOrderRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(req.FulfilledBy) == "" {
+		problems["fulfilled_by"] = "is required, say who sent the order"
+	}
+	for _, line := range req.Lines {
+		if line.Quantity < 0 {
+			problems["lines"] = "must not have negative quantities"
+		}
+	}
+	if u := strings.TrimSpace(req.TrackingURL); u != "" && !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		problems["tracking_url"] = "must be a web address"
+	}
+	return problems
+}
+
+// handleFulfilOrder marks an order shipped in its channel and records who did it
+func handleFulfilOrder(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, orderID := r.PathValue("origin"), r.PathValue("id")
+		if !validateOrigin(origin) {
+			http.Error(w, "invalid or missing origin", http.StatusBadRequest)
+			return
+		}
+		req, problems, err := decodeValid[fulfilOrderRequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+
+		record, err := o.Fulfil(r.Context(), origin, orderID, order.Fulfilment{
+			Lines:          req.Lines,
+			Carrier:        req.Carrier,
+			TrackingNumber: req.TrackingNumber,
+			TrackingURL:    req.TrackingURL,
+			FulfilledBy:    req.FulfilledBy,
+		})
+		var fulfilmentErr *order.FulfilmentError
+		var apiErr *transport.Error
+		switch {
+		case errors.As(err, &fulfilmentErr):
+			writeProblems(l, w, r, map[string]string{fulfilmentErr.Field: fulfilmentErr.Problem}, err)
+			return
+		case errors.Is(err, order.ErrInvalidOrderRef):
+			writeProblems(l, w, r, map[string]string{"order": "is not a valid order reference"}, err)
+			return
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			l.Warn("fulfilling missing order", "error_message", err, "orderID", orderID, "origin", origin)
+			http.Error(w, "order not found", http.StatusNotFound)
+			return
+		case err != nil:
+			l.Error("fulfilling order failed", "error_message", err, "orderID", orderID, "origin", origin)
+			if err := encode(w, r, http.StatusBadGateway, map[string]any{"error": err.Error()}); err != nil {
+				l.Error("encoding fulfilment error failed", "error_message", err)
+			}
+			return
+		}
+
+		l.Info("order fulfilled", "orderID", orderID, "origin", origin, "fulfilled_by", record.FulfilledBy, "dispatch_id", record.DispatchID)
+		if err := encode(w, r, http.StatusCreated, record); err != nil {
+			l.Error("encoding fulfilment failed", "error_message", err)
+		}
+	})
+}
+
+// shippingQuoteRequest is the body of POST /orders/{origin}/{id}/shipping-quotes
+type shippingQuoteRequest struct {
+	WeightGrams int `json:"weight_grams"`
+	LengthCM    int `json:"length_cm"`
+	WidthCM     int `json:"width_cm"`
+	HeightCM    int `json:"height_cm"`
+}
+
+func (req shippingQuoteRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if req.WeightGrams <= 0 {
+		problems["weight_grams"] = "must be more than zero"
+	}
+	if req.LengthCM < 0 || req.WidthCM < 0 || req.HeightCM < 0 {
+		problems["dimensions"] = "must not be negative"
+	}
+	return problems
+}
+
+// handleQuoteShipping returns the carrier's rates for sending a parcel to an order's address
+func handleQuoteShipping(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, orderID := r.PathValue("origin"), r.PathValue("id")
+		if !validateOrigin(origin) {
+			http.Error(w, "invalid or missing origin", http.StatusBadRequest)
+			return
+		}
+		req, problems, err := decodeValid[shippingQuoteRequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+
+		quote, err := o.QuoteShipping(r.Context(), origin, orderID, shipping.Parcel{
+			WeightGrams: req.WeightGrams,
+			LengthCM:    req.LengthCM,
+			WidthCM:     req.WidthCM,
+			HeightCM:    req.HeightCM,
+		})
+		if err != nil {
+			writeShippingError(l, w, r, err, "quoting shipping failed")
+			return
+		}
+		if err := encode(w, r, http.StatusOK, quote); err != nil {
+			l.Error("encoding shipping quote failed", "error_message", err)
+		}
+	})
+}
+
+// buyShippingLabelRequest is the body of POST /orders/{origin}/{id}/shipping-labels
+type buyShippingLabelRequest struct {
+	QuoteID  string `json:"quote_id"`
+	RateID   string `json:"rate_id"`
+	BoughtBy string `json:"bought_by"`
+}
+
+func (req buyShippingLabelRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if req.QuoteID == "" {
+		problems["quote_id"] = "is required, get a quote first"
+	}
+	if req.RateID == "" {
+		problems["rate_id"] = "is required, choose a service"
+	}
+	if strings.TrimSpace(req.BoughtBy) == "" {
+		problems["bought_by"] = "is required, say who bought the label"
+	}
+	return problems
+}
+
+// handleBuyShippingLabel buys a quoted rate and stores the label against the order
+func handleBuyShippingLabel(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, orderID := r.PathValue("origin"), r.PathValue("id")
+		if !validateOrigin(origin) {
+			http.Error(w, "invalid or missing origin", http.StatusBadRequest)
+			return
+		}
+		req, problems, err := decodeValid[buyShippingLabelRequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+
+		label, err := o.BuyShippingLabel(r.Context(), origin, orderID, req.QuoteID, req.RateID, req.BoughtBy)
+		if err != nil {
+			writeShippingError(l, w, r, err, "buying shipping label failed")
+			return
+		}
+		l.Info("shipping label bought", "orderID", orderID, "origin", origin, "bought_by", label.BoughtBy, "tracking_number", label.TrackingNumber, "price", label.Price.String())
+		if err := encode(w, r, http.StatusCreated, label); err != nil {
+			l.Error("encoding shipping label failed", "error_message", err)
+		}
+	})
+}
+
+// handleVoidShippingLabel cancels one of an order's labels so it is refunded
+func handleVoidShippingLabel(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, orderID := r.PathValue("origin"), r.PathValue("id")
+		labelID, err := strconv.ParseInt(r.PathValue("label"), 10, 64)
+		if !validateOrigin(origin) || err != nil {
+			http.Error(w, "invalid order or label", http.StatusBadRequest)
+			return
+		}
+
+		label, err := o.VoidShippingLabel(r.Context(), origin, orderID, labelID)
+		if err != nil {
+			writeShippingError(l, w, r, err, "voiding shipping label failed")
+			return
+		}
+		l.Info("shipping label voided", "orderID", orderID, "origin", origin, "tracking_number", label.TrackingNumber)
+		if err := encode(w, r, http.StatusOK, label); err != nil {
+			l.Error("encoding shipping label failed", "error_message", err)
+		}
+	})
+}
+
+// handleTrackShippingLabel reports where the parcel for one of an order's labels has got to
+func handleTrackShippingLabel(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin, orderID := r.PathValue("origin"), r.PathValue("id")
+		labelID, err := strconv.ParseInt(r.PathValue("label"), 10, 64)
+		if !validateOrigin(origin) || err != nil {
+			http.Error(w, "invalid order or label", http.StatusBadRequest)
+			return
+		}
+
+		tracking, err := o.TrackShippingLabel(r.Context(), origin, orderID, labelID)
+		if err != nil {
+			writeShippingError(l, w, r, err, "tracking shipping label failed")
+			return
+		}
+		if err := encode(w, r, http.StatusOK, tracking); err != nil {
+			l.Error("encoding tracking failed", "error_message", err)
+		}
+	})
+}
+
+// writeShippingError responds to a failed shipping label request: problems
+// the user can fix are 422s, and carrier or channel failures are 502s
+func writeShippingError(l *slog.Logger, w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var shipmentErr *shipping.ShipmentError
+	var apiErr *transport.Error
+	switch {
+	case errors.As(err, &shipmentErr):
+		writeProblems(l, w, r, map[string]string{shipmentErr.Field: shipmentErr.Problem}, err)
+	case errors.Is(err, order.ErrInvalidOrderRef):
+		writeProblems(l, w, r, map[string]string{"order": "is not a valid order reference"}, err)
+	case errors.Is(err, shipping.ErrQuoteNotFound), errors.Is(err, shipping.ErrRateNotFound):
+		writeProblems(l, w, r, map[string]string{"quote_id": "has expired or was already bought, get a new quote"}, err)
+	case errors.Is(err, shipping.ErrLabelVoided):
+		writeProblems(l, w, r, map[string]string{"label": "is already voided"}, err)
+	case errors.Is(err, shipping.ErrLabelNotFound):
+		http.Error(w, "shipping label not found", http.StatusNotFound)
+	case errors.Is(err, order.ErrShippingDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && validateOrigin(apiErr.Channel):
+		l.Warn(msg, "error_message", err, "path", r.URL.Path)
+		http.Error(w, "order not found", http.StatusNotFound)
+	default:
+		l.Error(msg, "error_message", err, "path", r.URL.Path)
+		if err := encode(w, r, http.StatusBadGateway, map[string]any{"error": err.Error()}); err != nil {
+			l.Error("encoding shipping error failed", "error_message", err)
+		}
+	}
+}
+
+// renderNotFound writes a 404 using the not-found page
+func renderNotFound(l *slog.Logger, t *TemplateRenderer, w http.ResponseWriter, title, message string) {
+	w.WriteHeader(http.StatusNotFound)
+	data := map[string]any{
+		"Title":   title,
+		"Message": message,
+	}
+	if err := t.Render(w, "not-found", data); err != nil {
+		l.Error("rendering not-found page failed", "error_message", err)
+	}
+}
+
+// renderOrderError shows the not-found page when the channel has no such order,
+// and a 500 for any other failure
+func renderOrderError(l *slog.Logger, t *TemplateRenderer, w http.ResponseWriter, err error, orderID, origin string) {
+	var apiErr *transport.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		l.Warn("order not found", "error_message", err, "orderID", orderID, "origin", origin)
+		renderNotFound(l, t, w, "Order Not Found", "We couldn't find order "+orderID+" in "+origin+".")
+		return
+	}
+
+	attrs := []any{"error_message", err, "orderID", orderID, "origin", origin}
+	if apiErr != nil {
+		attrs = append(attrs, "status", apiErr.StatusCode, "request_id", apiErr.RequestID, "retryable", apiErr.Retryable)
+	}
+	l.Error("error retrieving order details", attrs...)
+	http.Error(w, "failed to retrieve order details", http.StatusInternalServerError)
+}
+
+// customersPerPage is the number of customers shown per page on /customers
+const customersPerPage = 25
+
+func handleGetCustomers(l *slog.Logger, t *TemplateRenderer, c *customer.CustomerService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		after := r.URL.Query().Get("after")
+
+		page, err := c.ListOrderspaceCustomers(r.Context(), query, after, customersPerPage)
+		if err != nil {
+			l.Error("listing customers failed", "error_message", err, "query", query)
+			http.Error(w, "Failed to retrieve customers", http.StatusInternalServerError)
+			return
+		}
+
+		data := map[string]any{
+			"Title":     "Customers Page",
+			"Customers": page.Customers,
+			"Query":     query,
+			"Next":      page.Next,
+			"Paged":     after != "",
+		}
+		if err := t.Render(w, "customers", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// customerOrdersPerPage is the number of orders listed on a customer page
+const customerOrdersPerPage = 20
+
+func handleGetOrderspaceCustomer(l *slog.Logger, t *TemplateRenderer, o *order.OrderService, c *customer.CustomerService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		customerID := r.PathValue("id")
+		ordersAfter := r.URL.Query().Get("orders_after")
+
+		cust, res, err := c.GetOrderspaceCustomer(r.Context(), customerID, ordersAfter, customerOrdersPerPage)
+		if err != nil {
+			var apiErr *transport.Error
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				renderNotFound(l, t, w, "Customer Not Found", "We couldn't find customer "+customerID+" in orderspace.")
+				return
+			}
+			l.Error("retrieving customer failed", "error_message", err, "customerID", customerID)
+			http.Error(w, "Failed to retrieve customer", http.StatusInternalServerError)
+			return
+		}
+
+		orders := make([]order.Order, 0, len(res.Orders))
+		for _, ord := range res.Orders {
+			converted, err := o.ConvertOrderspaceOrder(ord)
 			if err != nil {
-				l.Error("error retrieving order details", "error_message", err.Error(), "orderID", orderID, "origin", origin)
-				http.Error(w, "failed to retrieve order details", http.StatusInternalServerError)
+				l.Error("converting customer order failed", "error_message", err, "customerID", customerID, "orderID", ord.ID)
+				http.Error(w, "Failed to retrieve customer", http.StatusInternalServerError)
+				return
+			}
+			orders = append(orders, converted)
+		}
+		next := ""
+		if res.Pagination.HasMore && len(res.Orders) > 0 {
+			next = res.Orders[len(res.Orders)-1].ID
+		}
+
+		data := map[string]any{
+			"Title":      cust.CompanyName,
+			"Customer":   cust,
+			"Orders":     orders,
+			"NextOrders": next,
+		}
+		if err := t.Render(w, "customer-details-orderspace", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+func handleGetUnifiedCustomers(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		page := 1
+		if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+			page = p
+		}
+
+		// Customers are matched from the local copy kept up to date by the sync worker
+		customers, err := o.ListUnifiedCustomers(r.Context(), query)
+		if err != nil {
+			l.Error("listing unified customers failed", "error_message", err, "query", query)
+			http.Error(w, "Failed to retrieve customers", http.StatusInternalServerError)
+			return
+		}
+
+		start := min((page-1)*customersPerPage, len(customers))
+		end := min(start+customersPerPage, len(customers))
+
+		data := map[string]any{
+			"Title":     "Customers Page",
+			"Customers": customers[start:end],
+			"Query":     query,
+			"Page":      page,
+			"PrevPage":  page - 1,
+			"NextPage":  page + 1,
+			"HasMore":   end < len(customers),
+		}
+		if err := t.Render(w, "customers-all", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+func handleGetUnifiedCustomer(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		customerID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			renderNotFound(l, t, w, "Customer Not Found", "We couldn't find customer "+r.PathValue("id")+".")
+			return
+		}
+
+		cust, orders, err := o.GetUnifiedCustomer(r.Context(), customerID)
+		if errors.Is(err, order.ErrCustomerNotFound) {
+			renderNotFound(l, t, w, "Customer Not Found", "We couldn't find customer "+r.PathValue("id")+".")
+			return
+		}
+		if err != nil {
+			l.Error("retrieving unified customer failed", "error_message", err, "customerID", customerID)
+			http.Error(w, "Failed to retrieve customer", http.StatusInternalServerError)
+			return
+		}
+
+		// Registered WooCommerce shoppers have an account worth showing; guests are keyed
+		// on their email and have none. The page still renders if WooCommerce is unreachable.
+		var accounts []woocommerce.Customer
+		for _, record := range cust.Records {
+			wooID, err := strconv.Atoi(record.ExternalID)
+			if record.Origin != WooCommerce || err != nil {
+				continue
+			}
+			account, err := o.WooClient.GetCustomer(r.Context(), wooID)
+			if err != nil {
+				l.Warn("retrieving woocommerce customer failed", "error_message", err, "wooCustomerID", wooID)
+				continue
+			}
+			accounts = append(accounts, *account)
+		}
+
+		data := map[string]any{
+			"Title":       cust.Name,
+			"Customer":    cust,
+			"Orders":      orders,
+			"WooAccounts": accounts,
+		}
+		if err := t.Render(w, "customer-details", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+func handleGetProducts(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		mismatchesOnly := r.URL.Query().Get("mismatches") == "1"
+
+		catalogue, err := o.ProductCatalogue(r.Context())
+		if err != nil {
+			l.Error("loading product catalogue failed", "error_message", err)
+			http.Error(w, "Failed to retrieve products", http.StatusInternalServerError)
+			return
+		}
+
+		mismatches := 0
+		products := make([]order.UnifiedProduct, 0, len(catalogue.Products))
+		for _, p := range catalogue.Products {
+			if p.Mismatched() {
+				mismatches++
+			}
+			if (mismatchesOnly && !p.Mismatched()) || (query != "" && !p.Matches(query)) {
+				continue
+			}
+			products = append(products, p)
+		}
+
+		data := map[string]any{
+			"Title":          "Products Page",
+			"Products":       products,
+			"NoSKU":          catalogue.NoSKU,
+			"Total":          len(catalogue.Products),
+			"Mismatches":     mismatches,
+			"MismatchesOnly": mismatchesOnly,
+			"Query":          query,
+			"Orderspace":     Orderspace,
+			"WooCommerce":    WooCommerce,
+		}
+		if err := t.Render(w, "products", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+func handleGetInventory(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		levels, err := o.InventoryLevels(r.Context())
+		if err != nil {
+			l.Error("listing inventory levels failed", "error_message", err)
+			http.Error(w, "Failed to retrieve inventory", http.StatusInternalServerError)
+			return
+		}
+
+		pending := 0
+		for _, level := range levels {
+			if level.Pending() {
+				pending++
+			}
+		}
+
+		data := map[string]any{
+			"Title":   "Inventory Page",
+			"Levels":  levels,
+			"Pending": pending,
+		}
+		if err := t.Render(w, "inventory", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// trackSKURequest is the body of POST /inventory
+type trackSKURequest struct {
+	SKU  string `json:"sku"`
+	Name string `json:"name"`
+}
+
+func (req trackSKURequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(req.SKU) == "" {
+		problems["sku"] = "is required"
+	}
+	return problems
+}
+
+// adjustStockRequest is the body of POST /inventory/{sku}/adjustments
+type adjustStockRequest struct {
+	Delta int    `json:"delta"`
+	Note  string `json:"note"`
+}
+
+func (req adjustStockRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if req.Delta == 0 {
+		problems["delta"] = "must not be zero"
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		problems["note"] = "is required, say why stock changed"
+	}
+	return problems
+}
+
+// stockTakeRequest is the body of POST /inventory/{sku}/stocktakes
+type stockTakeRequest struct {
+	Counted *int   `json:"counted"`
+	Note    string `json:"note"`
+}
+
+func (req stockTakeRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if req.Counted == nil {
+		problems["counted"] = "is required"
+	} else if *req.Counted < 0 {
+		problems["counted"] = "must not be negative"
+	}
+	return problems
+}
+
+func handleTrackSKU(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, problems, err := decodeValid[trackSKURequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+		level, err := o.TrackSKU(r.Context(), req.SKU, req.Name)
+		writeStockLevel(l, w, r, level, err)
+	})
+}
+
+func handleAdjustStock(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, problems, err := decodeValid[adjustStockRequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+		level, err := o.AdjustStock(r.Context(), r.PathValue("sku"), req.Delta, req.Note)
+		writeStockLevel(l, w, r, level, err)
+	})
+}
+
+func handleStockTake(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, problems, err := decodeValid[stockTakeRequest](r)
+		if err != nil {
+			writeProblems(l, w, r, problems, err)
+			return
+		}
+		level, err := o.StockTake(r.Context(), r.PathValue("sku"), *req.Counted, req.Note)
+		writeStockLevel(l, w, r, level, err)
+	})
+}
+
+func handlePushStock(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed, err := o.PushPendingStock(r.Context())
+		status, body := http.StatusOK, map[string]any{"pushed": pushed}
+		if err != nil {
+			l.Error("pushing stock failed", "error_message", err)
+			status, body["error"] = http.StatusBadGateway, err.Error()
+		}
+		if err := encode(w, r, status, body); err != nil {
+			l.Error("encoding stock push failed", "error_message", err)
+		}
+	})
+}
+
+// writeProblems responds to a JSON request body that failed to decode or validate
+func writeProblems(l *slog.Logger, w http.ResponseWriter, r *http.Request, problems map[string]string, err error) {
+	status := http.StatusUnprocessableEntity
+	if problems == nil {
+		status, problems = http.StatusBadRequest, map[string]string{"body": "must be valid JSON"}
+	}
+	l.Warn("rejected request body", "path", r.URL.Path, "error_message", err)
+	if err := encode(w, r, status, map[string]any{"problems": problems}); err != nil {
+		l.Error("encoding problems failed", "error_message", err)
+	}
+}
+
+// writeStockLevel responds with the stock level after an inventory change
+func writeStockLevel(l *slog.Logger, w http.ResponseWriter, r *http.Request, level *order.StockLevel, err error) {
+	if errors.Is(err, order.ErrSKUNotTracked) {
+		writeProblems(l, w, r, map[string]string{"sku": "is not tracked"}, err)
+		return
+	}
+	if err != nil {
+		l.Error("updating inventory failed", "error_message", err)
+		http.Error(w, "Failed to update inventory", http.StatusInternalServerError)
+		return
+	}
+	if err := encode(w, r, http.StatusOK, level); err != nil {
+		l.Error("encoding stock level failed", "error_message", err)
+	}
+}
+
+// Roast plan horizons, in days
+const (
+	defaultPlanDays = 7
+	maxPlanDays     = 60
+)
+
+// handleGetRoastPlan shows what to roast for the next ?days= days, as HTML or,
+// with ?format=json or ?format=csv, as data for other tools
+func handleGetRoastPlan(l *slog.Logger, t *TemplateRenderer, p *planning.Planner) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		days := defaultPlanDays
+		if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 {
+			days = min(d, maxPlanDays)
+		}
+
+		plan, err := p.Plan(r.Context(), time.Now(), days)
+		if err != nil {
+			l.Error("building roast plan failed", "error_message", err)
+			http.Error(w, "Failed to build roast plan", http.StatusInternalServerError)
+			return
+		}
+
+		switch r.URL.Query().Get("format") {
+		case "json":
+			if err := encode(w, r, http.StatusOK, plan); err != nil {
+				l.Error("encoding roast plan failed", "error_message", err)
+			}
+			return
+		case "csv":
+			w.Header().Set(HeaderContentType, "text/csv; charset=utf-8")
+			w.Header().Set(HeaderContentDisposition, fmt.Sprintf(`attachment; filename="roast-plan-%s.csv"`, plan.From.Format("2006-01-02")))
+			if err := plan.WriteCSV(w); err != nil {
+				l.Error("writing roast plan csv failed", "error_message", err)
+			}
+			return
+		}
+
+		data := map[string]any{
+			"Title":   "Roast Plan",
+			"Plan":    plan,
+			"Days":    days,
+			"Choices": []int{3, 7, 14, 28},
+		}
+		if err := t.Render(w, "planning", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// handleGetDeliveries shows orders by delivery date in a ?view=week or
+// ?view=month calendar around ?date=YYYY-MM-DD, or with ?format=json the same
+// calendar as data
+func handleGetDeliveries(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		view := r.URL.Query().Get("view")
+		date := o.Now()
+		if d, err := time.Parse("2006-01-02", r.URL.Query().Get("date")); err == nil {
+			date = d
+		}
+
+		calendar, err := o.DeliveryCalendar(r.Context(), view, date)
+		if err != nil {
+			l.Error("loading delivery calendar failed", "error_message", err)
+			http.Error(w, "Failed to retrieve deliveries", http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			if err := encode(w, r, http.StatusOK, calendar); err != nil {
+				l.Error("encoding delivery calendar failed", "error_message", err)
+			}
+			return
+		}
+
+		data := map[string]any{
+			"Title":    "Deliveries",
+			"Calendar": calendar,
+			"Today":    o.Now().Format("2006-01-02"),
+			"Month":    order.CalendarMonth,
+			"Week":     order.CalendarWeek,
+		}
+		if err := t.Render(w, "deliveries", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// The delivery feed covers recent and upcoming deliveries so calendar apps keep a little history
+const (
+	icsDaysBack  = 30
+	icsDaysAhead = 180
+)
+
+// handleGetDeliveriesICS serves delivery dates as an iCalendar feed that calendar apps can subscribe to
+func handleGetDeliveriesICS(l *slog.Logger, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := o.Now()
+		days, err := o.DeliverySchedule(r.Context(), now.AddDate(0, 0, -icsDaysBack), now.AddDate(0, 0, icsDaysAhead))
+		if err != nil {
+			l.Error("loading delivery schedule failed", "error_message", err)
+			http.Error(w, "Failed to retrieve deliveries", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, "text/calendar; charset=utf-8")
+		w.Header().Set(HeaderContentDisposition, `inline; filename="deliveries.ics"`)
+		if err := order.WriteICS(w, "Deliveries", days, now); err != nil {
+			l.Error("writing delivery feed failed", "error_message", err)
+		}
+	})
+}
+
+// handleGetPackingSlip renders an order's packing slip as a printable page, or with ?format=pdf as a PDF
+func handleGetPackingSlip(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orderID := r.PathValue("id")
+		origin := r.PathValue("origin")
+		slip, err := o.PackingSlip(r.Context(), origin, orderID)
+		if errors.Is(err, order.ErrInvalidOrderRef) {
+			l.Warn("invalid packing slip order", "error_message", err)
+			http.Error(w, "invalid origin or orderID", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			renderOrderError(l, t, w, err, orderID, origin)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "pdf" {
+			w.Header().Set(HeaderContentType, "application/pdf")
+			w.Header().Set(HeaderContentDisposition, fmt.Sprintf(`inline; filename="packing-slip-%d.pdf"`, slip.Order.OrderNumber))
+			if err := order.WritePackingSlipsPDF(w, []order.PackingSlip{*slip}); err != nil {
+				l.Error("writing packing slip pdf failed", "error_message", err, "orderID", orderID)
+			}
+			return
+		}
+
+		data := map[string]any{
+			"Title": fmt.Sprintf("Packing Slip #%d", slip.Order.OrderNumber),
+			"Slips": []order.PackingSlip{*slip},
+			"PDF":   r.URL.Path + "?format=pdf",
+		}
+		if err := t.Render(w, "packing-slip", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// maxPickListOrders caps how many orders one pick list covers
+const maxPickListOrders = 50
+
+// handleGetPickList totals the products to pick for the orders selected with
+// repeated ?order=origin:id parameters, followed by each order's packing slip.
+// The slips come from the synced orders rather than the channels.
+// ?format=pdf returns the pick list alone as a PDF.
+func handleGetPickList(l *slog.Logger, t *TemplateRenderer, o *order.OrderService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refs := r.URL.Query()["order"]
+		if len(refs) == 0 {
+			http.Error(w, "select at least one order", http.StatusBadRequest)
+			return
+		}
+		if len(refs) > maxPickListOrders {
+			http.Error(w, fmt.Sprintf("a pick list can cover at most %d orders", maxPickListOrders), http.StatusBadRequest)
+			return
+		}
+
+		slips := make([]order.PackingSlip, 0, len(refs))
+		for _, ref := range refs {
+			origin, orderID, _ := strings.Cut(ref, ":")
+			slip, err := o.StoredPackingSlip(r.Context(), origin, orderID)
+			if errors.Is(err, order.ErrInvalidOrderRef) {
+				l.Warn("invalid pick list order", "error_message", err, "order", ref)
+				http.Error(w, "invalid order "+ref, http.StatusBadRequest)
 				return
 			}
-			data := map[string]any{
-				"Title": "Orders Page",
-				"Order": order,
+			if errors.Is(err, order.ErrOrderNotStored) {
+				l.Warn("pick list order not synced", "error_message", err, "order", ref)
+				renderNotFound(l, t, w, "Order Not Found", "Order "+orderID+" from "+origin+" hasn't been synced yet.")
+				return
 			}
-			if err := t.Render(w, "order-details-woocommerce", data); err != nil {
-				http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				renderOrderError(l, t, w, err, orderID, origin)
 				return
 			}
+			slips = append(slips, *slip)
+		}
+		list := order.BuildPickList(slips)
+
+		if r.URL.Query().Get("format") == "pdf" {
+			w.Header().Set(HeaderContentType, "application/pdf")
+			w.Header().Set(HeaderContentDisposition, `inline; filename="pick-list.pdf"`)
+			if err := order.WritePickListPDF(w, list); err != nil {
+				l.Error("writing pick list pdf failed", "error_message", err)
+			}
 			return
-			// err = encode(w, r, http.StatusOK, order)
-			// if err != nil {
-			// 	l.Error("failed to encode order details", "error_message", err.Error())
-			// 	http.Error(w, "failed to encode order details", http.StatusInternalServerError)
-			// 	return
-			// }
-			// return
-		default:
-			json.NewEncoder(w).Encode(map[string]string{"status": "healthy", "orderID": orderID, "origin": origin})
 		}
 
+		query := r.URL.Query()
+		query.Set("format", "pdf")
+		data := map[string]any{
+			"Title":    "Pick List",
+			"PickList": list,
+			"Slips":    list.Slips,
+			"PDF":      r.URL.Path + "?" + query.Encode(),
+		}
+		if err := t.Render(w, "pick-list", data); err != nil {
+			http.Error(w, "Error rendering template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 	})
 }
 
@@ -5,10 +5,12 @@ import (
 	"net/http"
 
 	"github.com/dukerupert/paddy-cap/middleware"
+	"github.com/dukerupert/paddy-cap/service/customer"
 	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/planning"
 )
 
-func New(logger *slog.Logger, cfg ServerConfig, orderService *order.OrderService) http.Handler {
+func New(logger *slog.Logger, cfg ServerConfig, orderService *order.OrderService, customerService *customer.CustomerService, planner *planning.Planner) http.Handler {
 	// Initialize the template renderer
 	template, err := NewTemplateRenderer()
 	if err != nil {
@@ -16,7 +18,7 @@ func New(logger *slog.Logger, cfg ServerConfig, orderService *order.OrderService
 	}
 
 	mux := http.NewServeMux()
-	addRoutes(logger, mux, template, orderService)
+	addRoutes(logger, mux, template, orderService, customerService, planner)
 	var handler http.Handler = mux
 	// Middleware here
 	handler = middleware.Logging(handler)
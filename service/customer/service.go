@@ -0,0 +1,191 @@
+// Package customer looks up customers across the sales channels
+package customer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+)
+
+type CustomerService struct {
+	OrderspaceClient *orderspace.Client
+	WooClient        *woocommerce.Client
+	Queries          *db.Queries
+}
+
+func New(logger *slog.Logger, orderspaceClient *orderspace.Client, wooClient *woocommerce.Client, queries *db.Queries) *CustomerService {
+	service := &CustomerService{
+		OrderspaceClient: orderspaceClient,
+		WooClient:        wooClient,
+		Queries:          queries,
+	}
+
+	logger.Info("Customer service initialized")
+	return service
+}
+
+// OrderspaceCustomerPage is one page of Orderspace customers
+type OrderspaceCustomerPage struct {
+	Customers []orderspace.Customer
+	Next      string // cursor for the following page, empty on the last page
+}
+
+// ListOrderspaceCustomers returns up to limit customers after the startingAfter cursor.
+// A non-empty query keeps only customers whose name, reference, contact details,
+// buyers or addresses contain it, which means walking the customer list since the
+// Orderspace API has no search parameter.
+func (s *CustomerService) ListOrderspaceCustomers(ctx context.Context, query, startingAfter string, limit int) (*OrderspaceCustomerPage, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	if query == "" {
+		res, err := s.OrderspaceClient.ListCustomers(ctx, &orderspace.CustomerListOptions{
+			Limit:         limit,
+			StartingAfter: startingAfter,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list orderspace customers: %w", err)
+		}
+		page := &OrderspaceCustomerPage{Customers: res.Customers}
+		if res.Pagination.HasMore && len(res.Customers) > 0 {
+			page.Next = res.Customers[len(res.Customers)-1].ID
+		}
+		return page, nil
+	}
+
+	// Collect one extra match to find out whether there is another page
+	page := &OrderspaceCustomerPage{}
+	options := &orderspace.CustomerListOptions{StartingAfter: startingAfter}
+	for c, err := range s.OrderspaceClient.AllCustomers(ctx, options) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to search orderspace customers: %w", err)
+		}
+		if !matchesOrderspaceCustomer(c, query) {
+			continue
+		}
+		if len(page.Customers) == limit {
+			page.Next = page.Customers[limit-1].ID
+			break
+		}
+		page.Customers = append(page.Customers, c)
+	}
+	return page, nil
+}
+
+// GetOrderspaceCustomer returns a customer together with a page of their orders, newest first
+func (s *CustomerService) GetOrderspaceCustomer(ctx context.Context, customerID, ordersAfter string, ordersLimit int) (*orderspace.Customer, *orderspace.OrdersResponse, error) {
+	customer, err := s.OrderspaceClient.GetCustomer(ctx, customerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get orderspace customer: %w", err)
+	}
+
+	orders, err := s.OrderspaceClient.GetOrdersByCustomer(ctx, customerID, ordersLimit, ordersAfter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list orders for orderspace customer: %w", err)
+	}
+
+	return customer, orders, nil
+}
+
+// Match is a customer found by Search, summarised the same way for either channel
+type Match struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Detail string `json:"detail"` // contact details, to tell similar names apart
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, so a search matches them literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search finds up to limit customers of one sales channel matching query.
+// Orderspace has no customer search, so its customers are searched among those
+// stored by the order sync rather than by walking the whole customer list.
+func (s *CustomerService) Search(ctx context.Context, origin, query string, limit int) ([]Match, error) {
+	matches := []Match{}
+	switch origin {
+	case orderspace.Channel:
+		rows, err := s.Queries.SearchCustomers(ctx, db.SearchCustomersParams{
+			Origin:     origin,
+			Pattern:    likeEscaper.Replace(strings.TrimSpace(query)),
+			MaxResults: int32(limit),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search stored orderspace customers: %w", err)
+		}
+		for _, c := range rows {
+			matches = append(matches, Match{
+				ID:     c.ExternalID,
+				Name:   cmp.Or(c.CompanyName, c.ContactName, c.Email),
+				Detail: joinDetail(c.Email, c.Phone),
+			})
+		}
+	case woocommerce.Channel:
+		res, err := s.WooClient.ListCustomers(ctx, &woocommerce.CustomerListOptions{
+			PerPage: limit,
+			Search:  strings.TrimSpace(query),
+			Role:    "all",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search woocommerce customers: %w", err)
+		}
+		for _, c := range res.Customers {
+			name := strings.TrimSpace(c.FirstName + " " + c.LastName)
+			if c.Billing.Company != "" {
+				name = joinDetail(c.Billing.Company, name)
+			}
+			if name == "" {
+				name = c.Username
+			}
+			matches = append(matches, Match{
+				ID:     strconv.Itoa(c.ID),
+				Name:   name,
+				Detail: joinDetail(c.Email, c.Billing.City),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown sales channel %q", origin)
+	}
+	return matches, nil
+}
+
+// joinDetail joins the non-empty parts with commas
+func joinDetail(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, ", ")
+}
+
+// matchesOrderspaceCustomer reports whether any searchable field contains query, which must be lower case
+func matchesOrderspaceCustomer(c orderspace.Customer, query string) bool {
+	fields := []string{
+		c.CompanyName,
+		c.Reference,
+		c.Phone,
+		c.EmailAddresses.Orders,
+		c.EmailAddresses.Dispatches,
+		c.EmailAddresses.Invoices,
+	}
+	for _, b := range c.Buyers {
+		fields = append(fields, b.Name, b.EmailAddress)
+	}
+	for _, a := range c.Addresses {
+		fields = append(fields, a.ContactName, a.City, a.PostalCode)
+	}
+
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), query) {
+			return true
+		}
+	}
+	return false
+}
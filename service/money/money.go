@@ -0,0 +1,341 @@
+// Package money represents amounts of money exactly, as a whole number of a
+// currency's minor units such as pence or cents, and formats them for display.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// DefaultLanguage is the locale String formats amounts for
+var DefaultLanguage = language.BritishEnglish
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrOverflow is returned when a result doesn't fit in 64 bits of minor units
+	ErrOverflow = errors.New("money overflow")
+)
+
+// Money is an amount in a currency. The zero value is zero in no currency,
+// which can be added to an amount in any currency.
+type Money struct {
+	Amount   int64  // in minor units, e.g. pence
+	Currency string // ISO 4217 code, e.g. "GBP"
+}
+
+// New returns minor units of currency
+func New(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: strings.ToUpper(strings.TrimSpace(currency))}
+}
+
+// Zero returns no money in currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// FromFloat converts an amount in major units, as Orderspace sends them, rounding
+// half away from zero to the currency's minor units
+func FromFloat(amount float64, currency string) Money {
+	m := Zero(currency)
+	m.Amount = int64(math.Round(amount * math.Pow10(m.Exponent())))
+	return m
+}
+
+// Parse reads a decimal amount in major units, such as WooCommerce's "12.50".
+// Digits beyond the currency's minor units are rounded half away from zero.
+func Parse(s, currency string) (Money, error) {
+	m := Zero(currency)
+	text := strings.TrimSpace(s)
+	negative := false
+	if rest, ok := strings.CutPrefix(text, "-"); ok {
+		negative, text = true, rest
+	} else {
+		text = strings.TrimPrefix(text, "+")
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	exp := m.Exponent()
+	roundUp := false
+	if len(fraction) > exp {
+		roundUp = fraction[exp] >= '5'
+		fraction = fraction[:exp]
+	}
+	digits := strings.TrimLeft(whole+fraction+strings.Repeat("0", exp-len(fraction)), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", s, ErrOverflow)
+	}
+	if roundUp {
+		if amount == math.MaxInt64 {
+			return Money{}, fmt.Errorf("invalid amount %q: %w", s, ErrOverflow)
+		}
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	m.Amount = amount
+	return m, nil
+}
+
+// Exponent returns how many decimal places the currency's minor units take,
+// 2 for pounds and pence or 0 for yen. Unknown currencies are taken to have 2.
+func (m Money) Exponent() int {
+	unit, err := currency.ParseISO(m.Currency)
+	if err != nil {
+		return 2
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	m.Amount = -m.Amount
+	return m
+}
+
+// Add returns m plus o. Both must be in the same currency unless one is the zero value.
+func (m Money) Add(o Money) (Money, error) {
+	m, o, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrOverflow
+	}
+	m.Amount = sum
+	return m, nil
+}
+
+// Sub returns m minus o. Both must be in the same currency unless one is the zero value.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(o.Neg())
+}
+
+// Mul returns m times n, such as a unit price times a quantity
+func (m Money) Mul(n int) (Money, error) {
+	if n != 0 && (m.Amount*int64(n))/int64(n) != m.Amount {
+		return Money{}, ErrOverflow
+	}
+	m.Amount *= int64(n)
+	return m, nil
+}
+
+// Div returns m divided by n, rounded half away from zero to a minor unit,
+// such as a line total split into a unit price. It panics if n is zero.
+func (m Money) Div(n int) Money {
+	d := int64(n)
+	q, r := m.Amount/d, m.Amount%d
+	if 2*abs(r) >= abs(d) {
+		if (m.Amount < 0) != (d < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	m.Amount = q
+	return m
+}
+
+// Decimal returns the amount in major units with the currency's decimal places, e.g. "-12.50"
+func (m Money) Decimal() string {
+	whole, fraction := m.split()
+	s := strconv.FormatUint(whole, 10)
+	if fraction != "" {
+		s += "." + fraction
+	}
+	if m.Amount < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// String formats the amount for DefaultLanguage, e.g. "£1,234.50"
+func (m Money) String() string {
+	return m.Format(DefaultLanguage)
+}
+
+// Format formats the amount with the currency symbol, digit grouping and
+// decimal separator of the language, e.g. "£1,234.50" in British English or
+// "1.234,50 €" in German, with a no-break space between symbol and amount. Unknown currencies show their code after the amount.
+func (m Money) Format(tag language.Tag) string {
+	p := message.NewPrinter(tag)
+	whole, fraction := m.split()
+	amount := p.Sprint(number.Decimal(whole))
+	if fraction != "" {
+		// The locale's decimal separator is whatever sits between the digits of 1.5
+		separator := strings.Trim(p.Sprint(number.Decimal(1.5, number.Scale(1))), "15")
+		amount += separator + fraction
+	}
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+
+	unit, err := currency.ParseISO(m.Currency)
+	if err != nil {
+		if m.Currency == "" {
+			return sign + amount
+		}
+		return sign + amount + "\u00a0" + m.Currency
+	}
+	symbol := p.Sprint(currency.Symbol(unit))
+	if symbolAfter(tag) {
+		return sign + amount + "\u00a0" + symbol
+	}
+	if last := []rune(symbol); unicode.IsLetter(last[len(last)-1]) {
+		// Codes such as BHD need a gap before the digits, one that won't break across lines
+		symbol += "\u00a0"
+	}
+	return sign + symbol + amount
+}
+
+// MarshalJSON encodes the amount as a decimal string so no precision is lost,
+// e.g. {"amount":"12.50","currency":"GBP"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON decodes the form MarshalJSON writes
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	parsed, err := Parse(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Totals sums amounts in any number of currencies, for reports. Each amount is
+// only ever added to others in its own currency, so adding can't fail short of
+// overflowing 64 bits of minor units.
+type Totals map[string]Money
+
+// Add adds m to the total in its currency, failing with ErrOverflow if the
+// total gets too big
+func (t Totals) Add(m Money) error {
+	sum, err := t[m.Currency].Add(m)
+	if err != nil {
+		return err
+	}
+	t[m.Currency] = sum
+	return nil
+}
+
+// Merge adds every total in o, stopping at the first that overflows
+func (t Totals) Merge(o Totals) error {
+	for _, m := range o {
+		if err := t.Add(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String formats the totals in currency order joined by " + ", e.g. "£12.00 + €3.50"
+func (t Totals) String() string {
+	values := make([]string, 0, len(t))
+	for _, code := range slices.Sorted(maps.Keys(t)) {
+		values = append(values, t[code].String())
+	}
+	return strings.Join(values, " + ")
+}
+
+// align gives a zero value the other amount's currency, and checks the currencies match
+func align(a, b Money) (Money, Money, error) {
+	switch {
+	case a.Currency == b.Currency:
+	case a.Currency == "" && a.Amount == 0:
+		a.Currency = b.Currency
+	case b.Currency == "" && b.Amount == 0:
+		b.Currency = a.Currency
+	default:
+		return a, b, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Currency, b.Currency)
+	}
+	return a, b, nil
+}
+
+// split returns the whole major units and the zero-padded minor digits of the absolute amount
+func (m Money) split() (uint64, string) {
+	exp := m.Exponent()
+	amount := uint64(m.Amount)
+	if m.Amount < 0 {
+		amount = -amount
+	}
+	if exp == 0 {
+		return amount, ""
+	}
+	scale := uint64(math.Pow10(exp))
+	return amount / scale, fmt.Sprintf("%0*d", exp, amount%scale)
+}
+
+// symbolAfter reports whether the language writes the currency symbol after the amount
+func symbolAfter(tag language.Tag) bool {
+	base, _ := tag.Base()
+	switch base.String() {
+	case "cs", "da", "de", "es", "fi", "fr", "hu", "it", "nb", "no", "pl", "pt", "ro", "ru", "sk", "sv":
+		return true
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
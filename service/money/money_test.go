@@ -0,0 +1,153 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s        string
+		currency string
+		want     Money
+	}{
+		{"12.50", "GBP", Money{1250, "GBP"}},
+		{"12.5", "gbp", Money{1250, "GBP"}},
+		{"12", "GBP", Money{1200, "GBP"}},
+		{" -3.456 ", "GBP", Money{-346, "GBP"}},
+		{"0.004", "GBP", Money{0, "GBP"}},
+		{".99", "EUR", Money{99, "EUR"}},
+		{"1500", "JPY", Money{1500, "JPY"}},
+		{"1.5", "JPY", Money{2, "JPY"}},
+		{"1.2345", "BHD", Money{1235, "BHD"}},
+		{"7.25", "", Money{725, ""}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.s, tt.currency)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q, %q) = %+v, %v, want %+v", tt.s, tt.currency, got, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "-", ".", "abc", "1.2.3", "1,000.00", "£5", "99999999999999999999"} {
+		if _, err := Parse(s, "GBP"); err == nil {
+			t.Errorf("Parse(%q) should fail", s)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     Money
+	}{
+		{18.5, "GBP", Money{1850, "GBP"}},
+		{0.1 + 0.2, "GBP", Money{30, "GBP"}},
+		{-2.675, "EUR", Money{-268, "EUR"}},
+		{1500.4, "JPY", Money{1500, "JPY"}},
+	}
+	for _, tt := range tests {
+		if got := FromFloat(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FromFloat(%v, %q) = %+v, want %+v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := New(1250, "GBP"), New(375, "GBP")
+	if got, err := a.Add(b); err != nil || got != New(1625, "GBP") {
+		t.Errorf("Add = %+v, %v", got, err)
+	}
+	if got, err := b.Sub(a); err != nil || got != New(-875, "GBP") {
+		t.Errorf("Sub = %+v, %v", got, err)
+	}
+	if got, err := (Money{}).Add(a); err != nil || got != a {
+		t.Errorf("zero value Add = %+v, %v, want %+v", got, err, a)
+	}
+	if _, err := a.Add(New(100, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add across currencies error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := New(math.MaxInt64, "GBP").Add(New(1, "GBP")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Add overflow error = %v, want ErrOverflow", err)
+	}
+	if got, err := a.Mul(3); err != nil || got != New(3750, "GBP") {
+		t.Errorf("Mul = %+v, %v", got, err)
+	}
+	if _, err := New(math.MaxInt64/2+1, "GBP").Mul(2); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Mul overflow error = %v, want ErrOverflow", err)
+	}
+
+	divs := []struct {
+		amount int64
+		n      int
+		want   int64
+	}{{2000, 3, 667}, {1000, 3, 333}, {5, 2, 3}, {-5, 2, -3}, {1000, -3, -333}}
+	for _, tt := range divs {
+		if got := New(tt.amount, "GBP").Div(tt.n); got.Amount != tt.want {
+			t.Errorf("%d.Div(%d) = %d, want %d", tt.amount, tt.n, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		m    Money
+		tag  language.Tag
+		want string
+	}{
+		{New(123450, "GBP"), language.BritishEnglish, "£1,234.50"},
+		{New(-150, "GBP"), language.BritishEnglish, "-£1.50"},
+		{New(999, "USD"), language.AmericanEnglish, "$9.99"},
+		{New(999, "USD"), language.BritishEnglish, "US$9.99"},
+		{New(1500, "JPY"), language.Japanese, "￥1,500"},
+		{New(123450, "EUR"), language.German, "1.234,50\u00a0€"},
+		{New(1234, "BHD"), language.BritishEnglish, "BHD\u00a01.234"},
+		{New(725, "XYZ"), language.BritishEnglish, "7.25\u00a0XYZ"},
+		{New(725, ""), language.BritishEnglish, "7.25"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Format(tt.tag); got != tt.want {
+			t.Errorf("%+v.Format(%s) = %q, want %q", tt.m, tt.tag, got, tt.want)
+		}
+	}
+	if got := New(-5, "GBP").Decimal(); got != "-0.05" {
+		t.Errorf("Decimal() = %q, want -0.05", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1250, "GBP"))
+	if err != nil || string(data) != `{"amount":"12.50","currency":"GBP"}` {
+		t.Fatalf("Marshal = %s, %v", data, err)
+	}
+	var m Money
+	if err := json.Unmarshal(data, &m); err != nil || m != New(1250, "GBP") {
+		t.Errorf("Unmarshal = %+v, %v", m, err)
+	}
+}
+
+func TestTotals(t *testing.T) {
+	totals := Totals{}
+	for _, m := range []Money{New(1200, "GBP"), New(350, "EUR"), New(50, "GBP")} {
+		if err := totals.Add(m); err != nil {
+			t.Fatalf("Add(%v): %v", m, err)
+		}
+	}
+	if got, want := totals.String(), "€3.50 + £12.50"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if err := totals.Merge(Totals{"EUR": New(150, "EUR")}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if got := totals["EUR"]; got != New(500, "EUR") {
+		t.Errorf("merged EUR total = %+v, want 5.00", got)
+	}
+	if err := totals.Add(New(math.MaxInt64, "GBP")); !errors.Is(err, ErrOverflow) || totals["GBP"] != New(1250, "GBP") {
+		t.Errorf("overflowing Add = %v leaving %v, want ErrOverflow and the total unchanged", err, totals["GBP"])
+	}
+}
@@ -0,0 +1,250 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Calendar views
+const (
+	CalendarWeek  = "week"
+	CalendarMonth = "month"
+)
+
+// Delivery is an order due for delivery and where it's going
+type Delivery struct {
+	Order   Order  `json:"order"`
+	Address string `json:"address"`
+}
+
+// DeliveryDay is every order due for delivery on one date
+type DeliveryDay struct {
+	Date       time.Time    `json:"date"` // midnight UTC on the delivery date
+	Deliveries []Delivery   `json:"deliveries"`
+	Totals     money.Totals `json:"totals"` // gross order value per currency
+}
+
+// Total formats the value of the day's deliveries in each currency
+func (d DeliveryDay) Total() string {
+	return formatTotals(d.Totals)
+}
+
+// DeliveryCalendar is a week or month of delivery days, laid out in weeks
+// starting on Monday. A month includes the days of neighbouring months that
+// fill its first and last weeks.
+type DeliveryCalendar struct {
+	View   string          `json:"view"`
+	Start  time.Time       `json:"start"` // first day of the week or month shown
+	Prev   time.Time       `json:"prev"`  // start of the previous week or month
+	Next   time.Time       `json:"next"`  // start of the following week or month
+	Weeks  [][]DeliveryDay `json:"weeks"`
+	Count  int             `json:"count"`
+	Totals money.Totals    `json:"totals"`
+}
+
+// Total formats the value of every delivery in the calendar in each currency
+func (c DeliveryCalendar) Total() string {
+	return formatTotals(c.Totals)
+}
+
+// InView reports whether day falls in the week or month being shown, rather
+// than in a neighbouring month padding out the grid
+func (c DeliveryCalendar) InView(day DeliveryDay) bool {
+	return c.View != CalendarMonth || day.Date.Month() == c.Start.Month()
+}
+
+// calendarRange returns the first and last day shown by a week or month view
+// containing date, and the starts of the previous and next views
+func calendarRange(view string, date time.Time) (start, from, to, prev, next time.Time) {
+	date = civilDate(date)
+	if view == CalendarMonth {
+		start = date.AddDate(0, 0, 1-date.Day())
+		end := start.AddDate(0, 1, -1)
+		return start, startOfWeek(start), startOfWeek(end).AddDate(0, 0, 6), start.AddDate(0, -1, 0), start.AddDate(0, 1, 0)
+	}
+	start = startOfWeek(date)
+	return start, start, start.AddDate(0, 0, 6), start.AddDate(0, 0, -7), start.AddDate(0, 0, 7)
+}
+
+// DeliveryCalendar returns the week or month view containing date
+func (s *OrderService) DeliveryCalendar(ctx context.Context, view string, date time.Time) (*DeliveryCalendar, error) {
+	if view != CalendarMonth {
+		view = CalendarWeek
+	}
+	start, from, to, prev, next := calendarRange(view, date)
+	days, err := s.DeliverySchedule(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &DeliveryCalendar{View: view, Start: start, Prev: prev, Next: next, Totals: money.Totals{}}
+	for i := 0; i < len(days); i += 7 {
+		calendar.Weeks = append(calendar.Weeks, days[i:min(i+7, len(days))])
+	}
+	for _, day := range days {
+		if !calendar.InView(day) {
+			continue
+		}
+		calendar.Count += len(day.Deliveries)
+		if err := calendar.Totals.Merge(day.Totals); err != nil {
+			return nil, fmt.Errorf("failed to total deliveries: %w", err)
+		}
+	}
+	return calendar, nil
+}
+
+// DeliverySchedule returns a DeliveryDay for every date from from to to
+// inclusive, including days with nothing to deliver. Only Orderspace orders
+// carry a delivery date; cancelled orders are left out.
+func (s *OrderService) DeliverySchedule(ctx context.Context, from, to time.Time) ([]DeliveryDay, error) {
+	from, to = civilDate(from), civilDate(to)
+	rows, err := s.Queries.ListOrdersByDeliveryDate(ctx, db.ListOrdersByDeliveryDateParams{
+		FromDate:         pgtype.Date{Time: from, Valid: true},
+		ToDate:           pgtype.Date{Time: to, Valid: true},
+		HiddenLifecycles: []string{string(StatusCancelled)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders by delivery date: %w", err)
+	}
+
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	addresses, err := s.Queries.ListShippingAddressesByOrders(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery addresses: %w", err)
+	}
+	return s.buildDeliveryDays(from, to, rows, addresses)
+}
+
+// buildDeliveryDays groups stored orders into a day for each date from from to to
+func (s *OrderService) buildDeliveryDays(from, to time.Time, rows []db.Order, addresses []db.Address) ([]DeliveryDay, error) {
+	byOrder := make(map[int64]db.Address, len(addresses))
+	for _, a := range addresses {
+		byOrder[a.OrderID] = a
+	}
+
+	var days []DeliveryDay
+	index := map[time.Time]int{}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		index[date] = len(days)
+		days = append(days, DeliveryDay{Date: date, Totals: money.Totals{}})
+	}
+
+	for _, row := range rows {
+		if !row.DeliveryDate.Valid {
+			continue
+		}
+		i, ok := index[civilDate(row.DeliveryDate.Time)]
+		if !ok {
+			continue
+		}
+		o, err := s.convertStoredOrder(row)
+		if err != nil {
+			return nil, err
+		}
+		days[i].Deliveries = append(days[i].Deliveries, Delivery{Order: o, Address: formatAddress(byOrder[row.ID])})
+		if err := days[i].Totals.Add(o.Total); err != nil {
+			return nil, fmt.Errorf("failed to total deliveries on %s: %w", days[i].Date.Format(time.DateOnly), err)
+		}
+	}
+	return days, nil
+}
+
+// WriteICS writes the deliveries as an iCalendar feed of all-day events, one per order
+func WriteICS(w io.Writer, name string, days []DeliveryDay, now time.Time) error {
+	var b strings.Builder
+	line := func(s string) {
+		// Lines longer than 75 octets are folded onto continuation lines starting with a space
+		for len(s) > 75 {
+			cut := 75
+			for cut > 0 && !isUTF8Start(s[cut]) {
+				cut--
+			}
+			b.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//paddy-cap//deliveries//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICS(name))
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, day := range days {
+		for _, d := range day.Deliveries {
+			o := d.Order
+			line("BEGIN:VEVENT")
+			line(fmt.Sprintf("UID:%s-%s@paddy-cap", o.Origin, o.ID))
+			line("DTSTAMP:" + stamp)
+			line("DTSTART;VALUE=DATE:" + day.Date.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + day.Date.AddDate(0, 0, 1).Format("20060102"))
+			line(escapeICSProperty("SUMMARY", fmt.Sprintf("Deliver #%d %s", o.OrderNumber, o.Customer)))
+			if d.Address != "" {
+				line(escapeICSProperty("LOCATION", d.Address))
+			}
+			line(escapeICSProperty("DESCRIPTION", fmt.Sprintf("%s order #%d\n%s, %s", titleOrigin(o.Origin), o.OrderNumber, o.Total, o.Status.Label())))
+			line("TRANSP:TRANSPARENT")
+			line("END:VEVENT")
+		}
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func escapeICSProperty(name, value string) string {
+	return name + ":" + escapeICS(value)
+}
+
+// escapeICS escapes text values as RFC 5545 requires
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func titleOrigin(origin string) string {
+	switch origin {
+	case Orderspace:
+		return "Orderspace"
+	case WooCommerce:
+		return "WooCommerce"
+	}
+	return origin
+}
+
+// formatAddress joins the non-empty parts of an address onto one line
+func formatAddress(a db.Address) string {
+	var parts []string
+	for _, p := range []string{a.CompanyName, a.Line1, a.Line2, a.City, a.State, a.PostalCode, a.Country} {
+		parts = appendUnique(parts, p)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// civilDate returns midnight UTC on t's calendar date, so dates compare
+// equal whatever location they were read in
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// startOfWeek returns the Monday on or before date
+func startOfWeek(date time.Time) time.Time {
+	return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+}
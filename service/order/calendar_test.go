@@ -0,0 +1,159 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendarRange(t *testing.T) {
+	tests := []struct {
+		name                        string
+		view                        string
+		date                        time.Time
+		start, from, to, prev, next time.Time
+	}{
+		{
+			name:  "week from a Sunday",
+			view:  CalendarWeek,
+			date:  time.Date(2025, 3, 16, 23, 30, 0, 0, time.UTC),
+			start: date(2025, 3, 10), from: date(2025, 3, 10), to: date(2025, 3, 16),
+			prev: date(2025, 3, 3), next: date(2025, 3, 17),
+		},
+		{
+			name:  "month starting on a Saturday",
+			view:  CalendarMonth,
+			date:  date(2025, 2, 14),
+			start: date(2025, 2, 1), from: date(2025, 1, 27), to: date(2025, 3, 2),
+			prev: date(2025, 1, 1), next: date(2025, 3, 1),
+		},
+		{
+			name:  "month starting on a Monday",
+			view:  CalendarMonth,
+			date:  date(2025, 12, 31),
+			start: date(2025, 12, 1), from: date(2025, 12, 1), to: date(2026, 1, 4),
+			prev: date(2025, 11, 1), next: date(2026, 1, 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, from, to, prev, next := calendarRange(tt.view, tt.date)
+			got := []time.Time{start, from, to, prev, next}
+			want := []time.Time{tt.start, tt.from, tt.to, tt.prev, tt.next}
+			for i := range got {
+				if !got[i].Equal(want[i]) {
+					t.Errorf("calendarRange() = %v, want %v", got, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBuildDeliveryDays(t *testing.T) {
+	s := &OrderService{TitleCaser: cases.Title(language.English)}
+
+	var rows []db.Order
+	var addresses []db.Address
+	for i, o := range orderspacetest.Orders() {
+		raw, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		delivery, err := time.Parse("2006-01-02", o.DeliveryDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := int64(i + 1)
+		rows = append(rows, db.Order{
+			ID:              id,
+			Origin:          Orderspace,
+			ExternalID:      o.ID,
+			Currency:        o.Currency,
+			GrossTotalMinor: money.FromFloat(o.GrossTotal, o.Currency).Amount,
+			DeliveryDate:    pgtype.Date{Time: delivery, Valid: true},
+			Raw:             raw,
+		})
+		a := o.ShippingAddress
+		addresses = append(addresses, db.Address{OrderID: id, Kind: AddressShipping, CompanyName: a.CompanyName, Line1: a.Line1, City: a.City, PostalCode: a.PostalCode})
+	}
+
+	days, err := s.buildDeliveryDays(date(2025, 3, 17), date(2025, 3, 23), rows, addresses)
+	if err != nil {
+		t.Fatalf("buildDeliveryDays: %v", err)
+	}
+	if len(days) != 7 {
+		t.Fatalf("got %d days, want 7", len(days))
+	}
+	// The fixtures deliver on 17 and 18 March; the order due on the 13th is outside the range
+	for i, want := range []int{1, 1, 0, 0, 0, 0, 0} {
+		if got := len(days[i].Deliveries); got != want {
+			t.Errorf("%s: %d deliveries, want %d", days[i].Date.Format("Jan 2"), got, want)
+		}
+	}
+	monday := days[0]
+	if d := monday.Deliveries[0]; d.Order.ID != "or_7Pm4Yc2r" || d.Address == "" || d.Order.DeliveryDate.IsZero() {
+		t.Errorf("17 March delivery = %+v, want or_7Pm4Yc2r with an address and delivery date", d)
+	}
+	if want := money.New(rows[1].GrossTotalMinor, "GBP").String(); monday.Total() != want {
+		t.Errorf("17 March total = %s, want %s", monday.Total(), want)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	days := []DeliveryDay{{
+		Date: date(2025, 3, 18),
+		Deliveries: []Delivery{{
+			Order: Order{
+				ID:          "or_3kq9Zt1x",
+				OrderNumber: 1043,
+				Customer:    "Bean There Cafe; Ltd",
+				Total:       money.New(12300, "GBP"),
+				Status:      "New",
+				Origin:      Orderspace,
+			},
+			Address: "Bean There Cafe, 12 High Street, Unit 4 The Old Warehouse Building, Bristol, BS1 4DJ, GB",
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, "Deliveries", days, time.Date(2025, 3, 15, 8, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteICS: %v", err)
+	}
+	ics := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:orderspace-or_3kq9Zt1x@paddy-cap\r\n",
+		"DTSTAMP:20250315T080000Z\r\n",
+		"DTSTART;VALUE=DATE:20250318\r\nDTEND;VALUE=DATE:20250319\r\n",
+		`SUMMARY:Deliver #1043 Bean There Cafe\; Ltd` + "\r\n",
+		`DESCRIPTION:Orderspace order #1043\n£123.00\, New` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("feed missing %q:\n%s", want, ics)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets not folded: %q", len(line), line)
+		}
+	}
+	if !strings.Contains(ics, "\r\n ") {
+		t.Error("long LOCATION was not folded onto a continuation line")
+	}
+}
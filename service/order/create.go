@@ -0,0 +1,257 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/transport"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewOrder is an order to place in a sales channel for one of its existing customers
+type NewOrder struct {
+	CustomerID   string
+	Lines        []NewOrderLine
+	DeliveryDate time.Time // Orderspace only; zero for none
+	CustomerNote string    // shown to the customer
+	InternalNote string    // kept for staff
+}
+
+// NewOrderLine is a quantity of one product listing, identified as in ProductListing
+type NewOrderLine struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"` // empty for simple WooCommerce products
+	Quantity  int    `json:"quantity"`
+}
+
+// NewOrderError explains why an order can't be placed as asked, naming the
+// part of the request at fault
+type NewOrderError struct {
+	Field   string
+	Problem string
+}
+
+func (e *NewOrderError) Error() string {
+	return fmt.Sprintf("invalid order: %s %s", e.Field, e.Problem)
+}
+
+// CreateOrder places an order in its channel, priced by the channel, and
+// stores a copy so it appears in the order list straight away. The channel
+// has the order once it is created, so failing to store it is only logged.
+func (s *OrderService) CreateOrder(ctx context.Context, origin string, o NewOrder) (*Order, error) {
+	o.CustomerID = strings.TrimSpace(o.CustomerID)
+	o.CustomerNote = strings.TrimSpace(o.CustomerNote)
+	o.InternalNote = strings.TrimSpace(o.InternalNote)
+	if o.CustomerID == "" {
+		return nil, &NewOrderError{Field: "customer_id", Problem: "is required"}
+	}
+	if len(o.Lines) == 0 {
+		return nil, &NewOrderError{Field: "lines", Problem: "must include at least one product"}
+	}
+	for i, line := range o.Lines {
+		if line.Quantity < 1 {
+			return nil, &NewOrderError{Field: fmt.Sprintf("lines[%d].quantity", i), Problem: "must be at least 1"}
+		}
+	}
+
+	var converted Order
+	var err error
+	switch origin {
+	case Orderspace:
+		var created *orderspace.Order
+		created, err = s.createOrderspaceOrder(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.SaveOrderspaceOrder(ctx, *created); err != nil {
+			slog.Warn("Failed to save created order", "origin", origin, "orderID", created.ID, "error_message", err)
+		}
+		if converted, err = s.ConvertOrderspaceOrder(*created); err != nil {
+			return nil, fmt.Errorf("placed orderspace order %s but failed to read it back: %w", created.ID, err)
+		}
+	case WooCommerce:
+		var created *woocommerce.Order
+		created, err = s.createWooOrder(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.SaveWooOrder(ctx, *created); err != nil {
+			slog.Warn("Failed to save created order", "origin", origin, "orderID", created.ID, "error_message", err)
+		}
+		if converted, err = s.ConvertWooOrder(*created); err != nil {
+			return nil, fmt.Errorf("placed woocommerce order %d but failed to read it back: %w", created.ID, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: origin %q", ErrInvalidOrderRef, origin)
+	}
+	return &converted, nil
+}
+
+// OrderProducts returns up to limit orderable listings from one channel whose
+// name or SKU contains query, sorted by name, for adding to a new order. It
+// searches the listings stored by the sync worker rather than the channel.
+func (s *OrderService) OrderProducts(ctx context.Context, origin, query string, limit int) ([]ProductListing, error) {
+	if origin != Orderspace && origin != WooCommerce {
+		return nil, fmt.Errorf("%w: origin %q", ErrInvalidOrderRef, origin)
+	}
+	rows, err := s.Queries.SearchOrderableListings(ctx, db.SearchOrderableListingsParams{
+		Origin:     origin,
+		Pattern:    likeEscaper.Replace(strings.TrimSpace(query)),
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s listings: %w", origin, err)
+	}
+	listings := make([]ProductListing, 0, len(rows))
+	for _, row := range rows {
+		listings = append(listings, storedListing(row))
+	}
+	return listings, nil
+}
+
+// orderListings finds the stored listing for each line of a new order
+func (s *OrderService) orderListings(ctx context.Context, origin string, lines []NewOrderLine) ([]ProductListing, error) {
+	found := make([]ProductListing, len(lines))
+	for i, line := range lines {
+		row, err := s.Queries.GetProductListing(ctx, db.GetProductListingParams{
+			Origin:    origin,
+			ProductID: line.ProductID,
+			VariantID: line.VariantID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !row.Orderable) {
+			return nil, &NewOrderError{Field: fmt.Sprintf("lines[%d]", i), Problem: "is not a product on sale in " + titleOrigin(origin)}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s listing %s/%s: %w", origin, line.ProductID, line.VariantID, err)
+		}
+		found[i] = storedListing(row)
+	}
+	return found, nil
+}
+
+// createOrderspaceOrder places an Orderspace order at the customer's price list prices
+func (s *OrderService) createOrderspaceOrder(ctx context.Context, o NewOrder) (*orderspace.Order, error) {
+	customer, err := s.OrderspaceClient.GetCustomer(ctx, o.CustomerID)
+	if notFound(err) {
+		return nil, &NewOrderError{Field: "customer_id", Problem: "is not an Orderspace customer"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderspace customer %s: %w", o.CustomerID, err)
+	}
+	listings, err := s.orderListings(ctx, Orderspace, o.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &orderspace.NewOrder{
+		CustomerID:   customer.ID,
+		CustomerNote: o.CustomerNote,
+		InternalNote: o.InternalNote,
+	}
+	if !o.DeliveryDate.IsZero() {
+		req.DeliveryDate = o.DeliveryDate.Format("2006-01-02")
+	}
+	if len(customer.Addresses) > 0 {
+		req.ShippingAddress = &customer.Addresses[0]
+		req.BillingAddress = &customer.Addresses[0]
+	}
+	for i, line := range o.Lines {
+		req.OrderLines = append(req.OrderLines, orderspace.NewOrderLine{SKU: listings[i].SKU, Quantity: line.Quantity})
+	}
+
+	created, err := s.OrderspaceClient.CreateOrder(ctx, req)
+	if problem, ok := refused(err); ok {
+		return nil, &NewOrderError{Field: "order", Problem: "was refused by Orderspace: " + problem}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create orderspace order for customer %s: %w", customer.ID, err)
+	}
+	return created, nil
+}
+
+// createWooOrder places a WooCommerce order to the customer's saved addresses.
+// It is created as processing, as the customer pays on account rather than at
+// checkout, and any internal note is added as a private order note.
+func (s *OrderService) createWooOrder(ctx context.Context, o NewOrder) (*woocommerce.Order, error) {
+	if !o.DeliveryDate.IsZero() {
+		return nil, &NewOrderError{Field: "delivery_date", Problem: "can't be set on WooCommerce orders"}
+	}
+	customerID, err := strconv.Atoi(o.CustomerID)
+	if err != nil {
+		return nil, &NewOrderError{Field: "customer_id", Problem: "is not a WooCommerce customer"}
+	}
+	customer, err := s.WooClient.GetCustomer(ctx, customerID)
+	if notFound(err) {
+		return nil, &NewOrderError{Field: "customer_id", Problem: "is not a WooCommerce customer"}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get woocommerce customer %d: %w", customerID, err)
+	}
+	listings, err := s.orderListings(ctx, WooCommerce, o.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &woocommerce.NewOrder{
+		CustomerID:   customer.ID,
+		Status:       "processing",
+		Billing:      &customer.Billing,
+		Shipping:     &customer.Shipping,
+		CustomerNote: o.CustomerNote,
+	}
+	for i, line := range o.Lines {
+		item := woocommerce.NewOrderLineItem{Quantity: line.Quantity}
+		item.ProductID, _ = strconv.Atoi(listings[i].ProductID)
+		if listings[i].VariantID != "" {
+			item.VariationID, _ = strconv.Atoi(listings[i].VariantID)
+		}
+		req.LineItems = append(req.LineItems, item)
+	}
+
+	created, err := s.WooClient.CreateOrder(ctx, req)
+	if problem, ok := refused(err); ok {
+		return nil, &NewOrderError{Field: "order", Problem: "was refused by WooCommerce: " + problem}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create woocommerce order for customer %d: %w", customerID, err)
+	}
+	// The order exists now, so a missing note is only logged
+	if o.InternalNote != "" {
+		if _, err := s.WooClient.CreateOrderNote(ctx, created.ID, o.InternalNote, false); err != nil {
+			slog.Warn("Failed to add internal note to created order", "origin", WooCommerce, "orderID", created.ID, "error_message", err)
+		}
+	}
+	return created, nil
+}
+
+// notFound reports whether a channel answered that the requested record doesn't exist
+func notFound(err error) bool {
+	var reqErr *transport.Error
+	return errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound
+}
+
+// refused returns the channel's message when it rejected a request as invalid
+func refused(err error) (string, bool) {
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || (reqErr.StatusCode != http.StatusBadRequest && reqErr.StatusCode != http.StatusUnprocessableEntity) {
+		return "", false
+	}
+	var osErr *orderspace.Error
+	var wooErr *woocommerce.Error
+	switch {
+	case errors.As(err, &osErr):
+		return osErr.Message, true
+	case errors.As(err, &wooErr):
+		return wooErr.Message, true
+	}
+	return reqErr.Body, true
+}
@@ -0,0 +1,242 @@
+package order
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/db/dbtest"
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// catalogueService is an OrderService whose fake channels have customers and products
+func catalogueService(t *testing.T) (*OrderService, *woocommercetest.Server) {
+	t.Helper()
+	osrv := orderspacetest.NewServer(orderspacetest.Orders(), orderspacetest.Customers(), orderspacetest.Products())
+	t.Cleanup(osrv.Close)
+	woo := woocommercetest.NewServer(woocommercetest.Orders(), woocommercetest.Customers(), woocommercetest.Products(), woocommercetest.Variations())
+	t.Cleanup(woo.Close)
+	return &OrderService{
+		OrderspaceClient: osrv.NewClient(),
+		WooClient:        woo.NewClient(),
+		TitleCaser:       cases.Title(language.English),
+	}, woo
+}
+
+// syncedListings syncs the fake channels' listings into a fake store, which
+// answers listing lookups, SKU matches and searches from them as the queries would
+func syncedListings(t *testing.T, s *OrderService) *dbtest.DB {
+	t.Helper()
+	store := dbtest.New()
+	s.DB, s.Queries = store, db.New(store)
+	if _, err := s.SyncProductListings(context.Background()); err != nil {
+		t.Fatalf("SyncProductListings: %v", err)
+	}
+	// Upserts take the columns in table order, so their arguments are the stored rows
+	var rows [][]any
+	for _, c := range store.Calls("UpsertProductListing") {
+		rows = append(rows, c.Args)
+	}
+	store.Handle("GetProductListing", func(args []any) ([][]any, error) {
+		for _, row := range rows {
+			if row[0] == args[0] && row[1] == args[1] && row[2] == args[2] {
+				return [][]any{row}, nil
+			}
+		}
+		return nil, nil
+	})
+	store.Handle("ListProductListingsBySKUs", func(args []any) ([][]any, error) {
+		var found [][]any
+		for _, row := range rows {
+			if slices.Contains(args[0].([]string), NormalizeSKU(row[3].(string))) {
+				found = append(found, row)
+			}
+		}
+		return found, nil
+	})
+	store.Handle("SearchOrderableListings", func(args []any) ([][]any, error) {
+		pattern := strings.ToLower(args[1].(string))
+		var found [][]any
+		for _, row := range rows {
+			name, sku := strings.ToLower(row[4].(string)), strings.ToLower(row[3].(string))
+			if row[0] == args[0] && row[8] == true && (strings.Contains(name, pattern) || strings.Contains(sku, pattern)) {
+				found = append(found, row)
+			}
+		}
+		slices.SortFunc(found, func(a, b []any) int {
+			return cmp.Or(cmp.Compare(a[4].(string), b[4].(string)), cmp.Compare(a[3].(string), b[3].(string)))
+		})
+		return found[:min(len(found), int(args[2].(int32)))], nil
+	})
+	return store
+}
+
+func TestCreateOrder(t *testing.T) {
+	s, woo := catalogueService(t)
+	syncedListings(t, s)
+	ctx := context.Background()
+
+	// Placing the orders is tested without the store, which needs a database
+	osOrder, err := s.createOrderspaceOrder(ctx, NewOrder{
+		CustomerID:   "cu_2Xb7Qe9k",
+		Lines:        []NewOrderLine{{ProductID: "pr_4Ka9Tm2x", VariantID: "va_6Rt2Wb8n", Quantity: 3}},
+		DeliveryDate: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+		InternalNote: "Phoned in",
+	})
+	if err != nil {
+		t.Fatalf("createOrderspaceOrder: %v", err)
+	}
+	o, err := s.ConvertOrderspaceOrder(*osOrder)
+	if err != nil {
+		t.Fatalf("ConvertOrderspaceOrder: %v", err)
+	}
+	if o.OrderNumber != 1044 || o.Customer != "Grind & Gather" || o.DeliverOn != "Oct 20, 2026" {
+		t.Errorf("orderspace order = #%d for %q on %q, want #1044 for Grind & Gather on Oct 20, 2026", o.OrderNumber, o.Customer, o.DeliverOn)
+	}
+	if want := money.New(5550, "GBP"); o.Total != want {
+		t.Errorf("orderspace total = %v, want %v", o.Total, want)
+	}
+
+	wooOrder, err := s.createWooOrder(ctx, NewOrder{
+		CustomerID:   "23",
+		Lines:        []NewOrderLine{{ProductID: "44", Quantity: 2}, {ProductID: "47", VariantID: "4702", Quantity: 1}},
+		CustomerNote: "Leave with reception",
+		InternalNote: "Agreed by phone",
+	})
+	if err != nil {
+		t.Fatalf("createWooOrder: %v", err)
+	}
+	if o, err = s.ConvertWooOrder(*wooOrder); err != nil {
+		t.Fatalf("ConvertWooOrder: %v", err)
+	}
+	if o.ID != "5105" || o.Status != StatusReadyToFulfil || o.CustomerNote != "Leave with reception" {
+		t.Errorf("woocommerce order = %s %v note %q, want 5105 ready to fulfil with the customer note", o.ID, o.Status, o.CustomerNote)
+	}
+	if want := money.New(5200, "GBP"); o.Total != want {
+		t.Errorf("woocommerce total = %v, want %v", o.Total, want)
+	}
+	notes := woo.Notes(5105)
+	if !slices.ContainsFunc(notes, func(n woocommerce.OrderNote) bool { return n.Note == "Agreed by phone" && !n.CustomerNote }) {
+		t.Errorf("notes = %+v, want the internal note kept private", notes)
+	}
+
+	tests := []struct {
+		name      string
+		origin    string
+		order     NewOrder
+		wantField string
+	}{
+		{"no customer", Orderspace, NewOrder{Lines: []NewOrderLine{{ProductID: "pr_4Ka9Tm2x", VariantID: "va_1Qe7Lp3s", Quantity: 1}}}, "customer_id"},
+		{"no lines", Orderspace, NewOrder{CustomerID: "cu_2Xb7Qe9k"}, "lines"},
+		{"zero quantity", Orderspace, NewOrder{CustomerID: "cu_2Xb7Qe9k", Lines: []NewOrderLine{{ProductID: "pr_4Ka9Tm2x", VariantID: "va_1Qe7Lp3s"}}}, "lines[0].quantity"},
+		{"unknown orderspace customer", Orderspace, NewOrder{CustomerID: "cu_missing", Lines: []NewOrderLine{{ProductID: "pr_4Ka9Tm2x", VariantID: "va_1Qe7Lp3s", Quantity: 1}}}, "customer_id"},
+		{"inactive product", Orderspace, NewOrder{CustomerID: "cu_2Xb7Qe9k", Lines: []NewOrderLine{{ProductID: "pr_2Wd6Nk7q", VariantID: "va_8Jx1Ps6h", Quantity: 1}}}, "lines[0]"},
+		{"woocommerce delivery date", WooCommerce, NewOrder{CustomerID: "23", Lines: []NewOrderLine{{ProductID: "44", Quantity: 1}}, DeliveryDate: time.Now()}, "delivery_date"},
+		{"unknown woocommerce customer", WooCommerce, NewOrder{CustomerID: "99", Lines: []NewOrderLine{{ProductID: "44", Quantity: 1}}}, "customer_id"},
+		{"variable product without variation", WooCommerce, NewOrder{CustomerID: "23", Lines: []NewOrderLine{{ProductID: "44", Quantity: 1}, {ProductID: "47", Quantity: 1}}}, "lines[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateOrder(ctx, tt.origin, tt.order)
+			var orderErr *NewOrderError
+			if !errors.As(err, &orderErr) || orderErr.Field != tt.wantField {
+				t.Errorf("err = %v, want NewOrderError on %s", err, tt.wantField)
+			}
+		})
+	}
+
+	if _, err := s.CreateOrder(ctx, "shopify", NewOrder{CustomerID: "1", Lines: []NewOrderLine{{ProductID: "1", Quantity: 1}}}); !errors.Is(err, ErrInvalidOrderRef) {
+		t.Errorf("err = %v, want ErrInvalidOrderRef", err)
+	}
+}
+
+func TestOrderProducts(t *testing.T) {
+	s, _ := catalogueService(t)
+	store := syncedListings(t, s)
+	ctx := context.Background()
+
+	listings, err := s.OrderProducts(ctx, Orderspace, "", 10)
+	if err != nil {
+		t.Fatalf("OrderProducts(orderspace): %v", err)
+	}
+	var skus []string
+	for _, l := range listings {
+		skus = append(skus, l.SKU)
+	}
+	// The decaf is inactive, so none of its variants can be ordered
+	if want := []string{"FLT-250-FL", "ESP-1KG-WB", "ESP-250-WB"}; !slices.Equal(skus, want) {
+		t.Errorf("orderspace SKUs = %q, want %q", skus, want)
+	}
+
+	listings, err = s.OrderProducts(ctx, WooCommerce, "esp-1kg", 10)
+	if err != nil {
+		t.Fatalf("OrderProducts(woocommerce): %v", err)
+	}
+	if len(listings) != 2 || listings[0].VariantID == "" {
+		t.Errorf("woocommerce listings = %+v, want the two bulk espresso variations", listings)
+	}
+
+	if listings, _ = s.OrderProducts(ctx, WooCommerce, "", 2); len(listings) != 2 {
+		t.Errorf("got %d listings, want the limit of 2", len(listings))
+	}
+
+	// Searches read the stored listings, escaping wildcards in what was typed
+	if _, err := s.OrderProducts(ctx, WooCommerce, " 100%_ ", 10); err != nil {
+		t.Fatalf("OrderProducts: %v", err)
+	}
+	searches := store.Calls("SearchOrderableListings")
+	if got := searches[len(searches)-1].Args[1]; got != `100\%\_` {
+		t.Errorf("pattern = %q, want the wildcards escaped", got)
+	}
+	if _, err := s.OrderProducts(ctx, "shopify", "", 10); !errors.Is(err, ErrInvalidOrderRef) {
+		t.Errorf("err = %v, want ErrInvalidOrderRef", err)
+	}
+}
+
+func TestCreateOrderUnreadableAmount(t *testing.T) {
+	s, woo := catalogueService(t)
+	syncedListings(t, s)
+	// garbling passes everything through to the fake, but garbles the total of created orders
+	garbling := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		woo.Config.Handler.ServeHTTP(rec, r)
+		if r.Method != http.MethodPost || rec.Code != http.StatusCreated {
+			maps.Copy(w.Header(), rec.Header())
+			w.WriteHeader(rec.Code)
+			w.Write(rec.Body.Bytes())
+			return
+		}
+		var created woocommerce.Order
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+			t.Errorf("created order: %v", err)
+		}
+		created.Total = "52,00"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+	}))
+	defer garbling.Close()
+	s.WooClient = woocommerce.NewClient(garbling.URL, woocommercetest.ConsumerKey, woocommercetest.ConsumerSecret)
+
+	created, err := s.CreateOrder(context.Background(), WooCommerce, NewOrder{
+		CustomerID: "23",
+		Lines:      []NewOrderLine{{ProductID: "44", Quantity: 2}},
+	})
+	if !errors.Is(err, ErrUnreadableAmount) {
+		t.Errorf("CreateOrder = %+v, %v, want ErrUnreadableAmount", created, err)
+	}
+}
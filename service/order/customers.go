@@ -0,0 +1,301 @@
+package order
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
+)
+
+// ErrCustomerNotFound is returned when no stored customer has the requested ID
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// UnifiedCustomer groups the stored customer records from every channel that
+// belong to the same person or business
+type UnifiedCustomer struct {
+	ID          int64 // lowest stored customer ID in the group, stable enough for links
+	Name        string
+	Contacts    []string
+	Emails      []string
+	Phones      []string
+	Channels    []string
+	Records     []db.Customer
+	OrderCount  int64
+	Totals      money.Totals // lifetime value per currency
+	LastOrderAt time.Time    // zero when no orders are stored
+}
+
+// LifetimeValue formats the lifetime value in each currency the customer has spent in
+func (c UnifiedCustomer) LifetimeValue() string {
+	return formatTotals(c.Totals)
+}
+
+// formatTotals formats an amount per currency as "£1.00 + €2.00". With nothing
+// totalled there is no currency to show a zero in, so it is a dash.
+func formatTotals(totals money.Totals) string {
+	if len(totals) == 0 {
+		return "—"
+	}
+	return totals.String()
+}
+
+// InChannel reports whether any of the customer's records came from origin
+func (c UnifiedCustomer) InChannel(origin string) bool {
+	return slices.Contains(c.Channels, origin)
+}
+
+// ListUnifiedCustomers returns every customer matched across channels, most
+// recent order first. A non-empty query keeps customers whose name, contacts,
+// emails or phone numbers contain it.
+func (s *OrderService) ListUnifiedCustomers(ctx context.Context, query string) ([]UnifiedCustomer, error) {
+	customers, err := s.loadUnifiedCustomers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return customers, nil
+	}
+	return slices.DeleteFunc(customers, func(c UnifiedCustomer) bool {
+		return !matchesUnifiedCustomer(c, query)
+	}), nil
+}
+
+// GetUnifiedCustomer returns the unified customer containing the stored
+// customer customerID, along with their orders from every channel, newest first
+func (s *OrderService) GetUnifiedCustomer(ctx context.Context, customerID int64) (*UnifiedCustomer, []Order, error) {
+	customers, err := s.loadUnifiedCustomers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i := slices.IndexFunc(customers, func(c UnifiedCustomer) bool {
+		return slices.ContainsFunc(c.Records, func(r db.Customer) bool { return r.ID == customerID })
+	})
+	if i < 0 {
+		return nil, nil, ErrCustomerNotFound
+	}
+	customer := &customers[i]
+
+	ids := make([]int64, 0, len(customer.Records))
+	for _, r := range customer.Records {
+		ids = append(ids, r.ID)
+	}
+	rows, err := s.Queries.ListOrdersByCustomers(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list orders for customer %d: %w", customerID, err)
+	}
+
+	orders := make([]Order, 0, len(rows))
+	for _, row := range rows {
+		o, err := s.convertStoredOrder(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		orders = append(orders, o)
+	}
+	return customer, orders, nil
+}
+
+// loadUnifiedCustomers reads every stored customer with their addresses and
+// order totals and groups them
+func (s *OrderService) loadUnifiedCustomers(ctx context.Context) ([]UnifiedCustomer, error) {
+	customers, err := s.Queries.ListCustomers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	addresses, err := s.Queries.ListCustomerAddresses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer addresses: %w", err)
+	}
+	totals, err := s.Queries.ListCustomerOrderTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer order totals: %w", err)
+	}
+	return buildUnifiedCustomers(customers, addresses, totals)
+}
+
+// buildUnifiedCustomers merges customer records that share an email address,
+// a company name or a delivery address, in any channel. Only sales count
+// towards their order totals, leaving out unpaid, cancelled and refunded orders.
+func buildUnifiedCustomers(customers []db.Customer, addresses []db.ListCustomerAddressesRow, totals []db.ListCustomerOrderTotalsRow) ([]UnifiedCustomer, error) {
+	index := make(map[int64]int, len(customers))
+	for i, c := range customers {
+		index[c.ID] = i
+	}
+
+	// Union-find over record positions, joined by any shared match key
+	parent := make([]int, len(customers))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	owners := map[string]int{}
+	link := func(i int, key string) {
+		if key == "" {
+			return
+		}
+		if j, ok := owners[key]; ok {
+			parent[find(i)] = find(j)
+			return
+		}
+		owners[key] = i
+	}
+
+	for i, c := range customers {
+		link(i, emailKey(c.Email))
+		link(i, companyKey(c.CompanyName))
+	}
+	for _, a := range addresses {
+		i, ok := index[a.CustomerID]
+		if !ok {
+			continue
+		}
+		link(i, companyKey(a.CompanyName))
+		link(i, addressKey(a.Line1, a.PostalCode))
+	}
+
+	groups := map[int]*UnifiedCustomer{}
+	var roots []int
+	for i, c := range customers {
+		root := find(i)
+		u, ok := groups[root]
+		if !ok {
+			u = &UnifiedCustomer{ID: c.ID, Totals: money.Totals{}}
+			groups[root] = u
+			roots = append(roots, root)
+		}
+		u.ID = min(u.ID, c.ID)
+		u.Records = append(u.Records, c)
+		u.Contacts = appendUnique(u.Contacts, c.ContactName)
+		u.Emails = appendUnique(u.Emails, strings.ToLower(c.Email))
+		u.Phones = appendUnique(u.Phones, c.Phone)
+		u.Channels = appendUnique(u.Channels, c.Origin)
+	}
+
+	for _, t := range totals {
+		i, ok := index[t.CustomerID]
+		if !ok || !Status(t.Lifecycle).Sold() {
+			continue
+		}
+		u := groups[find(i)]
+		u.OrderCount += t.OrderCount
+		if err := u.Totals.Add(money.New(t.LifetimeValueMinor, t.Currency)); err != nil {
+			return nil, fmt.Errorf("failed to total orders of customer %d: %w", t.CustomerID, err)
+		}
+		// Orders whose placed date couldn't be read leave no last order date
+		if t.LastOrderAt.Valid && t.LastOrderAt.Time.After(u.LastOrderAt) {
+			u.LastOrderAt = t.LastOrderAt.Time
+		}
+	}
+
+	unified := make([]UnifiedCustomer, 0, len(roots))
+	for _, root := range roots {
+		u := groups[root]
+		u.Name = unifiedName(u.Records)
+		slices.Sort(u.Channels)
+		unified = append(unified, *u)
+	}
+	slices.SortStableFunc(unified, func(a, b UnifiedCustomer) int {
+		if c := b.LastOrderAt.Compare(a.LastOrderAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return unified, nil
+}
+
+// unifiedName prefers a company name, favouring the wholesale record, then a contact name, then an email
+func unifiedName(records []db.Customer) string {
+	var company, contact, email string
+	for _, r := range records {
+		if r.CompanyName != "" && (company == "" || r.Origin == Orderspace) {
+			company = r.CompanyName
+		}
+		if contact == "" {
+			contact = r.ContactName
+		}
+		if email == "" {
+			email = r.Email
+		}
+	}
+	return cmp.Or(company, contact, email)
+}
+
+func matchesUnifiedCustomer(c UnifiedCustomer, query string) bool {
+	fields := append([]string{c.Name}, c.Contacts...)
+	fields = append(fields, c.Emails...)
+	fields = append(fields, c.Phones...)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func emailKey(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return ""
+	}
+	return "email:" + email
+}
+
+// companySuffixes are legal-form words dropped before comparing company names
+var companySuffixes = []string{"ltd", "limited", "inc", "llc", "co", "plc"}
+
+// companyKey reduces a company name to lower-case letters and digits, so that
+// "Bean There Cafe Ltd." and "bean there cafe" match. Very short names are ignored.
+func companyKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for len(words) > 0 && slices.Contains(companySuffixes, words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	key := strings.Join(words, "")
+	if len(key) < 3 {
+		return ""
+	}
+	return "company:" + key
+}
+
+// addressKey identifies a delivery address by its first line and postcode, ignoring case and spacing
+func addressKey(line1, postalCode string) string {
+	squash := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, s)
+	}
+	line1, postalCode = squash(line1), squash(postalCode)
+	if line1 == "" || postalCode == "" {
+		return ""
+	}
+	return "address:" + postalCode + "|" + line1
+}
+
+func appendUnique(values []string, v string) []string {
+	v = strings.TrimSpace(v)
+	if v == "" || slices.Contains(values, v) {
+		return values
+	}
+	return append(values, v)
+}
@@ -0,0 +1,101 @@
+package order
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestBuildUnifiedCustomers(t *testing.T) {
+	customers := []db.Customer{
+		{ID: 1, Origin: Orderspace, ExternalID: "cu_8Hn2Lw0p", CompanyName: "Bean There Cafe Ltd", ContactName: "Alex Morgan", Email: "orders@beanthere.example"},
+		{ID: 2, Origin: WooCommerce, ExternalID: "31", CompanyName: "bean there cafe", ContactName: "Alex Morgan", Email: "alex@beanthere.example"},
+		{ID: 3, Origin: WooCommerce, ExternalID: "guest:jo@beanthere.example", ContactName: "Jo Reid", Email: "jo@beanthere.example"},
+		{ID: 4, Origin: WooCommerce, ExternalID: "17", ContactName: "Jamie Lee", Email: "Jamie@Example.com"},
+		{ID: 5, Origin: WooCommerce, ExternalID: "guest:jamie@example.com", ContactName: "Jamie Lee", Email: "jamie@example.com"},
+		{ID: 6, Origin: Orderspace, ExternalID: "cu_2Xb7Qe9k", CompanyName: "Grind & Gather", Email: "hello@grindgather.example"},
+		{ID: 7, Origin: WooCommerce, ExternalID: "23", CompanyName: "Co", ContactName: "Chris Doyle", Email: "chris@example.com"},
+	}
+	addresses := []db.ListCustomerAddressesRow{
+		// Jo's guest order went to the cafe, so it belongs with the cafe
+		{CustomerID: 3, Line1: "12 High St.", PostalCode: "bs14dj"},
+		{CustomerID: 1, Line1: "12 High St", PostalCode: "BS1 4DJ"},
+		{CustomerID: 7, Line1: "", PostalCode: "BS1 4DJ"},
+	}
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 9, 0, 0, 0, time.UTC) }
+	at := func(d int) pgtype.Timestamptz { return pgtype.Timestamptz{Time: day(d), Valid: true} }
+	totals := []db.ListCustomerOrderTotalsRow{
+		{CustomerID: 1, Lifecycle: string(StatusFulfilled), Currency: "GBP", OrderCount: 3, LifetimeValueMinor: 30000, LastOrderAt: at(10)},
+		{CustomerID: 2, Lifecycle: string(StatusFulfilled), Currency: "GBP", OrderCount: 1, LifetimeValueMinor: 2550, LastOrderAt: at(12)},
+		{CustomerID: 3, Lifecycle: string(StatusFulfilled), Currency: "EUR", OrderCount: 1, LifetimeValueMinor: 1000, LastOrderAt: at(1)},
+		{CustomerID: 4, Lifecycle: string(StatusReadyToFulfil), Currency: "GBP", OrderCount: 2, LifetimeValueMinor: 4000, LastOrderAt: at(14)},
+		{CustomerID: 5, Lifecycle: string(StatusFulfilled), Currency: "GBP", OrderCount: 1, LifetimeValueMinor: 1800, LastOrderAt: at(2)},
+		// Cancelled, refunded and unpaid orders aren't sales
+		{CustomerID: 1, Lifecycle: string(StatusCancelled), Currency: "GBP", OrderCount: 2, LifetimeValueMinor: 9900, LastOrderAt: at(20)},
+		{CustomerID: 2, Lifecycle: string(StatusRefunded), Currency: "GBP", OrderCount: 1, LifetimeValueMinor: 1500, LastOrderAt: at(21)},
+		{CustomerID: 7, Lifecycle: string(StatusAwaitingPayment), Currency: "GBP", OrderCount: 1, LifetimeValueMinor: 700, LastOrderAt: at(22)},
+		// Grind & Gather's only order has an unreadable date
+		{CustomerID: 6, Lifecycle: string(StatusFulfilled), Currency: "GBP", OrderCount: 1, LifetimeValueMinor: 1200},
+	}
+
+	got, err := buildUnifiedCustomers(customers, addresses, totals)
+	if err != nil {
+		t.Fatalf("buildUnifiedCustomers: %v", err)
+	}
+
+	type summary struct {
+		ID       int64
+		Name     string
+		Records  []int64
+		Channels []string
+		Orders   int64
+		Value    string
+		Last     time.Time
+	}
+	var summaries []summary
+	for _, c := range got {
+		var ids []int64
+		for _, r := range c.Records {
+			ids = append(ids, r.ID)
+		}
+		summaries = append(summaries, summary{c.ID, c.Name, ids, c.Channels, c.OrderCount, c.LifetimeValue(), c.LastOrderAt})
+	}
+
+	want := []summary{
+		{4, "Jamie Lee", []int64{4, 5}, []string{WooCommerce}, 3, "£58.00", day(14)},
+		{1, "Bean There Cafe Ltd", []int64{1, 2, 3}, []string{Orderspace, WooCommerce}, 5, "€10.00 + £325.50", day(12)},
+		{7, "Co", []int64{7}, []string{WooCommerce}, 0, "—", time.Time{}},
+		{6, "Grind & Gather", []int64{6}, []string{Orderspace}, 1, "£12.00", time.Time{}},
+	}
+	if len(summaries) != len(want) {
+		t.Fatalf("got %d customers %+v, want %d", len(summaries), summaries, len(want))
+	}
+	for i := range want {
+		g, w := summaries[i], want[i]
+		if g.ID != w.ID || g.Name != w.Name || !slices.Equal(g.Records, w.Records) || !slices.Equal(g.Channels, w.Channels) ||
+			g.Orders != w.Orders || g.Value != w.Value || !g.Last.Equal(w.Last) {
+			t.Errorf("customer %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestCompanyKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Bean There Cafe Ltd.", "company:beantherecafe"},
+		{"BEAN THERE CAFE", "company:beantherecafe"},
+		{"Grind & Gather Co", "company:grindgather"},
+		{"Co", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := companyKey(tt.name); got != tt.want {
+			t.Errorf("companyKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+)
+
+// Fulfilment marks an order, or part of an Orderspace order, as shipped
+type Fulfilment struct {
+	// Lines are the Orderspace lines and quantities sent. Empty sends everything
+	// outstanding that isn't on hold. WooCommerce orders are always sent whole.
+	Lines          []FulfilmentLine
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+	FulfilledBy    string // who packed and sent it
+}
+
+// FulfilmentLine is a quantity of one order line, identified by LineItem.ID
+type FulfilmentLine struct {
+	LineID   string `json:"line_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// FulfilmentRecord is a fulfilment made from this app, kept as a log of who
+// sent what and when
+type FulfilmentRecord struct {
+	ID             int64            `json:"id"`
+	Origin         string           `json:"origin"`
+	OrderID        string           `json:"order_id"`
+	DispatchID     string           `json:"dispatch_id,omitempty"` // the Orderspace dispatch created
+	Lines          []FulfilmentLine `json:"lines"`
+	Carrier        string           `json:"carrier,omitempty"`
+	TrackingNumber string           `json:"tracking_number,omitempty"`
+	TrackingURL    string           `json:"tracking_url,omitempty"`
+	FulfilledBy    string           `json:"fulfilled_by"`
+	FulfilledAt    time.Time        `json:"fulfilled_at"`
+}
+
+// FulfilmentError explains why an order can't be fulfilled as asked, naming
+// the part of the request at fault
+type FulfilmentError struct {
+	Field   string
+	Problem string
+}
+
+func (e *FulfilmentError) Error() string {
+	return fmt.Sprintf("invalid fulfilment: %s %s", e.Field, e.Problem)
+}
+
+// Fulfil marks an order shipped in its channel and records who did it. An
+// Orderspace order gets a dispatch for the chosen lines; a WooCommerce order is
+// completed, with any tracking details added as a note the customer is sent.
+// Orders awaiting payment can't be fulfilled until they are paid. The stored
+// copy of the order is refreshed so its status shows straight away.
+func (s *OrderService) Fulfil(ctx context.Context, origin, id string, f Fulfilment) (*FulfilmentRecord, error) {
+	f.FulfilledBy = strings.TrimSpace(f.FulfilledBy)
+	f.Carrier = strings.TrimSpace(f.Carrier)
+	f.TrackingNumber = strings.TrimSpace(f.TrackingNumber)
+	f.TrackingURL = strings.TrimSpace(f.TrackingURL)
+	if f.FulfilledBy == "" {
+		return nil, &FulfilmentError{Field: "fulfilled_by", Problem: "is required"}
+	}
+
+	var record FulfilmentRecord
+	var err error
+	switch origin {
+	case Orderspace:
+		record, err = s.fulfilOrderspace(ctx, id, f)
+	case WooCommerce:
+		record, err = s.fulfilWoo(ctx, id, f)
+	default:
+		return nil, fmt.Errorf("%w: origin %q", ErrInvalidOrderRef, origin)
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.Carrier, record.TrackingNumber, record.TrackingURL = f.Carrier, f.TrackingNumber, f.TrackingURL
+	record.FulfilledBy = f.FulfilledBy
+
+	lines, err := json.Marshal(record.Lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fulfilment lines: %w", err)
+	}
+	row, err := s.Queries.InsertFulfilment(ctx, db.InsertFulfilmentParams{
+		Origin:         origin,
+		ExternalID:     id,
+		DispatchID:     record.DispatchID,
+		Lines:          lines,
+		Carrier:        f.Carrier,
+		TrackingNumber: f.TrackingNumber,
+		TrackingUrl:    f.TrackingURL,
+		FulfilledBy:    f.FulfilledBy,
+	})
+	if err != nil {
+		// The channel has already been updated, so don't report the fulfilment as failed
+		slog.Error("Failed to record fulfilment", "origin", origin, "orderID", id, "fulfilled_by", f.FulfilledBy, "error_message", err)
+		record.FulfilledAt = s.Now()
+		return &record, nil
+	}
+	stored, err := s.fulfilmentRecord(row)
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Fulfilments returns the fulfilments made from this app for an order, newest first
+func (s *OrderService) Fulfilments(ctx context.Context, origin, id string) ([]FulfilmentRecord, error) {
+	rows, err := s.Queries.ListOrderFulfilments(ctx, db.ListOrderFulfilmentsParams{Origin: origin, ExternalID: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fulfilments of %s order %s: %w", origin, id, err)
+	}
+	records := make([]FulfilmentRecord, 0, len(rows))
+	for _, row := range rows {
+		record, err := s.fulfilmentRecord(row)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// fulfilOrderspace creates a dispatch for the chosen lines of an Orderspace order
+func (s *OrderService) fulfilOrderspace(ctx context.Context, id string, f Fulfilment) (FulfilmentRecord, error) {
+	order, err := s.OrderspaceClient.GetOrder(ctx, id)
+	if err != nil {
+		return FulfilmentRecord{}, fmt.Errorf("failed to get orderspace order %s: %w", id, err)
+	}
+	if status := OrderspaceStatus(*order); !status.ToFulfil() {
+		return FulfilmentRecord{}, closedOrderError(status)
+	}
+	lines, err := dispatchLines(order.OrderLines, f.Lines)
+	if err != nil {
+		return FulfilmentRecord{}, err
+	}
+
+	dispatch, err := s.OrderspaceClient.CreateDispatch(ctx, &orderspace.Dispatch{
+		OrderID:        id,
+		Courier:        f.Carrier,
+		TrackingNumber: f.TrackingNumber,
+		Note:           "Dispatched by " + f.FulfilledBy,
+		DispatchLines:  lines,
+	})
+	if err != nil {
+		return FulfilmentRecord{}, fmt.Errorf("failed to create dispatch for orderspace order %s: %w", id, err)
+	}
+
+	if updated, err := s.OrderspaceClient.GetOrder(ctx, id); err != nil {
+		slog.Warn("Failed to refresh fulfilled order", "origin", Orderspace, "orderID", id, "error_message", err)
+	} else if err := s.SaveOrderspaceOrder(ctx, *updated); err != nil {
+		slog.Warn("Failed to save fulfilled order", "origin", Orderspace, "orderID", id, "error_message", err)
+	}
+
+	record := FulfilmentRecord{Origin: Orderspace, OrderID: id, DispatchID: dispatch.ID}
+	for _, l := range lines {
+		record.Lines = append(record.Lines, FulfilmentLine{LineID: l.OrderLineID, Quantity: l.Quantity})
+	}
+	return record, nil
+}
+
+// fulfilWoo completes a WooCommerce order, then adds any tracking details as a
+// customer note. The note is only sent once the order is completed, so a
+// failed update can be retried without emailing the customer again.
+func (s *OrderService) fulfilWoo(ctx context.Context, id string, f Fulfilment) (FulfilmentRecord, error) {
+	orderID, err := strconv.Atoi(id)
+	if err != nil {
+		return FulfilmentRecord{}, fmt.Errorf("%w: woocommerce order ID %q", ErrInvalidOrderRef, id)
+	}
+	if len(f.Lines) > 0 {
+		return FulfilmentRecord{}, &FulfilmentError{Field: "lines", Problem: "can't be chosen for WooCommerce orders, which are sent whole"}
+	}
+	order, err := s.WooClient.GetOrder(ctx, orderID)
+	if err != nil {
+		return FulfilmentRecord{}, fmt.Errorf("failed to get woocommerce order %d: %w", orderID, err)
+	}
+	if status := WooStatus(order.Status); !status.ToFulfil() {
+		return FulfilmentRecord{}, closedOrderError(status)
+	}
+
+	updated, err := s.WooClient.UpdateOrderStatus(ctx, orderID, "completed")
+	if err != nil {
+		return FulfilmentRecord{}, fmt.Errorf("failed to complete woocommerce order %d: %w", orderID, err)
+	}
+	// The order is completed, so the fulfilment stands even if the note can't be added
+	if note := trackingNote(f); note != "" {
+		if _, err := s.WooClient.CreateOrderNote(ctx, orderID, note, true); err != nil {
+			slog.Error("Failed to add tracking note", "origin", WooCommerce, "orderID", id, "error_message", err)
+		}
+	}
+	if err := s.SaveWooOrder(ctx, *updated); err != nil {
+		slog.Warn("Failed to save fulfilled order", "origin", WooCommerce, "orderID", id, "error_message", err)
+	}
+
+	record := FulfilmentRecord{Origin: WooCommerce, OrderID: id}
+	for _, line := range order.LineItems {
+		record.Lines = append(record.Lines, FulfilmentLine{LineID: strconv.Itoa(line.ID), Quantity: line.Quantity})
+	}
+	return record, nil
+}
+
+// closedOrderError refuses to fulfil an order that no longer has anything to
+// send, or can't be sent until it is paid
+func closedOrderError(status Status) *FulfilmentError {
+	switch status {
+	case StatusUnknown:
+		return &FulfilmentError{Field: "order", Problem: "has a status this app doesn't recognise"}
+	case StatusAwaitingPayment:
+		return &FulfilmentError{Field: "order", Problem: "is awaiting payment"}
+	}
+	return &FulfilmentError{Field: "order", Problem: "is already " + strings.ToLower(status.Label())}
+}
+
+// dispatchLines checks the chosen quantities against what is outstanding on
+// each line. With nothing chosen, every outstanding line not on hold is sent.
+// Lines chosen with a zero quantity are skipped, so a form can send them all.
+func dispatchLines(lines []orderspace.OrderLine, chosen []FulfilmentLine) ([]orderspace.DispatchLine, error) {
+	outstanding := make(map[string]int, len(lines))
+	var all []orderspace.DispatchLine
+	for _, line := range lines {
+		if line.Shipping {
+			continue
+		}
+		left := max(line.Quantity-line.Dispatched, 0)
+		outstanding[line.ID] = left
+		if left > 0 && !line.OnHold {
+			all = append(all, orderspace.DispatchLine{OrderLineID: line.ID, Quantity: left})
+		}
+	}
+
+	if len(chosen) == 0 {
+		if len(all) == 0 {
+			return nil, &FulfilmentError{Field: "lines", Problem: "has nothing left to send"}
+		}
+		return all, nil
+	}
+
+	var dispatch []orderspace.DispatchLine
+	seen := map[string]bool{}
+	for _, c := range chosen {
+		left, ok := outstanding[c.LineID]
+		switch {
+		case !ok:
+			return nil, &FulfilmentError{Field: "lines", Problem: fmt.Sprintf("include %q, which isn't a product on the order", c.LineID)}
+		case seen[c.LineID]:
+			return nil, &FulfilmentError{Field: "lines", Problem: fmt.Sprintf("include %q more than once", c.LineID)}
+		case c.Quantity < 0:
+			return nil, &FulfilmentError{Field: "lines", Problem: fmt.Sprintf("ask for a negative quantity of %q", c.LineID)}
+		case c.Quantity > left:
+			return nil, &FulfilmentError{Field: "lines", Problem: fmt.Sprintf("ask for %d of %q but only %d are left to send", c.Quantity, c.LineID, left)}
+		}
+		seen[c.LineID] = true
+		if c.Quantity > 0 {
+			dispatch = append(dispatch, orderspace.DispatchLine{OrderLineID: c.LineID, Quantity: c.Quantity})
+		}
+	}
+	if len(dispatch) == 0 {
+		return nil, &FulfilmentError{Field: "lines", Problem: "must send at least one item"}
+	}
+	return dispatch, nil
+}
+
+// trackingNote describes how the order was sent for the customer, or is empty without tracking details
+func trackingNote(f Fulfilment) string {
+	if f.Carrier == "" && f.TrackingNumber == "" && f.TrackingURL == "" {
+		return ""
+	}
+	var parts []string
+	if f.Carrier != "" {
+		parts = append(parts, "Your order has been sent with "+f.Carrier+".")
+	} else {
+		parts = append(parts, "Your order has been sent.")
+	}
+	if f.TrackingNumber != "" {
+		parts = append(parts, "Tracking number: "+f.TrackingNumber+".")
+	}
+	if f.TrackingURL != "" {
+		parts = append(parts, "Track it at "+f.TrackingURL)
+	}
+	return strings.Join(parts, " ")
+}
+
+// fulfilmentRecord converts a stored fulfilment, with its time in the business timezone
+func (s *OrderService) fulfilmentRecord(row db.Fulfilment) (FulfilmentRecord, error) {
+	record := FulfilmentRecord{
+		ID:             row.ID,
+		Origin:         row.Origin,
+		OrderID:        row.ExternalID,
+		DispatchID:     row.DispatchID,
+		Carrier:        row.Carrier,
+		TrackingNumber: row.TrackingNumber,
+		TrackingURL:    row.TrackingUrl,
+		FulfilledBy:    row.FulfilledBy,
+		FulfilledAt:    row.FulfilledAt.In(s.location()),
+	}
+	if err := json.Unmarshal(row.Lines, &record.Lines); err != nil {
+		return FulfilmentRecord{}, fmt.Errorf("failed to unmarshal lines of fulfilment %d: %w", row.ID, err)
+	}
+	return record, nil
+}
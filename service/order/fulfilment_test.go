@@ -0,0 +1,141 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/db/dbtest"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+func TestDispatchLines(t *testing.T) {
+	lines := []orderspace.OrderLine{
+		{ID: "ol_1", Quantity: 6},
+		{ID: "ol_2", Quantity: 20, Dispatched: 10},
+		{ID: "ol_3", Quantity: 2, OnHold: true},
+		{ID: "ol_4", Quantity: 4, Dispatched: 4},
+		{ID: "ol_ship", Quantity: 1, Shipping: true},
+	}
+
+	got, err := dispatchLines(lines, nil)
+	want := []orderspace.DispatchLine{{OrderLineID: "ol_1", Quantity: 6}, {OrderLineID: "ol_2", Quantity: 10}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("dispatchLines(nothing chosen) = %v, %v, want %v", got, err, want)
+	}
+
+	got, err = dispatchLines(lines, []FulfilmentLine{{"ol_1", 0}, {"ol_2", 5}, {"ol_3", 2}})
+	want = []orderspace.DispatchLine{{OrderLineID: "ol_2", Quantity: 5}, {OrderLineID: "ol_3", Quantity: 2}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("dispatchLines(chosen) = %v, %v, want %v", got, err, want)
+	}
+
+	invalid := map[string][]FulfilmentLine{
+		"unknown line":   {{"ol_9", 1}},
+		"shipping line":  {{"ol_ship", 1}},
+		"too many":       {{"ol_2", 11}},
+		"already sent":   {{"ol_4", 1}},
+		"negative":       {{"ol_1", -1}},
+		"duplicate":      {{"ol_1", 1}, {"ol_1", 1}},
+		"nothing chosen": {{"ol_1", 0}},
+	}
+	for name, chosen := range invalid {
+		var fe *FulfilmentError
+		if _, err := dispatchLines(lines, chosen); !errors.As(err, &fe) || fe.Field != "lines" {
+			t.Errorf("%s: error = %v, want a lines FulfilmentError", name, err)
+		}
+	}
+
+	var fe *FulfilmentError
+	if _, err := dispatchLines(lines[3:], nil); !errors.As(err, &fe) {
+		t.Errorf("dispatchLines(all sent) error = %v, want a FulfilmentError", err)
+	}
+}
+
+func TestTrackingNote(t *testing.T) {
+	tests := []struct {
+		f    Fulfilment
+		want string
+	}{
+		{Fulfilment{}, ""},
+		{Fulfilment{Carrier: "Royal Mail", TrackingNumber: "RM123456789GB"}, "Your order has been sent with Royal Mail. Tracking number: RM123456789GB."},
+		{Fulfilment{TrackingURL: "https://track.example/1"}, "Your order has been sent. Track it at https://track.example/1"},
+	}
+	for _, tt := range tests {
+		if got := trackingNote(tt.f); got != tt.want {
+			t.Errorf("trackingNote(%+v) = %q, want %q", tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestFulfilWoo(t *testing.T) {
+	woo := woocommercetest.NewServer(woocommercetest.Orders(), nil, nil, nil)
+	defer woo.Close()
+	// rejecting passes everything through to the fake except order updates
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			http.Error(w, `{"code":"rest_cannot_edit","message":"Sorry, you are not allowed to edit this resource."}`, http.StatusForbidden)
+			return
+		}
+		woo.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer rejecting.Close()
+
+	store := dbtest.New()
+	store.Handle("InsertFulfilment", func(args []any) ([][]any, error) {
+		return [][]any{append(append([]any{int64(1)}, args...), time.Now())}, nil
+	})
+	s := &OrderService{
+		WooClient:  woocommerce.NewClient(rejecting.URL, woocommercetest.ConsumerKey, woocommercetest.ConsumerSecret),
+		TitleCaser: cases.Title(language.English),
+		DB:         store,
+		Queries:    db.New(store),
+	}
+	ctx := context.Background()
+	f := Fulfilment{Carrier: "Royal Mail", TrackingNumber: "RM123456789GB", FulfilledBy: "Sam"}
+
+	// The second fixture is on hold, waiting for its bank transfer
+	unpaid := woocommercetest.Orders()[1]
+	_, err := s.Fulfil(ctx, WooCommerce, strconv.Itoa(unpaid.ID), f)
+	var fulfilErr *FulfilmentError
+	if !errors.As(err, &fulfilErr) || fulfilErr.Field != "order" {
+		t.Errorf("Fulfil(awaiting payment) error = %v, want a FulfilmentError about the order", err)
+	}
+
+	paid := woocommercetest.Orders()[0]
+	id := strconv.Itoa(paid.ID)
+	if _, err := s.Fulfil(ctx, WooCommerce, id, f); err == nil {
+		t.Fatal("Fulfil with the update rejected succeeded, want an error")
+	}
+	if notes := woo.Notes(paid.ID); len(notes) != 0 {
+		t.Errorf("notes after a failed update = %+v, want the customer not told yet", notes)
+	}
+
+	s.WooClient = woo.NewClient()
+	record, err := s.Fulfil(ctx, WooCommerce, id, f)
+	if err != nil {
+		t.Fatalf("Fulfil retry: %v", err)
+	}
+	if len(record.Lines) != len(paid.LineItems) {
+		t.Errorf("fulfilled %d lines, want all %d", len(record.Lines), len(paid.LineItems))
+	}
+	if notes := woo.Notes(paid.ID); len(notes) != 1 || !notes[0].CustomerNote {
+		t.Errorf("notes after the retry = %+v, want one customer note", notes)
+	}
+	if _, err := s.Fulfil(ctx, WooCommerce, id, f); !errors.As(err, &fulfilErr) {
+		t.Errorf("Fulfil(completed) error = %v, want a FulfilmentError", err)
+	}
+	if notes := woo.Notes(paid.ID); len(notes) != 1 {
+		t.Errorf("%d notes after fulfilling twice, want the customer told once", len(notes))
+	}
+}
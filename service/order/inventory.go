@@ -0,0 +1,321 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Inventory movement kinds. The stock level of a SKU is the sum of its
+// movements, so a stock take is recorded as the difference between the count
+// and the level at the time.
+const (
+	MovementOrder      = "order"
+	MovementAdjustment = "adjustment"
+	MovementStockTake  = "stocktake"
+)
+
+// ErrSKUNotTracked is returned when adjusting stock of a SKU that isn't in the inventory
+var ErrSKUNotTracked = errors.New("sku is not tracked")
+
+// StockLevel is the current stock of a tracked SKU
+type StockLevel struct {
+	SKU            string
+	Name           string
+	Quantity       int
+	TrackedSince   time.Time // orders placed before this don't count against stock
+	PushedQuantity *int      // quantity last sent to the channels, nil if never pushed
+	PushedAt       time.Time
+}
+
+// Pending reports whether the level has changed since it was last pushed to the channels
+func (l StockLevel) Pending() bool {
+	return l.PushedQuantity == nil || *l.PushedQuantity != l.Quantity
+}
+
+// NormalizeSKU upper-cases a SKU and trims surrounding space, matching the catalogue's SKU keys
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// TrackSKU adds a SKU to the inventory at zero stock, or renames it if already
+// tracked. Orders placed from now on count against it.
+func (s *OrderService) TrackSKU(ctx context.Context, sku, name string) (*StockLevel, error) {
+	sku = NormalizeSKU(sku)
+	if sku == "" {
+		return nil, fmt.Errorf("sku is required")
+	}
+	if _, err := s.Queries.UpsertInventoryItem(ctx, db.UpsertInventoryItemParams{Sku: sku, Name: strings.TrimSpace(name)}); err != nil {
+		return nil, fmt.Errorf("failed to track sku %s: %w", sku, err)
+	}
+	return s.StockLevel(ctx, sku)
+}
+
+// StockLevel returns the current stock of a tracked SKU
+func (s *OrderService) StockLevel(ctx context.Context, sku string) (*StockLevel, error) {
+	sku = NormalizeSKU(sku)
+	row, err := s.Queries.GetInventoryLevel(ctx, sku)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSKUNotTracked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock level of %s: %w", sku, err)
+	}
+	level := stockLevel(db.ListInventoryLevelsRow(row))
+	return &level, nil
+}
+
+// InventoryLevels returns the stock of every tracked SKU, ordered by SKU
+func (s *OrderService) InventoryLevels(ctx context.Context) ([]StockLevel, error) {
+	rows, err := s.Queries.ListInventoryLevels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inventory levels: %w", err)
+	}
+	levels := make([]StockLevel, 0, len(rows))
+	for _, row := range rows {
+		levels = append(levels, stockLevel(row))
+	}
+	return levels, nil
+}
+
+// AdjustStock adds delta, which may be negative, to a SKU's stock, e.g. for
+// a delivery from the roastery or a damaged bag
+func (s *OrderService) AdjustStock(ctx context.Context, sku string, delta int, note string) (*StockLevel, error) {
+	sku = NormalizeSKU(sku)
+	if _, err := s.StockLevel(ctx, sku); err != nil {
+		return nil, err
+	}
+	_, err := s.Queries.InsertInventoryMovement(ctx, db.InsertInventoryMovementParams{
+		Sku:      sku,
+		Kind:     MovementAdjustment,
+		Quantity: int32(delta),
+		Note:     strings.TrimSpace(note),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust stock of %s: %w", sku, err)
+	}
+	return s.StockLevel(ctx, sku)
+}
+
+// StockTake sets a SKU's stock to the counted quantity. Orders placed before
+// the count but synced after it still count against stock.
+func (s *OrderService) StockTake(ctx context.Context, sku string, counted int, note string) (*StockLevel, error) {
+	sku = NormalizeSKU(sku)
+	err := s.withTx(ctx, func(q *db.Queries) error {
+		row, err := q.GetInventoryLevel(ctx, sku)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSKUNotTracked
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get stock level of %s: %w", sku, err)
+		}
+		// The difference is recorded even when zero so the count shows in the history
+		_, err = q.InsertInventoryMovement(ctx, db.InsertInventoryMovementParams{
+			Sku:      sku,
+			Kind:     MovementStockTake,
+			Quantity: int32(counted) - row.Quantity,
+			Note:     strings.TrimSpace(note),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record stock take of %s: %w", sku, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.StockLevel(ctx, sku)
+}
+
+// PushPendingStock sends the level of every SKU that changed since its last
+// push to each stored channel listing with that SKU: the WooCommerce product or
+// variation and the Orderspace variant. It returns how many SKUs were pushed.
+// A SKU no channel lists is logged and cleared, as there is nowhere to send
+// it, until its level next changes; a failed push is retried on the next call.
+func (s *OrderService) PushPendingStock(ctx context.Context) (int, error) {
+	levels, err := s.InventoryLevels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	levels = slices.DeleteFunc(levels, func(l StockLevel) bool { return !l.Pending() })
+	if len(levels) == 0 {
+		return 0, nil
+	}
+
+	skus := make([]string, 0, len(levels))
+	for _, level := range levels {
+		skus = append(skus, level.SKU)
+	}
+	rows, err := s.Queries.ListProductListingsBySKUs(ctx, skus)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list product listings: %w", err)
+	}
+	listings := make(map[string][]ProductListing, len(levels))
+	for _, row := range rows {
+		sku := NormalizeSKU(row.Sku)
+		listings[sku] = append(listings[sku], storedListing(row))
+	}
+
+	pushed := 0
+	var errs []error
+	for _, level := range levels {
+		targets := listings[level.SKU]
+		if len(targets) == 0 {
+			slog.Warn("Not pushing stock of a SKU no channel lists", "sku", level.SKU, "quantity", level.Quantity)
+		} else if err := s.pushStock(ctx, targets, level.Quantity); err != nil {
+			errs = append(errs, fmt.Errorf("failed to push stock of %s: %w", level.SKU, err))
+			continue
+		}
+		err := s.Queries.MarkInventoryPushed(ctx, db.MarkInventoryPushedParams{
+			Sku:            level.SKU,
+			PushedQuantity: pgtype.Int4{Int32: int32(level.Quantity), Valid: true},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to mark %s pushed: %w", level.SKU, err))
+			continue
+		}
+		if len(targets) > 0 {
+			pushed++
+		}
+	}
+	return pushed, errors.Join(errs...)
+}
+
+// pushStock sets the stock quantity of every listing through its channel's API
+func (s *OrderService) pushStock(ctx context.Context, listings []ProductListing, quantity int) error {
+	for _, l := range listings {
+		switch l.Origin {
+		case Orderspace:
+			if _, err := s.OrderspaceClient.SetVariantStock(ctx, l.VariantID, quantity); err != nil {
+				return fmt.Errorf("orderspace variant %s: %w", l.VariantID, err)
+			}
+		case WooCommerce:
+			productID, err := strconv.Atoi(l.ProductID)
+			if err != nil {
+				return fmt.Errorf("invalid woocommerce product ID %q: %w", l.ProductID, err)
+			}
+			if l.VariantID == "" {
+				if _, err := s.WooClient.SetProductStock(ctx, productID, quantity); err != nil {
+					return fmt.Errorf("woocommerce product %d: %w", productID, err)
+				}
+				continue
+			}
+			variationID, err := strconv.Atoi(l.VariantID)
+			if err != nil {
+				return fmt.Errorf("invalid woocommerce variation ID %q: %w", l.VariantID, err)
+			}
+			if _, err := s.WooClient.SetVariationStock(ctx, productID, variationID, quantity); err != nil {
+				return fmt.Errorf("woocommerce variation %d: %w", variationID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// stockLine is the part of an order line that matters to the inventory
+type stockLine struct {
+	SKU      string
+	Quantity int
+}
+
+// stockUsage totals the quantity ordered of each SKU, ignoring lines without one
+func stockUsage(lines []stockLine) map[string]int {
+	usage := map[string]int{}
+	for _, line := range lines {
+		sku := NormalizeSKU(line.SKU)
+		if sku == "" || line.Quantity <= 0 {
+			continue
+		}
+		usage[sku] += line.Quantity
+	}
+	return usage
+}
+
+// orderspaceStockLines returns the product lines of an order, leaving out shipping charges
+func orderspaceStockLines(order orderspace.Order) []stockLine {
+	var lines []stockLine
+	for _, line := range order.OrderLines {
+		if line.Shipping {
+			continue
+		}
+		lines = append(lines, stockLine{SKU: line.SKU, Quantity: line.Quantity})
+	}
+	return lines
+}
+
+func wooStockLines(order woocommerce.Order) []stockLine {
+	lines := make([]stockLine, 0, len(order.LineItems))
+	for _, line := range order.LineItems {
+		lines = append(lines, stockLine{SKU: line.SKU, Quantity: line.Quantity})
+	}
+	return lines
+}
+
+// consumesStock reports whether an order in a channel's status holds stock
+func consumesStock(origin, status string) bool {
+	return StatusFor(origin, status).HoldsStock()
+}
+
+// recordOrderStock replaces an order's inventory movements with one per
+// tracked SKU it uses. It runs on every save, so status changes and edited
+// quantities correct the stock level. Orders placed before a SKU was tracked
+// are ignored, since a starting stock take already accounts for them; orders
+// whose placed date is unknown are counted.
+func recordOrderStock(ctx context.Context, q *db.Queries, orderID int64, placedAt pgtype.Timestamptz, consumes bool, lines []stockLine) error {
+	if err := q.DeleteOrderMovements(ctx, pgtype.Int8{Int64: orderID, Valid: true}); err != nil {
+		return fmt.Errorf("failed to clear inventory movements for order %d: %w", orderID, err)
+	}
+	usage := stockUsage(lines)
+	if !consumes || len(usage) == 0 {
+		return nil
+	}
+
+	items, err := q.ListInventoryItemsBySKUs(ctx, slices.Sorted(maps.Keys(usage)))
+	if err != nil {
+		return fmt.Errorf("failed to list inventory items for order %d: %w", orderID, err)
+	}
+	for _, item := range items {
+		if placedAt.Valid && placedAt.Time.Before(item.TrackedSince) {
+			continue
+		}
+		_, err := q.InsertInventoryMovement(ctx, db.InsertInventoryMovementParams{
+			Sku:      item.Sku,
+			Kind:     MovementOrder,
+			Quantity: -int32(usage[item.Sku]),
+			OrderID:  pgtype.Int8{Int64: orderID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record %s inventory movement for order %d: %w", item.Sku, orderID, err)
+		}
+	}
+	return nil
+}
+
+func stockLevel(row db.ListInventoryLevelsRow) StockLevel {
+	level := StockLevel{
+		SKU:          row.Sku,
+		Name:         row.Name,
+		Quantity:     int(row.Quantity),
+		TrackedSince: row.TrackedSince,
+	}
+	if row.PushedQuantity.Valid {
+		pushed := int(row.PushedQuantity.Int32)
+		level.PushedQuantity = &pushed
+	}
+	if row.PushedAt.Valid {
+		level.PushedAt = row.PushedAt.Time
+	}
+	return level
+}
@@ -0,0 +1,168 @@
+package order
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestStockUsage(t *testing.T) {
+	orderspaceOrder := orderspace.Order{
+		OrderLines: []orderspace.OrderLine{
+			{SKU: "ESP-250-WB", Quantity: 6},
+			{SKU: " esp-250-wb ", Quantity: 2},
+			{SKU: "FLT-250-FL", Quantity: 3},
+			{Name: "Delivery", Quantity: 1, Shipping: true},
+			{SKU: "SHIP-NEXT-DAY", Quantity: 1, Shipping: true},
+		},
+	}
+	got := stockUsage(orderspaceStockLines(orderspaceOrder))
+	if want := map[string]int{"ESP-250-WB": 8, "FLT-250-FL": 3}; !maps.Equal(got, want) {
+		t.Errorf("orderspace usage = %v, want %v", got, want)
+	}
+
+	wooOrder := woocommerce.Order{
+		LineItems: []woocommerce.OrderLineItem{
+			{SKU: "ESP-1KG-WB", Quantity: 1},
+			{SKU: "", Quantity: 4},
+			{SKU: "ETH-250-WB", Quantity: 0},
+		},
+	}
+	got = stockUsage(wooStockLines(wooOrder))
+	if want := map[string]int{"ESP-1KG-WB": 1}; !maps.Equal(got, want) {
+		t.Errorf("woocommerce usage = %v, want %v", got, want)
+	}
+}
+
+func TestConsumesStock(t *testing.T) {
+	tests := []struct {
+		origin, status string
+		want           bool
+	}{
+		{Orderspace, "new", true},
+		{Orderspace, "fulfilled", true},
+		{Orderspace, "cancelled", false},
+		{Orderspace, "refunded", false},
+		{WooCommerce, "processing", true},
+		{WooCommerce, "on-hold", true},
+		{WooCommerce, "failed", false},
+		{WooCommerce, "refunded", false},
+		{WooCommerce, "trash", false},
+		{WooCommerce, "mystery", false},
+		{"shopify", "new", false},
+	}
+	for _, tt := range tests {
+		if got := consumesStock(tt.origin, tt.status); got != tt.want {
+			t.Errorf("consumesStock(%q, %q) = %v, want %v", tt.origin, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestStockLevelPending(t *testing.T) {
+	ten := 10
+	if !(StockLevel{Quantity: 10}).Pending() {
+		t.Error("never pushed level is not pending")
+	}
+	if (StockLevel{Quantity: 10, PushedQuantity: &ten}).Pending() {
+		t.Error("level equal to the pushed quantity is pending")
+	}
+	if !(StockLevel{Quantity: 9, PushedQuantity: &ten}).Pending() {
+		t.Error("changed level is not pending")
+	}
+}
+
+func TestPushStock(t *testing.T) {
+	osrv := orderspacetest.NewServer(orderspacetest.Orders(), orderspacetest.Customers(), orderspacetest.Products())
+	t.Cleanup(osrv.Close)
+	woo := woocommercetest.NewServer(woocommercetest.Orders(), woocommercetest.Customers(), woocommercetest.Products(), woocommercetest.Variations())
+	t.Cleanup(woo.Close)
+	s := &OrderService{OrderspaceClient: osrv.NewClient(), WooClient: woo.NewClient()}
+	ctx := context.Background()
+
+	catalogue, err := s.ProductCatalogue(ctx)
+	if err != nil {
+		t.Fatalf("ProductCatalogue: %v", err)
+	}
+	for _, p := range catalogue.Products {
+		if p.SKU != "ESP-1KG-WB" && p.SKU != "ESP-250-WB" {
+			continue
+		}
+		if err := s.pushStock(ctx, p.Listings, 7); err != nil {
+			t.Fatalf("pushStock(%s): %v", p.SKU, err)
+		}
+	}
+
+	product, err := s.OrderspaceClient.GetProduct(ctx, "pr_4Ka9Tm2x")
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	for _, v := range product.Variants {
+		if v.StockLevel == nil || *v.StockLevel != 7 {
+			t.Errorf("orderspace %s stock = %v, want 7", v.SKU, v.StockLevel)
+		}
+	}
+
+	simple, err := s.WooClient.GetProduct(ctx, 44)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if simple.StockQuantity == nil || *simple.StockQuantity != 7 {
+		t.Errorf("woocommerce ESP-250-WB stock = %v, want 7", simple.StockQuantity)
+	}
+	for v, err := range s.WooClient.AllVariations(ctx, 47, nil) {
+		if err != nil {
+			t.Fatalf("AllVariations: %v", err)
+		}
+		want := 7
+		if v.SKU == "ESP-1KG-ES" {
+			want = 0 // not pushed, still the fixture's level
+		}
+		if v.StockQuantity == nil || *v.StockQuantity != want {
+			t.Errorf("woocommerce %s stock = %v, want %d", v.SKU, v.StockQuantity, want)
+		}
+	}
+}
+
+func TestPushPendingStock(t *testing.T) {
+	s, woo := catalogueService(t)
+	store := syncedListings(t, s)
+	store.Handle("ListInventoryLevels", func(args []any) ([][]any, error) {
+		return [][]any{
+			{"ESP-250-WB", "House Espresso 250g", time.Now(), nil, nil, int32(5)},
+			{"ESP-1KG-WB", "House Espresso 1kg", time.Now(), pgtype.Int4{Int32: 9, Valid: true}, nil, int32(9)},
+			{"GIFT-CARD", "Gift card", time.Now(), nil, nil, int32(3)},
+		}, nil
+	})
+	ctx := context.Background()
+
+	n, err := s.PushPendingStock(ctx)
+	if err != nil {
+		t.Fatalf("PushPendingStock: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("pushed %d SKUs, want only the listed one", n)
+	}
+	simple, err := woo.NewClient().GetProduct(ctx, 44)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if simple.StockQuantity == nil || *simple.StockQuantity != 5 {
+		t.Errorf("woocommerce ESP-250-WB stock = %v, want 5", simple.StockQuantity)
+	}
+	// The unlisted SKU is cleared too, so it isn't looked up again every sync
+	var marked []string
+	for _, c := range store.Calls("MarkInventoryPushed") {
+		marked = append(marked, c.Args[0].(string))
+	}
+	if want := []string{"ESP-250-WB", "GIFT-CARD"}; !slices.Equal(marked, want) {
+		t.Errorf("marked %v pushed, want %v", marked, want)
+	}
+}
@@ -0,0 +1,95 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
+)
+
+// SyncProductListings refreshes the stored listings of both channels from
+// their APIs and removes listings the channels no longer have, returning how
+// many were stored. Each channel is stored on its own, so one failing doesn't
+// hold up the other.
+func (s *OrderService) SyncProductListings(ctx context.Context) (int, error) {
+	stored := 0
+	var errs []error
+	for _, origin := range []string{Orderspace, WooCommerce} {
+		n, err := s.syncProductListings(ctx, origin)
+		stored += n
+		errs = append(errs, err)
+	}
+	return stored, errors.Join(errs...)
+}
+
+// syncProductListings replaces the stored listings of one channel
+func (s *OrderService) syncProductListings(ctx context.Context, origin string) (int, error) {
+	var listings []ProductListing
+	var err error
+	switch origin {
+	case Orderspace:
+		listings, err = s.orderspaceListings(ctx)
+	case WooCommerce:
+		listings, err = s.wooListings(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	syncedAt := time.Now().UTC()
+	err = s.withTx(ctx, func(q *db.Queries) error {
+		for _, l := range listings {
+			err := q.UpsertProductListing(ctx, db.UpsertProductListingParams{
+				Origin:     origin,
+				ProductID:  l.ProductID,
+				VariantID:  l.VariantID,
+				Sku:        l.SKU,
+				Name:       l.Name,
+				PriceMinor: l.Price.Amount,
+				Currency:   l.Currency,
+				Active:     l.Active,
+				Orderable:  orderable(l),
+				SyncedAt:   syncedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to store %s listing %s/%s: %w", origin, l.ProductID, l.VariantID, err)
+			}
+		}
+		err := q.DeleteProductListingsSyncedBefore(ctx, db.DeleteProductListingsSyncedBeforeParams{Origin: origin, SyncedAt: syncedAt})
+		if err != nil {
+			return fmt.Errorf("failed to remove old %s listings: %w", origin, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(listings), nil
+}
+
+// orderable reports whether a listing can go on a new order. Orderspace order
+// lines are matched by SKU, so its variants need one.
+func orderable(l ProductListing) bool {
+	return l.Active && (l.Origin != Orderspace || l.SKU != "")
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, so a search matches them literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// storedListing converts a stored listing
+func storedListing(row db.ProductListing) ProductListing {
+	return ProductListing{
+		Origin:    row.Origin,
+		ProductID: row.ProductID,
+		VariantID: row.VariantID,
+		SKU:       row.Sku,
+		Name:      row.Name,
+		Price:     money.New(row.PriceMinor, row.Currency),
+		Currency:  row.Currency,
+		Active:    row.Active,
+	}
+}
@@ -0,0 +1,26 @@
+package order
+
+import "testing"
+
+func TestSyncProductListings(t *testing.T) {
+	s, _ := catalogueService(t)
+	store := syncedListings(t, s)
+
+	orderable := map[string]bool{}
+	for _, c := range store.Calls("UpsertProductListing") {
+		orderable[c.Args[0].(string)+" "+c.Args[3].(string)] = c.Args[8].(bool)
+	}
+	// The decaf is inactive, so it is stored but can't be ordered
+	for key, want := range map[string]bool{"orderspace ESP-1KG-WB": true, "orderspace DEC-1KG-WB": false} {
+		if got, ok := orderable[key]; !ok || got != want {
+			t.Errorf("%s stored %v orderable %v, want orderable %v", key, ok, got, want)
+		}
+	}
+	// Listings the channels no longer have are cleared out
+	if deletes := store.Calls("DeleteProductListingsSyncedBefore"); len(deletes) != 2 {
+		t.Errorf("cleared old listings %d times, want once per channel", len(deletes))
+	}
+	if store.Commits() != 2 {
+		t.Errorf("committed %d times, want once per channel", store.Commits())
+	}
+}
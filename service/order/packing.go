@@ -0,0 +1,435 @@
+package order
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/pdf"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidOrderRef is returned for an unknown origin or a malformed order ID
+var ErrInvalidOrderRef = errors.New("invalid order reference")
+
+// ErrOrderNotStored is returned when an order hasn't been synced into the database
+var ErrOrderNotStored = errors.New("order not stored")
+
+// PackingLine is a product to pack and how many of it
+type PackingLine struct {
+	SKU      string `json:"sku"`
+	Name     string `json:"name"`
+	Options  string `json:"options"`
+	Quantity int    `json:"quantity"`
+}
+
+// PackingSlip is the paperwork that goes in the box with an order
+type PackingSlip struct {
+	Order          Order         `json:"order"`
+	Reference      string        `json:"reference"` // the customer's purchase order number or reference
+	ShipTo         []string      `json:"ship_to"`   // shipping address, one line per part
+	Phone          string        `json:"phone"`
+	ShippingMethod string        `json:"shipping_method"`
+	CustomerNote   string        `json:"customer_note"`
+	Lines          []PackingLine `json:"lines"`
+}
+
+// Quantity returns the number of items to pack
+func (p PackingSlip) Quantity() int {
+	return sumQuantities(p.Lines)
+}
+
+// PickItem is a product to pick for a batch of orders, and which orders it's for
+type PickItem struct {
+	PackingLine
+	Orders []int `json:"orders"` // order numbers
+}
+
+// PickList totals the products to pick for a batch of orders
+type PickList struct {
+	Slips []PackingSlip `json:"slips"`
+	Items []PickItem    `json:"items"` // sorted by SKU, with lines that have no SKU last
+}
+
+// Quantity returns the number of items to pick
+func (l PickList) Quantity() int {
+	total := 0
+	for _, item := range l.Items {
+		total += item.Quantity
+	}
+	return total
+}
+
+// PackingSlip fetches an order from its channel and builds its packing slip
+func (s *OrderService) PackingSlip(ctx context.Context, origin, id string) (*PackingSlip, error) {
+	order, err := s.fetchOrder(ctx, origin, id)
+	if err != nil {
+		return nil, err
+	}
+	slip := NewPackingSlip(*order)
+	return &slip, nil
+}
+
+// StoredPackingSlip builds the packing slip of an order from its synced copy,
+// so batches of slips don't wait on the channels' rate limits
+func (s *OrderService) StoredPackingSlip(ctx context.Context, origin, id string) (*PackingSlip, error) {
+	if origin != Orderspace && origin != WooCommerce {
+		return nil, fmt.Errorf("%w: origin %q", ErrInvalidOrderRef, origin)
+	}
+	row, err := s.Queries.GetOrderByExternalID(ctx, db.GetOrderByExternalIDParams{Origin: origin, ExternalID: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s order %s", ErrOrderNotStored, origin, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored %s order %s: %w", origin, id, err)
+	}
+	order, err := s.convertStoredOrder(row)
+	if err != nil {
+		return nil, err
+	}
+	slip := NewPackingSlip(order)
+	return &slip, nil
+}
+
+// fetchOrder gets an order straight from its channel, bypassing the stored copy
+func (s *OrderService) fetchOrder(ctx context.Context, origin, id string) (*Order, error) {
+	switch origin {
+	case Orderspace:
+		order, err := s.OrderspaceClient.GetOrder(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get orderspace order %s: %w", id, err)
+		}
+		converted, err := s.ConvertOrderspaceOrder(*order)
+		if err != nil {
+			return nil, err
+		}
+		return &converted, nil
+	case WooCommerce:
+		orderID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: woocommerce order ID %q", ErrInvalidOrderRef, id)
+		}
+		order, err := s.WooClient.GetOrder(ctx, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get woocommerce order %d: %w", orderID, err)
+		}
+		converted, err := s.ConvertWooOrder(*order)
+		if err != nil {
+			return nil, err
+		}
+		return &converted, nil
+	}
+	return nil, fmt.Errorf("%w: origin %q", ErrInvalidOrderRef, origin)
+}
+
+// OrderspacePackingSlip builds the packing slip of an Orderspace order
+func (s *OrderService) OrderspacePackingSlip(order orderspace.Order) (PackingSlip, error) {
+	o, err := s.ConvertOrderspaceOrder(order)
+	if err != nil {
+		return PackingSlip{}, err
+	}
+	return NewPackingSlip(o), nil
+}
+
+// WooPackingSlip builds the packing slip of a WooCommerce order
+func (s *OrderService) WooPackingSlip(order woocommerce.Order) (PackingSlip, error) {
+	o, err := s.ConvertWooOrder(order)
+	if err != nil {
+		return PackingSlip{}, err
+	}
+	return NewPackingSlip(o), nil
+}
+
+// NewPackingSlip builds the packing slip of an order. Orders without a
+// shipping address, such as collections, show the billing address.
+func NewPackingSlip(o Order) PackingSlip {
+	address := o.ShippingAddress
+	if address.IsZero() {
+		address = o.BillingAddress
+	}
+	slip := PackingSlip{
+		Order:          o,
+		Reference:      o.Reference,
+		ShipTo:         address.Lines(),
+		Phone:          o.Phone,
+		ShippingMethod: o.ShippingMethod,
+		CustomerNote:   o.CustomerNote,
+	}
+	for _, line := range o.Lines {
+		slip.Lines = append(slip.Lines, PackingLine{SKU: line.SKU, Name: line.Name, Options: line.Options, Quantity: line.Quantity})
+	}
+	return slip
+}
+
+// BuildPickList totals the lines of the packing slips by SKU. Lines without a
+// SKU are matched on name and options.
+func BuildPickList(slips []PackingSlip) PickList {
+	list := PickList{Slips: slips}
+	index := map[string]int{}
+	for _, slip := range slips {
+		for _, line := range slip.Lines {
+			if line.Quantity <= 0 {
+				continue
+			}
+			key := NormalizeSKU(line.SKU)
+			if key == "" {
+				key = "\x00" + strings.ToLower(line.Name+"\x00"+line.Options)
+			}
+			i, ok := index[key]
+			if !ok {
+				i = len(list.Items)
+				index[key] = i
+				line.SKU = NormalizeSKU(line.SKU)
+				list.Items = append(list.Items, PickItem{PackingLine: PackingLine{SKU: line.SKU, Name: line.Name, Options: line.Options}})
+			}
+			item := &list.Items[i]
+			item.Quantity += line.Quantity
+			if !slices.Contains(item.Orders, slip.Order.OrderNumber) {
+				item.Orders = append(item.Orders, slip.Order.OrderNumber)
+			}
+		}
+	}
+
+	slices.SortStableFunc(list.Items, func(a, b PickItem) int {
+		if (a.SKU == "") != (b.SKU == "") {
+			if a.SKU == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(a.SKU, b.SKU), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Options, b.Options))
+	})
+	return list
+}
+
+func sumQuantities(lines []PackingLine) int {
+	total := 0
+	for _, line := range lines {
+		total += line.Quantity
+	}
+	return total
+}
+
+// Page layout of the PDF documents, in points
+const (
+	pdfMargin = 40.0
+	pdfRight  = pdf.PageWidth - pdfMargin
+	pdfBottom = pdf.PageHeight - pdfMargin
+)
+
+// pdfLayout writes rows down the pages of a document, starting a new page
+// when the current one is full
+type pdfLayout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64 // baseline of the next row
+	// header is drawn at the top of each page a table continues onto
+	header func()
+}
+
+func newPDFLayout(title string) *pdfLayout {
+	return &pdfLayout{doc: pdf.New(title)}
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = pdfMargin + 14
+}
+
+// need starts a new page, repeating the table header, unless height points are left on this one
+func (l *pdfLayout) need(height float64) {
+	if l.page != nil && l.y+height <= pdfBottom {
+		return
+	}
+	l.newPage()
+	if l.header != nil {
+		l.header()
+	}
+}
+
+func (l *pdfLayout) text(x float64, font pdf.Font, size float64, s string) {
+	l.page.Text(x, l.y, font, size, s)
+}
+
+// textRight draws s ending at x
+func (l *pdfLayout) textRight(x float64, font pdf.Font, size float64, s string) {
+	l.page.Text(x-pdf.Width(font, size, s), l.y, font, size, s)
+}
+
+// rule draws a line across the page just below the current row
+func (l *pdfLayout) rule(width float64) {
+	l.page.Line(pdfMargin, l.y+5, pdfRight, l.y+5, width)
+}
+
+// WritePackingSlipsPDF writes the packing slips as a PDF, each starting on a new page
+func WritePackingSlipsPDF(w io.Writer, slips []PackingSlip) error {
+	l := newPDFLayout("Packing slips")
+	for _, slip := range slips {
+		l.header = nil
+		l.newPage()
+		writePackingSlipPDF(l, slip)
+	}
+	if _, err := l.doc.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write packing slip pdf: %w", err)
+	}
+	return nil
+}
+
+func writePackingSlipPDF(l *pdfLayout, slip PackingSlip) {
+	o := slip.Order
+	l.text(pdfMargin, pdf.HelveticaBold, 18, "Packing slip")
+	l.textRight(pdfRight, pdf.HelveticaBold, 14, fmt.Sprintf("Order #%d", o.OrderNumber))
+	l.y += 16
+	l.textRight(pdfRight, pdf.Helvetica, 9, titleOrigin(o.Origin)+" order placed "+o.OrderDate)
+	if o.DeliverOn != "" && o.DeliverOn != "N/A" {
+		l.y += 12
+		l.textRight(pdfRight, pdf.HelveticaBold, 9, "Deliver on "+o.DeliverOn)
+	}
+
+	// Address on the left, order details on the right
+	l.y += 28
+	top := l.y
+	l.text(pdfMargin, pdf.HelveticaBold, 10, "Ship to")
+	for _, line := range slip.ShipTo {
+		l.y += 14
+		l.text(pdfMargin, pdf.Helvetica, 11, line)
+	}
+	bottom := l.y
+	l.y = top
+	for _, field := range [][2]string{{"Customer", o.Customer}, {"Reference", slip.Reference}, {"Phone", slip.Phone}, {"Shipping", slip.ShippingMethod}} {
+		if field[1] == "" {
+			continue
+		}
+		l.text(330, pdf.HelveticaBold, 10, field[0])
+		l.text(400, pdf.Helvetica, 10, field[1])
+		l.y += 14
+	}
+	l.y = max(bottom, l.y-14) + 28
+
+	if slip.CustomerNote != "" {
+		lines := pdf.Wrap(pdf.Helvetica, 10, slip.CustomerNote, pdfRight-pdfMargin-20)
+		height := 26 + 13*float64(len(lines))
+		l.need(height)
+		l.page.Rect(pdfMargin, l.y-12, pdfRight-pdfMargin, height-8, 0.75)
+		l.text(pdfMargin+10, pdf.HelveticaBold, 10, "Customer note")
+		for _, line := range lines {
+			l.y += 13
+			l.text(pdfMargin+10, pdf.Helvetica, 10, line)
+		}
+		l.y += 30
+	}
+
+	l.header = func() {
+		l.text(pdfMargin, pdf.HelveticaBold, 9, "Qty")
+		l.text(80, pdf.HelveticaBold, 9, "SKU")
+		l.text(180, pdf.HelveticaBold, 9, "Item")
+		l.textRight(pdfRight, pdf.HelveticaBold, 9, "Packed")
+		l.rule(0.75)
+		l.y += 20
+	}
+	l.need(40)
+	l.header()
+	for _, line := range slip.Lines {
+		name := pdf.Wrap(pdf.Helvetica, 10, line.Name, 300)
+		options := pdf.Wrap(pdf.Helvetica, 8, line.Options, 300)
+		if line.Options == "" {
+			options = nil
+		}
+		l.need(14*float64(len(name)) + 11*float64(len(options)) + 8)
+		l.text(pdfMargin, pdf.HelveticaBold, 11, strconv.Itoa(line.Quantity))
+		l.text(80, pdf.Helvetica, 9, line.SKU)
+		l.page.Rect(pdfRight-12, l.y-10, 12, 12, 0.75)
+		for i, s := range name {
+			if i > 0 {
+				l.y += 14
+			}
+			l.text(180, pdf.Helvetica, 10, s)
+		}
+		for _, s := range options {
+			l.y += 11
+			l.text(180, pdf.Helvetica, 8, s)
+		}
+		l.page.Line(pdfMargin, l.y+6, pdfRight, l.y+6, 0.25)
+		l.y += 20
+	}
+	l.need(20)
+	l.textRight(pdfRight, pdf.HelveticaBold, 10, fmt.Sprintf("%d items", slip.Quantity()))
+}
+
+// WritePickListPDF writes the pick list as a PDF, with a box to tick against each product
+func WritePickListPDF(w io.Writer, list PickList) error {
+	l := newPDFLayout("Pick list")
+	l.newPage()
+	l.text(pdfMargin, pdf.HelveticaBold, 18, "Pick list")
+	l.textRight(pdfRight, pdf.Helvetica, 10, fmt.Sprintf("%d orders, %d items", len(list.Slips), list.Quantity()))
+	l.y += 22
+
+	orders := make([]string, 0, len(list.Slips))
+	for _, slip := range list.Slips {
+		orders = append(orders, fmt.Sprintf("#%d %s", slip.Order.OrderNumber, slip.Order.Customer))
+	}
+	for _, line := range pdf.Wrap(pdf.Helvetica, 9, strings.Join(orders, ", "), pdfRight-pdfMargin) {
+		l.text(pdfMargin, pdf.Helvetica, 9, line)
+		l.y += 12
+	}
+	l.y += 16
+
+	l.header = func() {
+		l.text(pdfMargin+20, pdf.HelveticaBold, 9, "Qty")
+		l.text(95, pdf.HelveticaBold, 9, "SKU")
+		l.text(195, pdf.HelveticaBold, 9, "Item")
+		l.text(420, pdf.HelveticaBold, 9, "Orders")
+		l.rule(0.75)
+		l.y += 20
+	}
+	l.header()
+	for _, item := range list.Items {
+		name := pdf.Wrap(pdf.Helvetica, 10, item.Name, 215)
+		var options []string
+		if item.Options != "" {
+			options = pdf.Wrap(pdf.Helvetica, 8, item.Options, 215)
+		}
+		numbers := make([]string, 0, len(item.Orders))
+		for _, n := range item.Orders {
+			numbers = append(numbers, "#"+strconv.Itoa(n))
+		}
+		refs := pdf.Wrap(pdf.Helvetica, 9, strings.Join(numbers, " "), pdfRight-420)
+		l.need(13*float64(max(len(name)+len(options), len(refs))) + 8)
+
+		top := l.y
+		l.page.Rect(pdfMargin, l.y-10, 12, 12, 0.75)
+		l.text(pdfMargin+20, pdf.HelveticaBold, 11, strconv.Itoa(item.Quantity))
+		l.text(95, pdf.Helvetica, 9, item.SKU)
+		for i, s := range name {
+			if i > 0 {
+				l.y += 13
+			}
+			l.text(195, pdf.Helvetica, 10, s)
+		}
+		for _, s := range options {
+			l.y += 11
+			l.text(195, pdf.Helvetica, 8, s)
+		}
+		bottom := l.y
+		for i, s := range refs {
+			l.y = top + 13*float64(i)
+			l.text(420, pdf.Helvetica, 9, s)
+		}
+		l.y = max(bottom, l.y)
+		l.page.Line(pdfMargin, l.y+6, pdfRight, l.y+6, 0.25)
+		l.y += 20
+	}
+
+	if _, err := l.doc.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write pick list pdf: %w", err)
+	}
+	return nil
+}
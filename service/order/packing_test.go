@@ -0,0 +1,174 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/db/dbtest"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+func packingService(t *testing.T) *OrderService {
+	t.Helper()
+	osrv := orderspacetest.NewServer(orderspacetest.Orders(), nil, nil)
+	t.Cleanup(osrv.Close)
+	woo := woocommercetest.NewServer(woocommercetest.Orders(), nil, nil, nil)
+	t.Cleanup(woo.Close)
+	return &OrderService{
+		OrderspaceClient: osrv.NewClient(),
+		WooClient:        woo.NewClient(),
+		TitleCaser:       cases.Title(language.English),
+	}
+}
+
+func TestPackingSlip(t *testing.T) {
+	s := packingService(t)
+	ctx := context.Background()
+
+	slip, err := s.PackingSlip(ctx, Orderspace, "or_3kq9Zt1x")
+	if err != nil {
+		t.Fatalf("PackingSlip(orderspace): %v", err)
+	}
+	if slip.Order.OrderNumber != 1043 || slip.Reference != "PO-2291" || slip.CustomerNote != "Back door please" {
+		t.Errorf("orderspace slip = #%d ref %q note %q", slip.Order.OrderNumber, slip.Reference, slip.CustomerNote)
+	}
+	if want := []string{"Alex Morgan", "Bean There Cafe", "12 High Street", "Bristol", "BS1 4DJ", "GB"}; !slices.Equal(slip.ShipTo, want) {
+		t.Errorf("orderspace ship to = %q, want %q", slip.ShipTo, want)
+	}
+	// The shipping charge names the method rather than appearing as a line to pack
+	if want := []PackingLine{{SKU: "ESP-1KG-WB", Name: "House Espresso 1kg", Options: "Whole Bean", Quantity: 6}}; !slices.Equal(slip.Lines, want) {
+		t.Errorf("orderspace lines = %+v, want %+v", slip.Lines, want)
+	}
+	if slip.ShippingMethod != "Standard Delivery" {
+		t.Errorf("orderspace shipping method = %q, want Standard Delivery", slip.ShippingMethod)
+	}
+
+	slip, err = s.PackingSlip(ctx, WooCommerce, "5104")
+	if err != nil {
+		t.Fatalf("PackingSlip(woocommerce): %v", err)
+	}
+	if slip.Order.OrderNumber != 5104 || slip.CustomerNote != "Leave in porch" || slip.ShippingMethod != "Royal Mail" {
+		t.Errorf("woocommerce slip = #%d note %q shipping %q", slip.Order.OrderNumber, slip.CustomerNote, slip.ShippingMethod)
+	}
+	if want := []string{"Jamie Lee", "1 Roast Road", "Leeds", "LS1 1AA", "GB"}; !slices.Equal(slip.ShipTo, want) {
+		t.Errorf("woocommerce ship to = %q, want %q", slip.ShipTo, want)
+	}
+	if want := []PackingLine{{SKU: "ESP-250-WB", Name: "House Espresso 250g", Options: "grind: Whole Bean", Quantity: 2}}; !slices.Equal(slip.Lines, want) {
+		t.Errorf("woocommerce lines = %+v, want %+v", slip.Lines, want)
+	}
+
+	for _, ref := range [][2]string{{WooCommerce, "abc"}, {"ebay", "1"}} {
+		if _, err := s.PackingSlip(ctx, ref[0], ref[1]); !errors.Is(err, ErrInvalidOrderRef) {
+			t.Errorf("PackingSlip(%s, %s) error = %v, want ErrInvalidOrderRef", ref[0], ref[1], err)
+		}
+	}
+}
+
+func TestStoredPackingSlip(t *testing.T) {
+	store := dbtest.New()
+	s := &OrderService{DB: store, Queries: db.New(store), TitleCaser: cases.Title(language.English)}
+	ctx := context.Background()
+
+	raw, err := json.Marshal(orderspacetest.Orders()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Handle("GetOrderByExternalID", func(args []any) ([][]any, error) {
+		if args[0] != Orderspace || args[1] != "or_3kq9Zt1x" {
+			return nil, nil
+		}
+		// Columns after the external ID are left zero, apart from the raw order
+		return [][]any{{int64(1), Orderspace, "or_3kq9Zt1x", nil, nil, nil, nil, nil, nil, nil, nil, raw, nil, nil, nil, nil, nil, nil}}, nil
+	})
+
+	slip, err := s.StoredPackingSlip(ctx, Orderspace, "or_3kq9Zt1x")
+	if err != nil {
+		t.Fatalf("StoredPackingSlip: %v", err)
+	}
+	if slip.Order.OrderNumber != 1043 || len(slip.Lines) == 0 {
+		t.Errorf("slip = #%d with %d lines, want #1043 with lines", slip.Order.OrderNumber, len(slip.Lines))
+	}
+
+	if _, err := s.StoredPackingSlip(ctx, Orderspace, "or_missing"); !errors.Is(err, ErrOrderNotStored) {
+		t.Errorf("StoredPackingSlip(unsynced) error = %v, want ErrOrderNotStored", err)
+	}
+	if _, err := s.StoredPackingSlip(ctx, "shopify", "1"); !errors.Is(err, ErrInvalidOrderRef) {
+		t.Errorf("StoredPackingSlip(shopify) error = %v, want ErrInvalidOrderRef", err)
+	}
+}
+
+func TestBuildPickList(t *testing.T) {
+	slips := []PackingSlip{
+		{Order: Order{OrderNumber: 1043}, Lines: []PackingLine{
+			{SKU: "ESP-1KG-WB", Name: "House Espresso 1kg", Quantity: 6},
+			{Name: "Gift card", Quantity: 1},
+		}},
+		{Order: Order{OrderNumber: 5104}, Lines: []PackingLine{
+			{SKU: "esp-1kg-wb ", Name: "House Espresso 1kg", Quantity: 2},
+			{SKU: "ESP-1KG-WB", Name: "House Espresso 1kg", Quantity: 1},
+			{SKU: "FLT-250-FL", Name: "Filter Blend 250g", Quantity: 3},
+			{Name: "Gift card", Quantity: 2},
+			{SKU: "ETH-250-WB", Name: "Ethiopia 250g", Quantity: 0},
+		}},
+	}
+
+	list := BuildPickList(slips)
+	var got []string
+	for _, item := range list.Items {
+		got = append(got, fmt.Sprintf("%s %s x%d %v", item.SKU, item.Name, item.Quantity, item.Orders))
+	}
+	want := []string{
+		"ESP-1KG-WB House Espresso 1kg x9 [1043 5104]",
+		"FLT-250-FL Filter Blend 250g x3 [5104]",
+		" Gift card x3 [1043 5104]",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+	if list.Quantity() != 15 {
+		t.Errorf("Quantity() = %d, want 15", list.Quantity())
+	}
+}
+
+func TestWritePackingSlipsPDF(t *testing.T) {
+	long := PackingSlip{Order: Order{OrderNumber: 2001, Origin: Orderspace, OrderDate: "Mar 3, 2025"}, CustomerNote: "Ring (twice)"}
+	for i := range 60 {
+		long.Lines = append(long.Lines, PackingLine{SKU: fmt.Sprintf("SKU-%02d", i), Name: "Coffee", Quantity: 1})
+	}
+	short := PackingSlip{Order: Order{OrderNumber: 2002, Origin: WooCommerce}, ShipTo: []string{"Jamie Lee", "1 Roast Road"}}
+
+	var buf bytes.Buffer
+	if err := WritePackingSlipsPDF(&buf, []PackingSlip{long, short}); err != nil {
+		t.Fatalf("WritePackingSlipsPDF: %v", err)
+	}
+	out := buf.String()
+	// The long slip runs onto a second page and the short one starts a third
+	if !strings.Contains(out, "/Count 3") {
+		t.Error("expected three pages")
+	}
+	for _, want := range []string{"(Order #2001)", "(Ring \\(twice\\))", "(SKU-59)", "(60 items)", "(Order #2002)", "(1 Roast Road)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("pdf is missing %s", want)
+		}
+	}
+
+	buf.Reset()
+	if err := WritePickListPDF(&buf, BuildPickList([]PackingSlip{long, short})); err != nil {
+		t.Fatalf("WritePickListPDF: %v", err)
+	}
+	for _, want := range []string{"(Pick list)", "(2 orders, 60 items)", "(SKU-00)", "(#2001)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("pick list pdf is missing %s", want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package order
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"golang.org/x/sync/errgroup"
+)
+
+// ProductListing is one channel's offer of a SKU
+type ProductListing struct {
+	Origin     string
+	ProductID  string
+	VariantID  string // empty for simple WooCommerce products
+	SKU        string
+	Name       string
+	Price      money.Money            // wholesale unit price on Orderspace, current selling price on WooCommerce
+	RRP        money.Money            // retail price: the Orderspace RRP or the WooCommerce regular price, zero when unset
+	PriceLists map[string]money.Money // Orderspace price list name to unit price, for lists that override Price
+	Currency   string
+	Active     bool
+}
+
+// UnifiedProduct is a SKU and every channel listing that sells it
+type UnifiedProduct struct {
+	SKU      string
+	Name     string
+	Listings []ProductListing
+
+	// NameMismatch is set when two listings' names don't describe the same thing
+	NameMismatch bool
+	// PriceMismatch is set when listings disagree on the retail price. Wholesale
+	// and retail prices are expected to differ, so only RRPs are compared.
+	PriceMismatch bool
+}
+
+// Catalogue is every product sold on either channel, keyed by SKU
+type Catalogue struct {
+	Products []UnifiedProduct // sorted by SKU
+	NoSKU    []ProductListing // listings that can't be matched because they have no SKU
+}
+
+// Listing returns the first listing from origin, or nil if the channel doesn't sell the SKU
+func (p UnifiedProduct) Listing(origin string) *ProductListing {
+	i := slices.IndexFunc(p.Listings, func(l ProductListing) bool { return l.Origin == origin })
+	if i < 0 {
+		return nil
+	}
+	return &p.Listings[i]
+}
+
+// Mismatched reports whether the listings disagree on name or price
+func (p UnifiedProduct) Mismatched() bool {
+	return p.NameMismatch || p.PriceMismatch
+}
+
+// Matches reports whether the SKU or any listing name contains query, ignoring case
+func (p UnifiedProduct) Matches(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if strings.Contains(strings.ToLower(p.SKU), query) {
+		return true
+	}
+	return slices.ContainsFunc(p.Listings, func(l ProductListing) bool {
+		return strings.Contains(strings.ToLower(l.Name), query)
+	})
+}
+
+// ProductCatalogue loads the products of both channels and groups them by SKU.
+// Products aren't stored locally, so this reads both channels' APIs.
+func (s *OrderService) ProductCatalogue(ctx context.Context) (*Catalogue, error) {
+	var orderspaceListings, wooListings []ProductListing
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		orderspaceListings, err = s.orderspaceListings(ctx)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		wooListings, err = s.wooListings(ctx)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return buildCatalogue(append(orderspaceListings, wooListings...)), nil
+}
+
+// orderspaceListings returns a listing for every Orderspace product variant
+func (s *OrderService) orderspaceListings(ctx context.Context) ([]ProductListing, error) {
+	priceLists, err := s.OrderspaceClient.ListPriceLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orderspace price lists: %w", err)
+	}
+	names := make(map[string]string, len(priceLists))
+	currency := ""
+	for _, pl := range priceLists {
+		names[pl.ID] = pl.Name
+		if pl.Default || currency == "" {
+			currency = pl.Currency
+		}
+	}
+
+	var listings []ProductListing
+	for product, err := range s.OrderspaceClient.AllProducts(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list orderspace products: %w", err)
+		}
+		for _, variant := range product.Variants {
+			listing := ProductListing{
+				Origin:    Orderspace,
+				ProductID: product.ID,
+				VariantID: variant.ID,
+				SKU:       variant.SKU,
+				Name:      orderspaceVariantName(product, variant),
+				Price:     money.FromFloat(variant.UnitPrice, currency),
+				RRP:       money.FromFloat(variant.RRP, currency),
+				Currency:  currency,
+				Active:    product.Active,
+			}
+			for _, price := range variant.PriceListPrices {
+				if listing.PriceLists == nil {
+					listing.PriceLists = map[string]money.Money{}
+				}
+				listing.PriceLists[cmp.Or(names[price.PriceListID], price.PriceListID)] = money.FromFloat(price.UnitPrice, currency)
+			}
+			listings = append(listings, listing)
+		}
+	}
+	return listings, nil
+}
+
+// wooListings returns a listing for every simple WooCommerce product and every
+// variation of a variable product
+func (s *OrderService) wooListings(ctx context.Context) ([]ProductListing, error) {
+	currency, err := s.WooClient.CurrentCurrency(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get woocommerce currency: %w", err)
+	}
+
+	var listings []ProductListing
+	for product, err := range s.WooClient.AllProducts(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list woocommerce products: %w", err)
+		}
+		switch product.Type {
+		case woocommerce.ProductTypeGrouped:
+			// Grouped products only bundle other products and have no price of their own
+			continue
+		case woocommerce.ProductTypeVariable:
+			for variation, err := range s.WooClient.AllVariations(ctx, product.ID, nil) {
+				if err != nil {
+					return nil, fmt.Errorf("failed to list variations of woocommerce product %d: %w", product.ID, err)
+				}
+				price, rrp, err := wooPrices(variation.Price, variation.RegularPrice, currency.Code)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read price of woocommerce variation %d: %w", variation.ID, err)
+				}
+				listings = append(listings, ProductListing{
+					Origin:    WooCommerce,
+					ProductID: strconv.Itoa(product.ID),
+					VariantID: strconv.Itoa(variation.ID),
+					SKU:       variation.SKU,
+					Name:      wooVariationName(product, variation),
+					Price:     price,
+					RRP:       rrp,
+					Currency:  currency.Code,
+					Active:    product.Status == "publish" && variation.Status == "publish",
+				})
+			}
+		default:
+			price, rrp, err := wooPrices(product.Price, product.RegularPrice, currency.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read price of woocommerce product %d: %w", product.ID, err)
+			}
+			listings = append(listings, ProductListing{
+				Origin:    WooCommerce,
+				ProductID: strconv.Itoa(product.ID),
+				SKU:       product.SKU,
+				Name:      product.Name,
+				Price:     price,
+				RRP:       rrp,
+				Currency:  currency.Code,
+				Active:    product.Status == "publish",
+			})
+		}
+	}
+	return listings, nil
+}
+
+// wooPrices parses a WooCommerce product's price and its RRP, the regular
+// price or the price itself when there is no separate regular price
+func wooPrices(price, regular, currency string) (money.Money, money.Money, error) {
+	p, err := wooAmount(price, currency)
+	if err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	rrp, err := wooAmount(cmp.Or(regular, price), currency)
+	if err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	return p, rrp, nil
+}
+
+// buildCatalogue groups listings by SKU, ignoring case and surrounding space, and flags mismatches
+func buildCatalogue(listings []ProductListing) *Catalogue {
+	catalogue := &Catalogue{}
+	bySKU := map[string]*UnifiedProduct{}
+	var skus []string
+	for _, l := range listings {
+		key := strings.ToUpper(strings.TrimSpace(l.SKU))
+		if key == "" {
+			catalogue.NoSKU = append(catalogue.NoSKU, l)
+			continue
+		}
+		p, ok := bySKU[key]
+		if !ok {
+			p = &UnifiedProduct{SKU: key}
+			bySKU[key] = p
+			skus = append(skus, key)
+		}
+		p.Listings = append(p.Listings, l)
+	}
+
+	slices.Sort(skus)
+	for _, sku := range skus {
+		p := bySKU[sku]
+		// Orderspace listings sort first so the wholesale name is the one shown
+		slices.SortStableFunc(p.Listings, func(a, b ProductListing) int {
+			return cmp.Compare(originRank(a.Origin), originRank(b.Origin))
+		})
+		p.Name = p.Listings[0].Name
+		p.NameMismatch = namesMismatch(p.Listings)
+		p.PriceMismatch = pricesMismatch(p.Listings)
+		catalogue.Products = append(catalogue.Products, *p)
+	}
+	return catalogue
+}
+
+// namesMismatch reports whether any two listings have names where neither
+// contains every word of the other. Channels name variants differently
+// ("House Espresso 250g" against "House Espresso - 250g, Whole Bean"), so
+// one name adding detail to the other is not a mismatch.
+func namesMismatch(listings []ProductListing) bool {
+	for i := range listings {
+		for j := i + 1; j < len(listings); j++ {
+			a, b := nameWords(listings[i].Name), nameWords(listings[j].Name)
+			if !isSubset(a, b) && !isSubset(b, a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pricesMismatch reports whether two listings in the same currency have different retail prices
+func pricesMismatch(listings []ProductListing) bool {
+	for i := range listings {
+		for j := i + 1; j < len(listings); j++ {
+			a, b := listings[i], listings[j]
+			if a.RRP.IsZero() || b.RRP.IsZero() || a.RRP.Currency != b.RRP.Currency {
+				continue
+			}
+			if a.RRP != b.RRP {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func originRank(origin string) int {
+	if origin == Orderspace {
+		return 0
+	}
+	return 1
+}
+
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isSubset(words, of []string) bool {
+	for _, w := range words {
+		if !slices.Contains(of, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// orderspaceVariantName appends the variant's option values, ordered by option name, to the product name
+func orderspaceVariantName(product orderspace.Product, variant orderspace.Variant) string {
+	keys := make([]string, 0, len(variant.Options))
+	for k := range variant.Options {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, variant.Options[k])
+	}
+	if len(values) == 0 {
+		return product.Name
+	}
+	return product.Name + " - " + strings.Join(values, ", ")
+}
+
+// wooVariationName names a variation the way WooCommerce does, "Parent - Option, Option"
+func wooVariationName(product woocommerce.Product, variation woocommerce.Variation) string {
+	values := make([]string, 0, len(variation.Attributes))
+	for _, a := range variation.Attributes {
+		values = append(values, a.Option)
+	}
+	if len(values) == 0 {
+		return product.Name
+	}
+	return product.Name + " - " + strings.Join(values, ", ")
+}
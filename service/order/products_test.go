@@ -0,0 +1,101 @@
+package order
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+)
+
+func TestBuildCatalogue(t *testing.T) {
+	listings := []ProductListing{
+		{Origin: WooCommerce, SKU: "esp-250-wb ", Name: "House Espresso 250g", Price: gbp(1000), RRP: gbp(1000), Currency: "GBP"},
+		{Origin: Orderspace, SKU: "ESP-250-WB", Name: "House Espresso - Whole Bean, 250g", Price: gbp(525), RRP: gbp(1000), Currency: "GBP"},
+		{Origin: Orderspace, SKU: "FLT-250-FL", Name: "Filter Blend - Filter, 250g", Price: gbp(525), RRP: gbp(1100), Currency: "GBP"},
+		{Origin: WooCommerce, SKU: "FLT-250-FL", Name: "Filter Blend 250g", Price: gbp(1200), RRP: gbp(1200), Currency: "GBP"},
+		{Origin: Orderspace, SKU: "ETH-250-WB", Name: "Ethiopia Guji", RRP: gbp(1200), Currency: "GBP"},
+		{Origin: WooCommerce, SKU: "ETH-250-WB", Name: "Single Origin Kenya 250g", RRP: gbp(1200), Currency: "GBP"},
+		{Origin: Orderspace, SKU: "DEC-1KG-WB", Name: "Decaf Colombia", Price: gbp(1900), Currency: "GBP"},
+		{Origin: WooCommerce, SKU: "DEC-1KG-WB", Name: "Decaf Colombia", Price: gbp(3000), RRP: gbp(3000), Currency: "GBP"},
+		{Origin: Orderspace, SKU: "", Name: "Decaf Colombia - Sample"},
+	}
+
+	got := buildCatalogue(listings)
+
+	type summary struct {
+		SKU           string
+		Name          string
+		Origins       []string
+		NameMismatch  bool
+		PriceMismatch bool
+	}
+	want := []summary{
+		{"DEC-1KG-WB", "Decaf Colombia", []string{Orderspace, WooCommerce}, false, false},
+		{"ESP-250-WB", "House Espresso - Whole Bean, 250g", []string{Orderspace, WooCommerce}, false, false},
+		{"ETH-250-WB", "Ethiopia Guji", []string{Orderspace, WooCommerce}, true, false},
+		{"FLT-250-FL", "Filter Blend - Filter, 250g", []string{Orderspace, WooCommerce}, false, true},
+	}
+	if len(got.Products) != len(want) {
+		t.Fatalf("got %d products, want %d", len(got.Products), len(want))
+	}
+	for i, w := range want {
+		p := got.Products[i]
+		var origins []string
+		for _, l := range p.Listings {
+			origins = append(origins, l.Origin)
+		}
+		g := summary{p.SKU, p.Name, origins, p.NameMismatch, p.PriceMismatch}
+		if g.SKU != w.SKU || g.Name != w.Name || !slices.Equal(g.Origins, w.Origins) ||
+			g.NameMismatch != w.NameMismatch || g.PriceMismatch != w.PriceMismatch {
+			t.Errorf("product %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if len(got.NoSKU) != 1 || got.NoSKU[0].Name != "Decaf Colombia - Sample" {
+		t.Errorf("NoSKU = %+v, want the sample variant", got.NoSKU)
+	}
+}
+
+func TestProductCatalogue(t *testing.T) {
+	osrv := orderspacetest.NewServer(orderspacetest.Orders(), orderspacetest.Customers(), orderspacetest.Products())
+	t.Cleanup(osrv.Close)
+	woo := woocommercetest.NewServer(woocommercetest.Orders(), woocommercetest.Customers(), woocommercetest.Products(), woocommercetest.Variations())
+	t.Cleanup(woo.Close)
+	s := &OrderService{OrderspaceClient: osrv.NewClient(), WooClient: woo.NewClient()}
+
+	catalogue, err := s.ProductCatalogue(context.Background())
+	if err != nil {
+		t.Fatalf("ProductCatalogue: %v", err)
+	}
+
+	var skus []string
+	for _, p := range catalogue.Products {
+		skus = append(skus, p.SKU)
+	}
+	want := []string{"DEC-1KG-WB", "ESP-1KG-ES", "ESP-1KG-WB", "ESP-250-WB", "ETH-250-WB", "FLT-250-FL"}
+	if !slices.Equal(skus, want) {
+		t.Fatalf("SKUs = %v, want %v", skus, want)
+	}
+
+	bulk := catalogue.Products[2]
+	woolisting, oslisting := bulk.Listing(WooCommerce), bulk.Listing(Orderspace)
+	if woolisting == nil || oslisting == nil {
+		t.Fatalf("ESP-1KG-WB listings = %+v, want one per channel", bulk.Listings)
+	}
+	if woolisting.Name != "House Espresso Bulk - 1kg, Whole Bean" || woolisting.Price != gbp(3000) || woolisting.RRP != gbp(3200) {
+		t.Errorf("woocommerce listing = %+v", woolisting)
+	}
+	if oslisting.PriceLists["Trade Plus"] != gbp(1700) || oslisting.Currency != "GBP" {
+		t.Errorf("orderspace listing = %+v, want Trade Plus price 17 in GBP", oslisting)
+	}
+	if bulk.Mismatched() {
+		t.Errorf("ESP-1KG-WB flagged as mismatched: %+v", bulk)
+	}
+	if filter := catalogue.Products[5]; !filter.PriceMismatch {
+		t.Errorf("FLT-250-FL RRPs %v, want a price mismatch", filter.Listings)
+	}
+	if len(catalogue.NoSKU) != 1 {
+		t.Errorf("got %d listings without a SKU, want 1", len(catalogue.NoSKU))
+	}
+}
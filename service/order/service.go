@@ -1,13 +1,22 @@
 package order
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
 	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/shipping"
 	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -17,34 +26,156 @@ type OrderServiceConfig struct {
 	OrderspaceBaseURL      string
 	OrderspaceClientID     string
 	OrderspaceClientSecret string
+	OrderspaceTokenURL     string  // optional, defaults to orderspace.DefaultTokenURL
+	OrderspaceRateLimit    float64 // requests per second, 0 for unlimited
+	OrderspaceRateBurst    int
 	// Woocommerce Client
 	WooBaseURL        string
 	WooConsumerKey    string
 	WooConsumerSecret string
+	WooRateLimit      float64 // requests per second, 0 for unlimited
+	WooRateBurst      int
+	// Location is the business timezone, used to show dates and group orders by day. Defaults to UTC.
+	Location *time.Location
+	// Carrier buys shipping labels, nil to turn labels off
+	Carrier shipping.Carrier
+	// ShipFrom is the address parcels are sent from
+	ShipFrom shipping.Address
 }
 
-// Order represents an order from either system for display
+// ErrUnreadableAmount is returned for orders with an amount that is malformed
+// or can't be added to the rest, which are rejected rather than counted as zero
+var ErrUnreadableAmount = errors.New("unreadable amount")
+
+// Origins identify which sales channel an order came from
+const (
+	WooCommerce = "woocommerce"
+	Orderspace  = "orderspace"
+)
+
+// Order represents an order from either system. The display strings
+// summarise it for lists; the rest is the full order in a shape common to
+// both channels.
 type Order struct {
-	ID          string
-	OrderNumber int
-	Customer    string
-	OrderDate   string
-	DeliverOn   string
-	Total       string
-	Status      string
-	Origin      string
-	SortDate    time.Time // Added for sorting purposes
+	ID           string
+	OrderNumber  int
+	Customer     string
+	OrderDate    string // the day the order was placed, in the business timezone
+	DeliverOn    string
+	DeliveryDate time.Time   // zero when the order has no delivery date
+	Total        money.Money // what the customer pays
+	Status       Status
+	Origin       string
+	PlacedAt     time.Time // zero when the channel's timestamp couldn't be parsed
+	DateError    string    // why PlacedAt couldn't be parsed, empty when it could
+
+	ChannelStatus   string // the status as the channel names it, e.g. "On-Hold"
+	Currency        string
+	Reference       string // the customer's purchase order number or reference
+	Email           string
+	Phone           string
+	Lines           []LineItem // products ordered; shipping charges are in Totals.Shipping
+	ShippingAddress Address
+	BillingAddress  Address
+	ShippingMethod  string
+	PaymentMethod   string
+	CustomerNote    string
+	InternalNote    string
+	Coupons         []Coupon
+	Totals          Totals
+}
+
+// LineItem is a product on an order. Amounts are in the order's currency and exclude tax.
+type LineItem struct {
+	ID        string // the channel's ID for the line
+	SKU       string
+	Name      string
+	Options   string // chosen options such as grind, e.g. "Whole Bean" or "grind: Whole Bean"
+	Quantity  int
+	UnitPrice money.Money
+	Subtotal  money.Money // before discounts
+	Total     money.Money // after discounts
+	Tax       money.Money
+	OnHold    bool // held back from dispatch, Orderspace only
+	// Dispatched is how many have been sent. WooCommerce orders are sent whole,
+	// so their lines are either all or none dispatched.
+	Dispatched int
+}
+
+// Outstanding returns how many of the line are still to be sent
+func (l LineItem) Outstanding() int {
+	return max(l.Quantity-l.Dispatched, 0)
+}
+
+// Address is a billing or shipping address
+type Address struct {
+	Name       string
+	Company    string
+	Line1      string
+	Line2      string
+	City       string
+	State      string
+	PostalCode string
+	Country    string
+	Email      string
+	Phone      string
+}
+
+// Lines returns the non-empty parts of the address, one per line, without contact details
+func (a Address) Lines() []string {
+	var lines []string
+	for _, part := range []string{a.Name, a.Company, a.Line1, a.Line2, a.City, a.State, a.PostalCode, a.Country} {
+		lines = appendUnique(lines, part)
+	}
+	return lines
+}
+
+// IsZero reports whether the address has no street, town or postcode
+func (a Address) IsZero() bool {
+	return a.Line1 == "" && a.City == "" && a.PostalCode == ""
+}
+
+// Coupon is a discount code applied to an order
+type Coupon struct {
+	Code     string
+	Discount money.Money
+}
+
+// Totals breaks down what an order costs, in the order's currency. They add
+// up to Order.Total.
+type Totals struct {
+	Subtotal money.Money // line items before discounts, excluding tax
+	Discount money.Money
+	Shipping money.Money // excluding tax
+	Fees     money.Money
+	Tax      money.Money
+}
+
+// Database is where orders are stored: the Postgres pool, or a stand-in in tests
+type Database interface {
+	db.DBTX
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 }
 
 type OrderService struct {
 	WooClient        *woocommerce.Client
 	OrderspaceClient *orderspace.Client
 	TitleCaser       cases.Caser
+	DB               Database
+	Queries          *db.Queries
+	Location         *time.Location   // business timezone, nil for UTC
+	Carrier          shipping.Carrier // nil when shipping labels are off
+	ShipFrom         shipping.Address
 }
 
-func New(logger *slog.Logger, cfg OrderServiceConfig) *OrderService {
+func New(logger *slog.Logger, cfg OrderServiceConfig, pool *pgxpool.Pool) *OrderService {
 	orderspaceClient := orderspace.NewClient(cfg.OrderspaceBaseURL, cfg.OrderspaceClientID, cfg.OrderspaceClientSecret)
+	if cfg.OrderspaceTokenURL != "" {
+		orderspaceClient.SetTokenURL(cfg.OrderspaceTokenURL)
+	}
+	orderspaceClient.SetRateLimit(cfg.OrderspaceRateLimit, cfg.OrderspaceRateBurst)
 	woocommerceClient := woocommerce.NewClient(cfg.WooBaseURL, cfg.WooConsumerKey, cfg.WooConsumerSecret)
+	woocommerceClient.SetRateLimit(cfg.WooRateLimit, cfg.WooRateBurst)
 	// Create a title caser for English
 	titleCaser := cases.Title(language.English)
 
@@ -52,103 +183,292 @@ func New(logger *slog.Logger, cfg OrderServiceConfig) *OrderService {
 		WooClient:        woocommerceClient,
 		OrderspaceClient: orderspaceClient,
 		TitleCaser:       titleCaser,
+		DB:               pool,
+		Queries:          db.New(pool),
+		Location:         cfg.Location,
+		Carrier:          cfg.Carrier,
+		ShipFrom:         cfg.ShipFrom,
 	}
 
 	slog.Info("Order service initialized")
 	return service
 }
 
-// FormatCurrency formats the currency amount based on the currency
-func FormatCurrency(amount float64, currency string) string {
-	switch strings.ToUpper(currency) {
-	case "USD":
-		return "$" + strconv.FormatFloat(amount, 'f', 2, 64)
-	case "GBP":
-		return "£" + strconv.FormatFloat(amount, 'f', 2, 64)
-	case "EUR":
-		return "€" + strconv.FormatFloat(amount, 'f', 2, 64)
-	default:
-		return strconv.FormatFloat(amount, 'f', 2, 64) + " " + currency
+// ChannelStatus reports how hard a sales channel's API is currently being pushed
+type ChannelStatus struct {
+	Origin    string `json:"origin"`
+	Throttled bool   `json:"throttled"`
+	WaitMS    int64  `json:"wait_ms"` // how long a request made now would be held by the rate limiter
+	Retries   int64  `json:"retries"` // retries since startup
+}
+
+// ChannelStatuses returns the current rate limiter and retry state of both API clients
+func (s *OrderService) ChannelStatuses() []ChannelStatus {
+	status := func(origin string, wait time.Duration, retries int64) ChannelStatus {
+		return ChannelStatus{
+			Origin:    origin,
+			Throttled: wait > 0,
+			WaitMS:    wait.Milliseconds(),
+			Retries:   retries,
+		}
+	}
+	return []ChannelStatus{
+		status(Orderspace, s.OrderspaceClient.RateLimitWait(), s.OrderspaceClient.Retries()),
+		status(WooCommerce, s.WooClient.RateLimitWait(), s.WooClient.Retries()),
 	}
 }
 
-// ConvertWooOrder converts a WooCommerce order to Order
-func (s *OrderService) ConvertWooOrder(order woocommerce.Order) Order {
+// ConvertWooOrder converts a WooCommerce order to Order. It fails with
+// ErrUnreadableAmount when any of the order's amounts is malformed or they
+// don't add up in its currency, rather than counting them as zero.
+func (s *OrderService) ConvertWooOrder(order woocommerce.Order) (Order, error) {
 	customer := strings.TrimSpace(order.Billing.FirstName + " " + order.Billing.LastName)
 	if customer == "" {
 		customer = order.Billing.Email
 	}
 
-	// Parse total
-	total, err := strconv.ParseFloat(order.Total, 64)
-	if err != nil {
-		total = 0
+	amounts := &orderAmounts{currency: order.Currency}
+	o := Order{
+		ID:              strconv.Itoa(order.ID),
+		OrderNumber:     order.ID,
+		Customer:        customer,
+		DeliverOn:       "N/A",
+		Total:           amounts.woo("total", order.Total),
+		Status:          WooStatus(order.Status),
+		Origin:          WooCommerce,
+		ChannelStatus:   s.TitleCaser.String(order.Status),
+		Currency:        order.Currency,
+		Email:           order.Billing.Email,
+		Phone:           cmp.Or(order.Shipping.Phone, order.Billing.Phone),
+		ShippingAddress: wooAddress(order.Shipping),
+		BillingAddress:  wooAddress(order.Billing),
+		PaymentMethod:   order.PaymentMethodTitle,
+		CustomerNote:    strings.TrimSpace(order.CustomerNote),
+		Totals: Totals{
+			Subtotal: money.Zero(order.Currency),
+			Discount: amounts.woo("discount_total", order.DiscountTotal),
+			Shipping: amounts.woo("shipping_total", order.ShippingTotal),
+			Fees:     money.Zero(order.Currency),
+			Tax:      amounts.woo("total_tax", order.TotalTax),
+		},
 	}
-
-	// Parse date for sorting
-	sortDate, err := time.Parse("2006-01-02T15:04:05", order.DateCreated)
-	if err != nil {
-		slog.Warn("Failed to parse WooCommerce date for sorting", "date", order.DateCreated, "error", err)
-		sortDate = time.Now() // Fallback to current time
+	for _, line := range order.LineItems {
+		item := LineItem{
+			ID:        strconv.Itoa(line.ID),
+			SKU:       line.SKU,
+			Name:      line.Name,
+			Options:   wooLineOptions(line),
+			Quantity:  line.Quantity,
+			UnitPrice: money.Zero(order.Currency),
+			Subtotal:  amounts.woo("line subtotal", line.Subtotal),
+			Total:     amounts.woo("line total", line.Total),
+			Tax:       amounts.woo("line total_tax", line.TotalTax),
+		}
+		if line.Quantity != 0 {
+			item.UnitPrice = item.Subtotal.Div(line.Quantity)
+		}
+		if o.Status == StatusFulfilled {
+			item.Dispatched = line.Quantity
+		}
+		o.Lines = append(o.Lines, item)
+		amounts.add("subtotal", &o.Totals.Subtotal, item.Subtotal)
 	}
-
-	// Format date for display
-	orderDate := order.DateCreated
-	if err == nil {
-		orderDate = sortDate.Format("Jan 2, 2006")
+	var methods []string
+	for _, line := range order.ShippingLines {
+		methods = appendUnique(methods, line.MethodTitle)
 	}
-
-	return Order{
-		ID:          strconv.Itoa(order.ID),
-		OrderNumber: order.ID,
-		Customer:    customer,
-		OrderDate:   orderDate,
-		DeliverOn:   "N/A",
-		Total:       FormatCurrency(total, order.Currency),
-		Status:      s.TitleCaser.String(order.Status),
-		Origin:      "woocommerce",
-		SortDate:    sortDate,
+	o.ShippingMethod = strings.Join(methods, ", ")
+	for _, fee := range order.FeeLines {
+		amounts.add("fees", &o.Totals.Fees, amounts.woo("fee total", fee.Total))
 	}
+	for _, coupon := range order.CouponLines {
+		o.Coupons = append(o.Coupons, Coupon{Code: coupon.Code, Discount: amounts.woo("coupon discount", coupon.Discount)})
+	}
+	if amounts.err != nil {
+		return Order{}, fmt.Errorf("failed to convert woocommerce order %d: %w", order.ID, amounts.err)
+	}
+	// DateCreated is in the shop's own timezone with no offset; the _gmt variant is UTC
+	placedAt, err := time.Parse(wooDateLayout, order.DateCreatedGMT)
+	s.setPlacedAt(&o, placedAt, err)
+	return o, nil
 }
 
-// ConvertOrderspaceOrder converts an Orderspace order to UnifiedOrder
-func (s *OrderService) ConvertOrderspaceOrder(order orderspace.Order) Order {
+// ConvertOrderspaceOrder converts an Orderspace order to UnifiedOrder. It
+// fails with ErrUnreadableAmount when the order's lines don't add up in its
+// currency.
+func (s *OrderService) ConvertOrderspaceOrder(order orderspace.Order) (Order, error) {
 	customer := order.CompanyName
 	if customer == "" && order.BillingAddress.ContactName != "" {
 		customer = order.BillingAddress.ContactName
 	}
 
-	// Parse date for sorting
-	sortDate, err := time.Parse("2006-01-02T15:04:05Z", order.Created)
-	if err != nil {
-		slog.Warn("Failed to parse Orderspace date for sorting", "date", order.Created, "error", err)
-		sortDate = time.Now() // Fallback to current time
-	}
-
-	// Format date for display
-	orderDate := order.Created
-	if err == nil {
-		orderDate = sortDate.Format("Jan 2, 2006")
-	}
-
 	deliverOn := "N/A"
+	var deliveryDate time.Time
 	if order.DeliveryDate != "" {
 		if parsed, err := time.Parse("2006-01-02", order.DeliveryDate); err == nil {
 			deliverOn = parsed.Format("Jan 2, 2006")
+			deliveryDate = parsed
 		} else {
 			deliverOn = order.DeliveryDate
 		}
 	}
 
-	return Order{
-		ID:          order.ID,
-		OrderNumber: order.Number,
-		Customer:    customer,
-		OrderDate:   orderDate,
-		DeliverOn:   deliverOn,
-		Total:       FormatCurrency(order.GrossTotal, order.Currency),
-		Status:      s.TitleCaser.String(order.Status),
-		Origin:      "orderspace",
-		SortDate:    sortDate,
+	// Each float is converted to minor units before any sums, so they add up exactly
+	amounts := &orderAmounts{currency: order.Currency}
+	tax := money.FromFloat(order.GrossTotal, order.Currency)
+	amounts.add("tax", &tax, money.FromFloat(order.NetTotal, order.Currency).Neg())
+	o := Order{
+		ID:           order.ID,
+		OrderNumber:  order.Number,
+		Customer:     customer,
+		DeliverOn:    deliverOn,
+		DeliveryDate: deliveryDate,
+		Total:        money.FromFloat(order.GrossTotal, order.Currency),
+		Status:       OrderspaceStatus(order),
+		Origin:       Orderspace,
+
+		ChannelStatus:   s.TitleCaser.String(order.Status),
+		Currency:        order.Currency,
+		Reference:       cmp.Or(order.CustomerPONumber, order.Reference),
+		Email:           order.EmailAddresses.Orders,
+		Phone:           order.Phone,
+		ShippingAddress: orderspaceAddress(order.ShippingAddress),
+		BillingAddress:  orderspaceAddress(order.BillingAddress),
+		CustomerNote:    strings.TrimSpace(order.CustomerNote),
+		InternalNote:    strings.TrimSpace(order.InternalNote),
+		Totals: Totals{
+			Subtotal: money.Zero(order.Currency),
+			Discount: money.Zero(order.Currency),
+			Shipping: money.Zero(order.Currency),
+			Fees:     money.Zero(order.Currency),
+			Tax:      tax,
+		},
+	}
+	// Orderspace charges shipping as order lines
+	var methods []string
+	for _, line := range order.OrderLines {
+		if line.Shipping {
+			methods = appendUnique(methods, line.Name)
+			amounts.add("shipping", &o.Totals.Shipping, money.FromFloat(line.SubTotal, order.Currency))
+			continue
+		}
+		subtotal := money.FromFloat(line.SubTotal, order.Currency)
+		o.Lines = append(o.Lines, LineItem{
+			ID:         line.ID,
+			SKU:        line.SKU,
+			Name:       line.Name,
+			Options:    line.Options,
+			Quantity:   line.Quantity,
+			UnitPrice:  money.FromFloat(line.UnitPrice, order.Currency),
+			Subtotal:   subtotal,
+			Total:      subtotal,
+			Tax:        money.FromFloat(line.TaxAmount, order.Currency),
+			OnHold:     line.OnHold,
+			Dispatched: line.Dispatched,
+		})
+		amounts.add("subtotal", &o.Totals.Subtotal, subtotal)
+	}
+	if amounts.err != nil {
+		return Order{}, fmt.Errorf("failed to convert orderspace order %s: %w", order.ID, amounts.err)
+	}
+	o.ShippingMethod = cmp.Or(strings.Join(methods, ", "), order.ShippingType)
+	placedAt, err := time.Parse(time.RFC3339, order.Created)
+	s.setPlacedAt(&o, placedAt, err)
+	return o, nil
+}
+
+// orderAmounts reads and sums the amounts of one order in its currency. The
+// first failure is kept in err, so a converter can check once at the end.
+type orderAmounts struct {
+	currency string
+	err      error
+}
+
+// woo parses a WooCommerce amount, naming the field if it is malformed
+func (a *orderAmounts) woo(field, s string) money.Money {
+	m, err := wooAmount(s, a.currency)
+	if err != nil && a.err == nil {
+		a.err = fmt.Errorf("%s: %w", field, err)
+	}
+	return m
+}
+
+// add adds m to total, leaving total as it was if they can't be added
+func (a *orderAmounts) add(field string, total *money.Money, m money.Money) {
+	sum, err := total.Add(m)
+	if err != nil {
+		if a.err == nil {
+			a.err = fmt.Errorf("%w: %s: %w", ErrUnreadableAmount, field, err)
+		}
+		return
+	}
+	*total = sum
+}
+
+// setPlacedAt records when the order was placed, or why the channel's
+// timestamp couldn't be read. A bad timestamp is left zero rather than
+// guessed, so the order shows as undated instead of jumping to the top.
+func (s *OrderService) setPlacedAt(o *Order, placedAt time.Time, err error) {
+	if err != nil {
+		slog.Warn("Failed to parse order date", "origin", o.Origin, "orderID", o.ID, "error_message", err)
+		o.OrderDate = "Unknown"
+		o.DateError = err.Error()
+		return
+	}
+	o.PlacedAt = placedAt
+	o.OrderDate = placedAt.In(s.location()).Format("Jan 2, 2006")
+}
+
+// location returns the business timezone
+func (s *OrderService) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+// Now returns the current time in the business timezone, so its date is today's date for the business
+func (s *OrderService) Now() time.Time {
+	return time.Now().In(s.location())
+}
+
+// wooAmount parses a WooCommerce decimal string in the order's currency. A
+// blank amount is zero; a malformed one is an ErrUnreadableAmount.
+func wooAmount(s, currency string) (money.Money, error) {
+	if strings.TrimSpace(s) == "" {
+		return money.Zero(currency), nil
+	}
+	amount, err := money.Parse(s, currency)
+	if err != nil {
+		return money.Zero(currency), fmt.Errorf("%w: %w", ErrUnreadableAmount, err)
+	}
+	return amount, nil
+}
+
+func orderspaceAddress(a orderspace.OrderAddress) Address {
+	return Address{
+		Name:       a.ContactName,
+		Company:    a.CompanyName,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func wooAddress(a woocommerce.OrderAddress) Address {
+	return Address{
+		Name:       strings.TrimSpace(a.FirstName + " " + a.LastName),
+		Company:    a.Company,
+		Line1:      a.Address1,
+		Line2:      a.Address2,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.Postcode,
+		Country:    a.Country,
+		Email:      a.Email,
+		Phone:      a.Phone,
 	}
 }
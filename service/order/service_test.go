@@ -0,0 +1,152 @@
+package order
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+func TestConvertOrderspaceOrder(t *testing.T) {
+	s := &OrderService{TitleCaser: cases.Title(language.English)}
+	o, err := s.ConvertOrderspaceOrder(orderspacetest.Orders()[0])
+	if err != nil {
+		t.Fatalf("ConvertOrderspaceOrder: %v", err)
+	}
+
+	if o.Reference != "PO-2291" || o.Email != "orders@beanthere.example" || o.Phone != "01632 960123" || o.CustomerNote != "Back door please" {
+		t.Errorf("contact = ref %q email %q phone %q note %q", o.Reference, o.Email, o.Phone, o.CustomerNote)
+	}
+	if want := []string{"Alex Morgan", "Bean There Cafe", "12 High Street", "Bristol", "BS1 4DJ", "GB"}; !slices.Equal(o.ShippingAddress.Lines(), want) {
+		t.Errorf("shipping address = %q, want %q", o.ShippingAddress.Lines(), want)
+	}
+	// The shipping charge line becomes the shipping total and method
+	want := []LineItem{{ID: "ol_1", SKU: "ESP-1KG-WB", Name: "House Espresso 1kg", Options: "Whole Bean", Quantity: 6, UnitPrice: gbp(1850), Subtotal: gbp(11100), Total: gbp(11100), Tax: gbp(0)}}
+	if !slices.Equal(o.Lines, want) {
+		t.Errorf("lines = %+v, want %+v", o.Lines, want)
+	}
+	if o.ShippingMethod != "Standard Delivery" {
+		t.Errorf("shipping method = %q, want Standard Delivery", o.ShippingMethod)
+	}
+	if want := (Totals{Subtotal: gbp(11100), Discount: gbp(0), Shipping: gbp(750), Fees: gbp(0), Tax: gbp(150)}); o.Totals != want {
+		t.Errorf("totals = %+v, want %+v", o.Totals, want)
+	}
+	if o.Total != gbp(12000) {
+		t.Errorf("total = %+v, want £120.00", o.Total)
+	}
+}
+
+func TestConvertWooOrder(t *testing.T) {
+	s := &OrderService{TitleCaser: cases.Title(language.English)}
+	order := woocommercetest.Orders()[0]
+	order.DiscountTotal = "2.00"
+	order.Total = "25.50"
+	order.FeeLines = []woocommerce.OrderFeeLine{{Name: "Gift wrap", Total: "4.00"}}
+	order.CouponLines = []woocommerce.OrderCouponLine{{Code: "SPRING", Discount: "2.00"}}
+	order.LineItems[0].Total = "18.00"
+	o, err := s.ConvertWooOrder(order)
+	if err != nil {
+		t.Fatalf("ConvertWooOrder: %v", err)
+	}
+
+	if o.Email != "jamie@example.com" || o.Phone != "07700 900123" || o.PaymentMethod != "Credit Card" || o.ShippingMethod != "Royal Mail" {
+		t.Errorf("contact = email %q phone %q payment %q shipping %q", o.Email, o.Phone, o.PaymentMethod, o.ShippingMethod)
+	}
+	if want := []string{"Jamie Lee", "1 Roast Road", "Leeds", "LS1 1AA", "GB"}; !slices.Equal(o.ShippingAddress.Lines(), want) {
+		t.Errorf("shipping address = %q, want %q", o.ShippingAddress.Lines(), want)
+	}
+	want := []LineItem{{ID: "901", SKU: "ESP-250-WB", Name: "House Espresso 250g", Options: "grind: Whole Bean", Quantity: 2, UnitPrice: gbp(1000), Subtotal: gbp(2000), Total: gbp(1800), Tax: gbp(0)}}
+	if !slices.Equal(o.Lines, want) {
+		t.Errorf("lines = %+v, want %+v", o.Lines, want)
+	}
+	if want := (Totals{Subtotal: gbp(2000), Discount: gbp(200), Shipping: gbp(350), Fees: gbp(400), Tax: gbp(0)}); o.Totals != want {
+		t.Errorf("totals = %+v, want %+v", o.Totals, want)
+	}
+	if o.Total != gbp(2550) {
+		t.Errorf("total = %+v, want £25.50", o.Total)
+	}
+	if want := []Coupon{{Code: "SPRING", Discount: gbp(200)}}; !slices.Equal(o.Coupons, want) {
+		t.Errorf("coupons = %+v, want %+v", o.Coupons, want)
+	}
+}
+
+func TestConvertWooOrderRejectsUnreadableAmounts(t *testing.T) {
+	s := &OrderService{TitleCaser: cases.Title(language.English)}
+	tests := []struct {
+		name string
+		edit func(o *woocommerce.Order)
+	}{
+		{"malformed total", func(o *woocommerce.Order) { o.Total = "25,50" }},
+		{"malformed line", func(o *woocommerce.Order) { o.LineItems[0].Subtotal = "twenty" }},
+		{"malformed fee", func(o *woocommerce.Order) { o.FeeLines = []woocommerce.OrderFeeLine{{Name: "Gift wrap", Total: "n/a"}} }},
+		{"subtotal overflow", func(o *woocommerce.Order) {
+			o.LineItems = append(o.LineItems, o.LineItems[0])
+			o.LineItems[0].Subtotal = "90000000000000000.00"
+			o.LineItems[1].Subtotal = "90000000000000000.00"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := woocommercetest.Orders()[0]
+			tt.edit(&order)
+			if _, err := s.ConvertWooOrder(order); !errors.Is(err, ErrUnreadableAmount) {
+				t.Errorf("err = %v, want ErrUnreadableAmount", err)
+			}
+		})
+	}
+}
+
+func TestConvertOrderDates(t *testing.T) {
+	// Los Angeles is seven hours behind UTC in mid-March, so an early morning
+	// order in UTC was placed the evening before there
+	s := &OrderService{TitleCaser: cases.Title(language.English), Location: time.FixedZone("PDT", -7*60*60)}
+
+	woo := woocommercetest.Orders()[0]
+	woo.DateCreated = "2025-03-14T03:30:00" // shop-local, which must be ignored
+	woo.DateCreatedGMT = "2025-03-14T05:30:00"
+	o, err := s.ConvertWooOrder(woo)
+	if err != nil {
+		t.Fatalf("ConvertWooOrder: %v", err)
+	}
+	if want := time.Date(2025, 3, 14, 5, 30, 0, 0, time.UTC); !o.PlacedAt.Equal(want) || o.OrderDate != "Mar 13, 2025" || o.DateError != "" {
+		t.Errorf("woocommerce placed = %s %q %q, want %s on Mar 13", o.PlacedAt, o.OrderDate, o.DateError, want)
+	}
+
+	wholesale := orderspacetest.Orders()[0]
+	wholesale.Created = "2025-03-14T09:15:00+02:00"
+	if o, err = s.ConvertOrderspaceOrder(wholesale); err != nil {
+		t.Fatalf("ConvertOrderspaceOrder: %v", err)
+	}
+	if want := time.Date(2025, 3, 14, 7, 15, 0, 0, time.UTC); !o.PlacedAt.Equal(want) || o.OrderDate != "Mar 14, 2025" {
+		t.Errorf("orderspace placed = %s %q, want %s on Mar 14", o.PlacedAt, o.OrderDate, want)
+	}
+
+	// A timestamp that can't be read is surfaced rather than replaced with now
+	woo.DateCreatedGMT = ""
+	if o, err = s.ConvertWooOrder(woo); err != nil {
+		t.Fatalf("ConvertWooOrder: %v", err)
+	}
+	if !o.PlacedAt.IsZero() || o.DateError == "" || o.OrderDate != "Unknown" {
+		t.Errorf("unparseable date = %s %q %q, want zero time, an error and Unknown", o.PlacedAt, o.OrderDate, o.DateError)
+	}
+}
+
+func gbp(pence int64) money.Money {
+	return money.New(pence, "GBP")
+}
+
+func TestAddressIsZero(t *testing.T) {
+	if !(Address{Name: "Jamie Lee", Email: "jamie@example.com"}).IsZero() {
+		t.Error("address with only contact details should be zero")
+	}
+	if (Address{PostalCode: "LS1 1AA"}).IsZero() {
+		t.Error("address with a postcode should not be zero")
+	}
+}
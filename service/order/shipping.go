@@ -0,0 +1,317 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrShippingDisabled is returned by the shipping label methods when no carrier is configured
+var ErrShippingDisabled = errors.New("shipping labels are not set up")
+
+// ShippingLabel is postage bought for an order from this app
+type ShippingLabel struct {
+	ID             int64       `json:"id"`
+	Origin         string      `json:"origin"`
+	OrderID        string      `json:"order_id"`
+	Provider       string      `json:"provider"` // the shipping.Carrier it was bought through
+	LabelID        string      `json:"label_id"` // the provider's ID for the label
+	Carrier        string      `json:"carrier"`
+	Service        string      `json:"service"`
+	TrackingNumber string      `json:"tracking_number"`
+	TrackingURL    string      `json:"tracking_url,omitempty"`
+	LabelURL       string      `json:"label_url"`
+	Price          money.Money `json:"price"`
+	BoughtBy       string      `json:"bought_by"`
+	BoughtAt       time.Time   `json:"bought_at"`
+	VoidedAt       time.Time   `json:"voided_at,omitzero"` // zero unless voided
+}
+
+// Voided reports whether the label has been cancelled
+func (l ShippingLabel) Voided() bool {
+	return !l.VoidedAt.IsZero()
+}
+
+// ShippingEnabled reports whether a carrier is configured to buy labels
+func (s *OrderService) ShippingEnabled() bool {
+	return s.Carrier != nil
+}
+
+// QuoteShipping asks the carrier for rates to send a parcel to an order's
+// shipping address, or its billing address when it has none. The quote is
+// stored against the order, so only this order can buy its rates.
+func (s *OrderService) QuoteShipping(ctx context.Context, origin, id string, parcel shipping.Parcel) (*shipping.Quote, error) {
+	if s.Carrier == nil {
+		return nil, ErrShippingDisabled
+	}
+	if err := parcel.Valid(); err != nil {
+		return nil, err
+	}
+	o, err := s.fetchOrder(ctx, origin, id)
+	if err != nil {
+		return nil, err
+	}
+	if !o.Status.Open() {
+		return nil, &shipping.ShipmentError{Field: "order", Problem: "is already " + strings.ToLower(o.Status.Label())}
+	}
+
+	from, err := shipping.NormalizeAddress(s.ShipFrom)
+	if err != nil {
+		return nil, prefixShipmentError("from.", err)
+	}
+	to, err := shipping.NormalizeAddress(s.shipTo(*o))
+	if err != nil {
+		return nil, prefixShipmentError("to.", err)
+	}
+
+	quote, err := s.Carrier.Quote(ctx, shipping.Shipment{
+		From:      from,
+		To:        to,
+		Parcel:    parcel,
+		Reference: "#" + strconv.Itoa(o.OrderNumber),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote shipping for %s order %s: %w", origin, id, err)
+	}
+
+	rateIDs := make([]string, 0, len(quote.Rates))
+	for _, rate := range quote.Rates {
+		rateIDs = append(rateIDs, rate.ID)
+	}
+	err = s.Queries.InsertShippingQuote(ctx, db.InsertShippingQuoteParams{
+		Origin:     origin,
+		ExternalID: id,
+		Provider:   s.Carrier.Name(),
+		QuoteID:    quote.ID,
+		RateIds:    rateIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record shipping quote %s for %s order %s: %w", quote.ID, origin, id, err)
+	}
+	return quote, nil
+}
+
+// BuyShippingLabel buys a rate from a quote made for the order, as long as the
+// order is still open, and stores the label against it. The carrier has been
+// paid once this returns a label, so a failure to store it is logged rather
+// than returned.
+func (s *OrderService) BuyShippingLabel(ctx context.Context, origin, id, quoteID, rateID, boughtBy string) (*ShippingLabel, error) {
+	if s.Carrier == nil {
+		return nil, ErrShippingDisabled
+	}
+	if origin != Orderspace && origin != WooCommerce {
+		return nil, fmt.Errorf("%w: origin %q", ErrInvalidOrderRef, origin)
+	}
+	boughtBy = strings.TrimSpace(boughtBy)
+	if boughtBy == "" {
+		return nil, &shipping.ShipmentError{Field: "bought_by", Problem: "is required"}
+	}
+
+	quote, err := s.Queries.GetShippingQuote(ctx, db.GetShippingQuoteParams{Provider: s.Carrier.Name(), QuoteID: quoteID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, shipping.ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipping quote %s: %w", quoteID, err)
+	}
+	if quote.Origin != origin || quote.ExternalID != id {
+		return nil, &shipping.ShipmentError{Field: "quote_id", Problem: "was made for a different order"}
+	}
+	if !slices.Contains(quote.RateIds, rateID) {
+		return nil, &shipping.ShipmentError{Field: "rate_id", Problem: "is not one of the quote's rates"}
+	}
+	o, err := s.fetchOrder(ctx, origin, id)
+	if err != nil {
+		return nil, err
+	}
+	if !o.Status.Open() {
+		return nil, &shipping.ShipmentError{Field: "order", Problem: "is already " + strings.ToLower(o.Status.Label())}
+	}
+
+	label, err := s.Carrier.BuyLabel(ctx, quoteID, rateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to buy shipping label for %s order %s: %w", origin, id, err)
+	}
+
+	row, err := s.Queries.InsertShippingLabel(ctx, db.InsertShippingLabelParams{
+		Origin:         origin,
+		ExternalID:     id,
+		Provider:       s.Carrier.Name(),
+		LabelID:        label.ID,
+		Carrier:        label.Carrier,
+		Service:        label.Service,
+		TrackingNumber: label.TrackingNumber,
+		TrackingUrl:    label.TrackingURL,
+		LabelUrl:       label.LabelURL,
+		PriceMinor:     label.Price.Amount,
+		Currency:       label.Price.Currency,
+		BoughtBy:       boughtBy,
+	})
+	if err != nil {
+		slog.Error("Failed to record shipping label", "origin", origin, "orderID", id, "label_id", label.ID, "tracking_number", label.TrackingNumber, "error_message", err)
+		return &ShippingLabel{
+			Origin:         origin,
+			OrderID:        id,
+			Provider:       s.Carrier.Name(),
+			LabelID:        label.ID,
+			Carrier:        label.Carrier,
+			Service:        label.Service,
+			TrackingNumber: label.TrackingNumber,
+			TrackingURL:    label.TrackingURL,
+			LabelURL:       label.LabelURL,
+			Price:          label.Price,
+			BoughtBy:       boughtBy,
+			BoughtAt:       s.Now(),
+		}, nil
+	}
+	stored := s.shippingLabel(row)
+	return &stored, nil
+}
+
+// VoidShippingLabel cancels an order's label with the carrier so it is refunded
+func (s *OrderService) VoidShippingLabel(ctx context.Context, origin, id string, labelID int64) (*ShippingLabel, error) {
+	row, err := s.storedLabel(ctx, origin, id, labelID)
+	if err != nil {
+		return nil, err
+	}
+	if row.VoidedAt.Valid {
+		return nil, shipping.ErrLabelVoided
+	}
+
+	if err := s.Carrier.VoidLabel(ctx, row.LabelID); err != nil {
+		return nil, fmt.Errorf("failed to void shipping label %d: %w", labelID, err)
+	}
+	row, err = s.Queries.VoidShippingLabel(ctx, labelID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, shipping.ErrLabelVoided
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record shipping label %d as voided: %w", labelID, err)
+	}
+	voided := s.shippingLabel(row)
+	return &voided, nil
+}
+
+// TrackShippingLabel asks the carrier where an order's parcel has got to
+func (s *OrderService) TrackShippingLabel(ctx context.Context, origin, id string, labelID int64) (*shipping.Tracking, error) {
+	row, err := s.storedLabel(ctx, origin, id, labelID)
+	if err != nil {
+		return nil, err
+	}
+	tracking, err := s.Carrier.Track(ctx, row.TrackingNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to track shipping label %d: %w", labelID, err)
+	}
+	if !tracking.EstimatedDelivery.IsZero() {
+		tracking.EstimatedDelivery = tracking.EstimatedDelivery.In(s.location())
+	}
+	for i := range tracking.Events {
+		tracking.Events[i].Time = tracking.Events[i].Time.In(s.location())
+	}
+	return tracking, nil
+}
+
+// ShippingLabels returns the labels bought for an order, newest first
+func (s *OrderService) ShippingLabels(ctx context.Context, origin, id string) ([]ShippingLabel, error) {
+	rows, err := s.Queries.ListOrderShippingLabels(ctx, db.ListOrderShippingLabelsParams{Origin: origin, ExternalID: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipping labels of %s order %s: %w", origin, id, err)
+	}
+	labels := make([]ShippingLabel, 0, len(rows))
+	for _, row := range rows {
+		labels = append(labels, s.shippingLabel(row))
+	}
+	return labels, nil
+}
+
+// storedLabel gets one of an order's labels, checking it was bought through the configured carrier
+func (s *OrderService) storedLabel(ctx context.Context, origin, id string, labelID int64) (db.ShippingLabel, error) {
+	if s.Carrier == nil {
+		return db.ShippingLabel{}, ErrShippingDisabled
+	}
+	row, err := s.Queries.GetShippingLabel(ctx, db.GetShippingLabelParams{Origin: origin, ExternalID: id, ID: labelID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.ShippingLabel{}, shipping.ErrLabelNotFound
+	}
+	if err != nil {
+		return db.ShippingLabel{}, fmt.Errorf("failed to get shipping label %d: %w", labelID, err)
+	}
+	if row.Provider != s.Carrier.Name() {
+		return db.ShippingLabel{}, fmt.Errorf("shipping label %d was bought through %s, not %s", labelID, row.Provider, s.Carrier.Name())
+	}
+	return row, nil
+}
+
+// shipTo is where an order's parcel goes: its shipping address, or its billing
+// address for orders without one. A missing country is taken to be the
+// sender's, as both channels leave it blank for domestic orders.
+func (s *OrderService) shipTo(o Order) shipping.Address {
+	a := o.ShippingAddress
+	if a.IsZero() {
+		a = o.BillingAddress
+	}
+	to := shipping.Address{
+		Name:       a.Name,
+		Company:    a.Company,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Email:      a.Email,
+		Phone:      a.Phone,
+	}
+	if to.Country == "" {
+		to.Country = s.ShipFrom.Country
+	}
+	if to.Email == "" {
+		to.Email = o.Email
+	}
+	if to.Phone == "" {
+		to.Phone = o.Phone
+	}
+	return to
+}
+
+// prefixShipmentError names which address a normalisation error is about
+func prefixShipmentError(prefix string, err error) error {
+	var shipErr *shipping.ShipmentError
+	if errors.As(err, &shipErr) {
+		return &shipping.ShipmentError{Field: prefix + shipErr.Field, Problem: shipErr.Problem}
+	}
+	return err
+}
+
+// shippingLabel converts a stored label, with its times in the business timezone
+func (s *OrderService) shippingLabel(row db.ShippingLabel) ShippingLabel {
+	label := ShippingLabel{
+		ID:             row.ID,
+		Origin:         row.Origin,
+		OrderID:        row.ExternalID,
+		Provider:       row.Provider,
+		LabelID:        row.LabelID,
+		Carrier:        row.Carrier,
+		Service:        row.Service,
+		TrackingNumber: row.TrackingNumber,
+		TrackingURL:    row.TrackingUrl,
+		LabelURL:       row.LabelUrl,
+		Price:          money.New(row.PriceMinor, row.Currency),
+		BoughtBy:       row.BoughtBy,
+		BoughtAt:       row.BoughtAt.In(s.location()),
+	}
+	if row.VoidedAt.Valid {
+		label.VoidedAt = row.VoidedAt.Time.In(s.location())
+	}
+	return label
+}
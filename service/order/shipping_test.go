@@ -0,0 +1,169 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/db/dbtest"
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+	"github.com/dukerupert/paddy-cap/service/shipping/shippingtest"
+)
+
+func TestQuoteShipping(t *testing.T) {
+	s := packingService(t)
+	s.Carrier = shippingtest.NewCarrier()
+	store := dbtest.New()
+	s.Queries = db.New(store)
+	s.ShipFrom = shipping.Address{Company: "Paddy Cap Coffee", Line1: "3 Roastery Yard", City: "Leeds", PostalCode: "ls11aa", Country: "GB"}
+	ctx := context.Background()
+
+	quote, err := s.QuoteShipping(ctx, Orderspace, "or_3kq9Zt1x", shipping.Parcel{WeightGrams: 1500})
+	if err != nil {
+		t.Fatalf("QuoteShipping(orderspace): %v", err)
+	}
+	if len(quote.Rates) != 2 {
+		t.Fatalf("got %d rates, want 2", len(quote.Rates))
+	}
+	// 2 started kilos on the fake's cheapest service
+	if got, want := quote.Rates[0].Price, money.New(550, "GBP"); got != want {
+		t.Errorf("price = %v, want %v", got, want)
+	}
+	saved := store.Calls("InsertShippingQuote")
+	if len(saved) != 1 || saved[0].Args[1] != "or_3kq9Zt1x" || saved[0].Args[3] != quote.ID || len(saved[0].Args[4].([]string)) != 2 {
+		t.Errorf("saved quotes = %+v, want the quote and its rates stored against the order", saved)
+	}
+
+	if _, err := s.QuoteShipping(ctx, WooCommerce, "5104", shipping.Parcel{WeightGrams: 250}); err != nil {
+		t.Errorf("QuoteShipping(woocommerce): %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		origin    string
+		id        string
+		parcel    shipping.Parcel
+		wantField string
+	}{
+		{"no weight", Orderspace, "or_3kq9Zt1x", shipping.Parcel{}, "weight_grams"},
+		{"fulfilled order", Orderspace, "or_1Aa0Bb9c", shipping.Parcel{WeightGrams: 500}, "order"},
+		{"completed order", WooCommerce, "5101", shipping.Parcel{WeightGrams: 500}, "order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.QuoteShipping(ctx, tt.origin, tt.id, tt.parcel)
+			var shipErr *shipping.ShipmentError
+			if !errors.As(err, &shipErr) || shipErr.Field != tt.wantField {
+				t.Errorf("err = %v, want ShipmentError on %s", err, tt.wantField)
+			}
+		})
+	}
+
+	s.ShipFrom.PostalCode = ""
+	_, err = s.QuoteShipping(ctx, Orderspace, "or_3kq9Zt1x", shipping.Parcel{WeightGrams: 500})
+	var shipErr *shipping.ShipmentError
+	if !errors.As(err, &shipErr) || shipErr.Field != "from.postal_code" {
+		t.Errorf("err = %v, want ShipmentError on from.postal_code", err)
+	}
+
+	s.Carrier = nil
+	if _, err := s.QuoteShipping(ctx, Orderspace, "or_3kq9Zt1x", shipping.Parcel{WeightGrams: 500}); !errors.Is(err, ErrShippingDisabled) {
+		t.Errorf("err = %v, want ErrShippingDisabled", err)
+	}
+}
+
+func TestBuyShippingLabel(t *testing.T) {
+	s := packingService(t)
+	s.Carrier = shippingtest.NewCarrier()
+	s.ShipFrom = shipping.Address{Company: "Paddy Cap Coffee", Line1: "3 Roastery Yard", City: "Leeds", PostalCode: "LS1 1AA", Country: "GB"}
+	store := dbtest.New()
+	s.Queries = db.New(store)
+	// Stored quotes are read back as the database would return them
+	store.Handle("GetShippingQuote", func(args []any) ([][]any, error) {
+		var rows [][]any
+		for _, c := range store.Calls("InsertShippingQuote") {
+			if c.Args[2] == args[0] && c.Args[3] == args[1] {
+				rows = append(rows, []any{int64(len(rows) + 1), c.Args[0], c.Args[1], c.Args[2], c.Args[3], c.Args[4], nil})
+			}
+		}
+		return rows, nil
+	})
+	ctx := context.Background()
+
+	quote, err := s.QuoteShipping(ctx, Orderspace, "or_3kq9Zt1x", shipping.Parcel{WeightGrams: 500})
+	if err != nil {
+		t.Fatalf("QuoteShipping: %v", err)
+	}
+	other, err := s.QuoteShipping(ctx, WooCommerce, "5104", shipping.Parcel{WeightGrams: 500})
+	if err != nil {
+		t.Fatalf("QuoteShipping: %v", err)
+	}
+	rateID := quote.Rates[0].ID
+
+	tests := []struct {
+		name      string
+		origin    string
+		id        string
+		quoteID   string
+		rateID    string
+		wantField string
+	}{
+		{"quote for another order", WooCommerce, "5104", quote.ID, rateID, "quote_id"},
+		{"rate from another quote", Orderspace, "or_3kq9Zt1x", quote.ID, other.Rates[0].ID, "rate_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.BuyShippingLabel(ctx, tt.origin, tt.id, tt.quoteID, tt.rateID, "Sam")
+			var shipErr *shipping.ShipmentError
+			if !errors.As(err, &shipErr) || shipErr.Field != tt.wantField {
+				t.Errorf("err = %v, want ShipmentError on %s", err, tt.wantField)
+			}
+		})
+	}
+	if _, err := s.BuyShippingLabel(ctx, Orderspace, "or_3kq9Zt1x", "unknown", rateID, "Sam"); !errors.Is(err, shipping.ErrQuoteNotFound) {
+		t.Errorf("err = %v, want ErrQuoteNotFound", err)
+	}
+	if _, err := s.BuyShippingLabel(ctx, Orderspace, "or_3kq9Zt1x", quote.ID, rateID, "Sam"); err != nil {
+		t.Fatalf("BuyShippingLabel: %v", err)
+	}
+
+	// A quote made while the order was open can't be bought once it is fulfilled
+	store.Handle("GetShippingQuote", func(args []any) ([][]any, error) {
+		return [][]any{{int64(1), Orderspace, "or_1Aa0Bb9c", shippingtest.Name, quote.ID, []string{rateID}, nil}}, nil
+	})
+	_, err = s.BuyShippingLabel(ctx, Orderspace, "or_1Aa0Bb9c", quote.ID, rateID, "Sam")
+	var shipErr *shipping.ShipmentError
+	if !errors.As(err, &shipErr) || shipErr.Field != "order" {
+		t.Errorf("err = %v, want ShipmentError on order", err)
+	}
+	bought := store.Calls("InsertShippingLabel")
+	if len(bought) != 1 {
+		t.Fatalf("stored %d labels, want only the one bought for the open order", len(bought))
+	}
+	if got, want := bought[0].Args[9], quote.Rates[0].Price.Amount; got != want {
+		t.Errorf("stored price = %v, want %d minor units", got, want)
+	}
+}
+
+func TestShippingLabelPrice(t *testing.T) {
+	s := &OrderService{}
+	// Dinars have three decimal places, which a two-decimal column would round away
+	label := s.shippingLabel(db.ShippingLabel{PriceMinor: 1234, Currency: "KWD"})
+	if want := money.New(1234, "KWD"); label.Price != want {
+		t.Errorf("price = %v, want %v", label.Price, want)
+	}
+}
+
+func TestShipTo(t *testing.T) {
+	s := &OrderService{ShipFrom: shipping.Address{Country: "GB"}}
+	o := Order{
+		Email:          "ada@example.com",
+		BillingAddress: Address{Name: "Ada", Line1: "2 High Street", City: "York", PostalCode: "YO1 7HH"},
+	}
+	to := s.shipTo(o)
+	if to.Line1 != "2 High Street" || to.Country != "GB" || to.Email != "ada@example.com" {
+		t.Errorf("shipTo = %+v, want billing address in GB with the order's email", to)
+	}
+}
@@ -0,0 +1,169 @@
+package order
+
+import (
+	"strings"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+)
+
+// Status is where an order is in its lifecycle. Each channel has its own
+// statuses; these are what they mean to us, so orders from both can be
+// compared and filtered together.
+type Status string
+
+// Order lifecycle, in the order an order normally moves through it
+const (
+	StatusNew                 Status = "new"
+	StatusAwaitingPayment     Status = "awaiting_payment"
+	StatusReadyToFulfil       Status = "ready_to_fulfil"
+	StatusPartiallyDispatched Status = "partially_dispatched"
+	StatusFulfilled           Status = "fulfilled"
+	StatusCancelled           Status = "cancelled"
+	StatusRefunded            Status = "refunded"
+	// StatusUnknown is a channel status with no mapping, which should be added below
+	StatusUnknown Status = "unknown"
+)
+
+// Statuses lists every lifecycle status a channel status can map to, in lifecycle order
+var Statuses = []Status{
+	StatusNew,
+	StatusAwaitingPayment,
+	StatusReadyToFulfil,
+	StatusPartiallyDispatched,
+	StatusFulfilled,
+	StatusCancelled,
+	StatusRefunded,
+}
+
+var statusLabels = map[Status]string{
+	StatusNew:                 "New",
+	StatusAwaitingPayment:     "Awaiting payment",
+	StatusReadyToFulfil:       "Ready to fulfil",
+	StatusPartiallyDispatched: "Partially dispatched",
+	StatusFulfilled:           "Fulfilled",
+	StatusCancelled:           "Cancelled",
+	StatusRefunded:            "Refunded",
+	StatusUnknown:             "Unknown",
+}
+
+// orderspaceStatuses maps Orderspace order statuses to the lifecycle.
+// Partial dispatch isn't a status in Orderspace and is worked out from the lines.
+var orderspaceStatuses = map[string]Status{
+	"new":              StatusNew,
+	"awaiting_payment": StatusAwaitingPayment,
+	"released":         StatusReadyToFulfil,
+	"dispatched":       StatusFulfilled,
+	"fulfilled":        StatusFulfilled,
+	"completed":        StatusFulfilled,
+	"cancelled":        StatusCancelled,
+	"refunded":         StatusRefunded,
+}
+
+// wooStatuses maps WooCommerce order statuses to the lifecycle. On-hold orders
+// are waiting for a payment, such as a bank transfer, to be confirmed, and
+// checkout drafts for the customer to pay. A failed payment ends the order
+// unless the customer pays again, so it's treated as cancelled until then.
+var wooStatuses = map[string]Status{
+	"checkout-draft": StatusAwaitingPayment,
+	"pending":        StatusAwaitingPayment,
+	"on-hold":        StatusAwaitingPayment,
+	"failed":         StatusCancelled,
+	"processing":     StatusReadyToFulfil,
+	"completed":      StatusFulfilled,
+	"cancelled":      StatusCancelled,
+	"trash":          StatusCancelled,
+	"refunded":       StatusRefunded,
+}
+
+// ParseStatus returns the lifecycle status named s, such as "ready_to_fulfil"
+func ParseStatus(s string) (Status, bool) {
+	status := Status(strings.ToLower(strings.TrimSpace(s)))
+	_, ok := statusLabels[status]
+	return status, ok && status != StatusUnknown
+}
+
+// Label returns the status for display, e.g. "Ready to fulfil"
+func (s Status) Label() string {
+	if label, ok := statusLabels[s]; ok {
+		return label
+	}
+	return string(s)
+}
+
+// Open reports whether the order still has to be sent
+func (s Status) Open() bool {
+	switch s {
+	case StatusNew, StatusAwaitingPayment, StatusReadyToFulfil, StatusPartiallyDispatched:
+		return true
+	}
+	return false
+}
+
+// ToFulfil reports whether the order still has to be roasted and sent now:
+// it is open and not waiting for a payment first
+func (s Status) ToFulfil() bool {
+	return s.Open() && s != StatusAwaitingPayment
+}
+
+// HoldsStock reports whether an order's goods are spoken for. Open orders,
+// including those awaiting payment, hold stock until they are cancelled or
+// refunded; fulfilled orders have used it.
+func (s Status) HoldsStock() bool {
+	return s.Open() || s == StatusFulfilled
+}
+
+// Sold reports whether an order counts as a sale: it has been paid for and
+// is neither cancelled nor refunded
+func (s Status) Sold() bool {
+	return s.ToFulfil() || s == StatusFulfilled
+}
+
+// StatusFor maps a channel's status to the lifecycle. It can't tell an
+// Orderspace order is partially dispatched, which needs its lines; use
+// OrderspaceStatus for that.
+func StatusFor(origin, raw string) Status {
+	var statuses map[string]Status
+	switch origin {
+	case Orderspace:
+		statuses = orderspaceStatuses
+	case WooCommerce:
+		statuses = wooStatuses
+	}
+	if s, ok := statuses[strings.ToLower(raw)]; ok {
+		return s
+	}
+	return StatusUnknown
+}
+
+// OrderspaceStatus maps an Orderspace order to the lifecycle. An order that is
+// neither finished nor cancelled but has some of its goods dispatched is
+// partially dispatched.
+func OrderspaceStatus(order orderspace.Order) Status {
+	status := StatusFor(Orderspace, order.Status)
+	if status.Open() && partiallyDispatched(order.OrderLines) {
+		return StatusPartiallyDispatched
+	}
+	return status
+}
+
+// WooStatus maps a WooCommerce order status to the lifecycle
+func WooStatus(status string) Status {
+	return StatusFor(WooCommerce, status)
+}
+
+// partiallyDispatched reports whether some, but not all, of the goods on the lines have been dispatched
+func partiallyDispatched(lines []orderspace.OrderLine) bool {
+	some, all := false, true
+	for _, line := range lines {
+		if line.Shipping {
+			continue
+		}
+		if line.Dispatched > 0 {
+			some = true
+		}
+		if line.Dispatched < line.Quantity {
+			all = false
+		}
+	}
+	return some && !all
+}
@@ -0,0 +1,97 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+)
+
+func TestOrderspaceStatus(t *testing.T) {
+	// The fixtures are new, released with half the coffee sent, and fulfilled
+	want := []Status{StatusNew, StatusPartiallyDispatched, StatusFulfilled}
+	for i, o := range orderspacetest.Orders() {
+		if got := OrderspaceStatus(o); got != want[i] {
+			t.Errorf("order %s (%s) status = %s, want %s", o.ID, o.Status, got, want[i])
+		}
+	}
+
+	lines := []orderspace.OrderLine{
+		{Quantity: 2, Dispatched: 2},
+		{Quantity: 1, Dispatched: 0},
+		{Shipping: true, Quantity: 1},
+	}
+	tests := []struct {
+		status string
+		lines  []orderspace.OrderLine
+		want   Status
+	}{
+		{"released", lines, StatusPartiallyDispatched},
+		{"released", lines[:1], StatusReadyToFulfil},
+		{"released", lines[2:], StatusReadyToFulfil},
+		{"Awaiting_payment", nil, StatusAwaitingPayment},
+		{"cancelled", lines, StatusCancelled},
+		{"archived", nil, StatusUnknown},
+	}
+	for _, tt := range tests {
+		if got := OrderspaceStatus(orderspace.Order{Status: tt.status, OrderLines: tt.lines}); got != tt.want {
+			t.Errorf("OrderspaceStatus(%s, %d lines) = %s, want %s", tt.status, len(tt.lines), got, tt.want)
+		}
+	}
+}
+
+func TestWooStatus(t *testing.T) {
+	tests := map[string]Status{
+		"checkout-draft": StatusAwaitingPayment,
+		"pending":        StatusAwaitingPayment,
+		"On-Hold":        StatusAwaitingPayment,
+		"failed":         StatusCancelled,
+		"processing":     StatusReadyToFulfil,
+		"completed":      StatusFulfilled,
+		"cancelled":      StatusCancelled,
+		"refunded":       StatusRefunded,
+		"wc-custom":      StatusUnknown,
+	}
+	for status, want := range tests {
+		if got := WooStatus(status); got != want {
+			t.Errorf("WooStatus(%q) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	for _, s := range Statuses {
+		if got, ok := ParseStatus(string(s)); !ok || got != s {
+			t.Errorf("ParseStatus(%q) = %s, %v", s, got, ok)
+		}
+	}
+	for _, s := range []string{"", "unknown", "Processing"} {
+		if _, ok := ParseStatus(s); ok {
+			t.Errorf("ParseStatus(%q) should fail", s)
+		}
+	}
+}
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		origin, status string
+		want           Status
+		toFulfil       bool
+	}{
+		{Orderspace, "new", StatusNew, true},
+		{Orderspace, "released", StatusReadyToFulfil, true},
+		{Orderspace, "awaiting_payment", StatusAwaitingPayment, false},
+		{WooCommerce, "processing", StatusReadyToFulfil, true},
+		{WooCommerce, "on-hold", StatusAwaitingPayment, false},
+		{WooCommerce, "checkout-draft", StatusAwaitingPayment, false},
+		{WooCommerce, "failed", StatusCancelled, false},
+		{WooCommerce, "completed", StatusFulfilled, false},
+		{"shopify", "new", StatusUnknown, false},
+	}
+	for _, tt := range tests {
+		got := StatusFor(tt.origin, tt.status)
+		if got != tt.want || got.ToFulfil() != tt.toFulfil {
+			t.Errorf("StatusFor(%q, %q) = %s (to fulfil %v), want %s (%v)", tt.origin, tt.status, got, got.ToFulfil(), tt.want, tt.toFulfil)
+		}
+	}
+}
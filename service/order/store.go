@@ -0,0 +1,374 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Address kinds stored in the addresses table
+const (
+	AddressBilling  = "billing"
+	AddressShipping = "shipping"
+)
+
+// SaveOrderspaceOrder upserts an Orderspace order, its customer, addresses and
+// lines, and the stock the order takes from the inventory
+func (s *OrderService) SaveOrderspaceOrder(ctx context.Context, order orderspace.Order) error {
+	raw, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to marshal orderspace order %s: %w", order.ID, err)
+	}
+
+	placedAt := storedPlacedAt(Orderspace, order.ID, time.RFC3339, order.Created)
+
+	var deliveryDate pgtype.Date
+	if parsed, err := time.Parse("2006-01-02", order.DeliveryDate); err == nil {
+		deliveryDate = pgtype.Date{Time: parsed, Valid: true}
+	}
+
+	// Orderspace sends amounts as floats, so each is converted to minor units
+	// before any sums and they add up exactly
+	amounts := &orderAmounts{currency: order.Currency}
+	grossTotal := money.FromFloat(order.GrossTotal, order.Currency)
+	netTotal := money.FromFloat(order.NetTotal, order.Currency)
+	taxTotal := grossTotal
+	amounts.add("tax total", &taxTotal, netTotal.Neg())
+	shippingTotal := money.Zero(order.Currency)
+	for _, line := range order.OrderLines {
+		if line.Shipping {
+			amounts.add("shipping total", &shippingTotal, money.FromFloat(line.SubTotal, order.Currency))
+		}
+	}
+	if amounts.err != nil {
+		return fmt.Errorf("failed to read orderspace order %s: %w", order.ID, amounts.err)
+	}
+
+	return s.withTx(ctx, func(q *db.Queries) error {
+		var customerID pgtype.Int8
+		if order.CustomerID != "" {
+			customer, err := q.UpsertCustomer(ctx, db.UpsertCustomerParams{
+				Origin:      Orderspace,
+				ExternalID:  order.CustomerID,
+				CompanyName: order.CompanyName,
+				ContactName: order.BillingAddress.ContactName,
+				Email:       order.EmailAddresses.Orders,
+				Phone:       order.Phone,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to upsert customer %s: %w", order.CustomerID, err)
+			}
+			customerID = pgtype.Int8{Int64: customer.ID, Valid: true}
+		}
+
+		stored, err := q.UpsertOrder(ctx, db.UpsertOrderParams{
+			Origin:             Orderspace,
+			ExternalID:         order.ID,
+			Number:             strconv.Itoa(order.Number),
+			Status:             order.Status,
+			Lifecycle:          string(OrderspaceStatus(order)),
+			CustomerID:         customerID,
+			Currency:           order.Currency,
+			NetTotalMinor:      netTotal.Amount,
+			TaxTotalMinor:      taxTotal.Amount,
+			ShippingTotalMinor: shippingTotal.Amount,
+			GrossTotalMinor:    grossTotal.Amount,
+			DeliveryDate:       deliveryDate,
+			CustomerNote:       order.CustomerNote,
+			PlacedAt:           placedAt,
+			Raw:                raw,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upsert order %s: %w", order.ID, err)
+		}
+
+		addresses := map[string]orderspace.OrderAddress{
+			AddressBilling:  order.BillingAddress,
+			AddressShipping: order.ShippingAddress,
+		}
+		for kind, address := range addresses {
+			_, err := q.UpsertAddress(ctx, db.UpsertAddressParams{
+				OrderID:     stored.ID,
+				Kind:        kind,
+				CompanyName: address.CompanyName,
+				ContactName: address.ContactName,
+				Line1:       address.Line1,
+				Line2:       address.Line2,
+				City:        address.City,
+				State:       address.State,
+				PostalCode:  address.PostalCode,
+				Country:     address.Country,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to upsert %s address for order %s: %w", kind, order.ID, err)
+			}
+		}
+
+		if err := q.DeleteOrderLines(ctx, stored.ID); err != nil {
+			return fmt.Errorf("failed to clear lines for order %s: %w", order.ID, err)
+		}
+		for _, line := range order.OrderLines {
+			_, err := q.InsertOrderLine(ctx, db.InsertOrderLineParams{
+				OrderID:        stored.ID,
+				ExternalID:     line.ID,
+				Sku:            line.SKU,
+				Name:           line.Name,
+				Options:        line.Options,
+				Quantity:       int32(line.Quantity),
+				UnitPriceMinor: money.FromFloat(line.UnitPrice, order.Currency).Amount,
+				SubTotalMinor:  money.FromFloat(line.SubTotal, order.Currency).Amount,
+				TaxAmountMinor: money.FromFloat(line.TaxAmount, order.Currency).Amount,
+				Dispatched:     int32(line.Dispatched),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to insert line %s for order %s: %w", line.ID, order.ID, err)
+			}
+		}
+
+		return recordOrderStock(ctx, q, stored.ID, placedAt, consumesStock(Orderspace, order.Status), orderspaceStockLines(order))
+	})
+}
+
+// SaveWooOrder upserts a WooCommerce order, its customer, addresses and lines,
+// and the stock the order takes from the inventory
+func (s *OrderService) SaveWooOrder(ctx context.Context, order woocommerce.Order) error {
+	externalID := strconv.Itoa(order.ID)
+
+	raw, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to marshal woocommerce order %d: %w", order.ID, err)
+	}
+
+	// WooCommerce reports dates without an offset; the _gmt variants are UTC
+	placedAt := storedPlacedAt(WooCommerce, externalID, wooDateLayout, order.DateCreatedGMT)
+
+	var modifiedAt pgtype.Timestamptz
+	if parsed, err := time.Parse(wooDateLayout, order.DateModifiedGMT); err == nil {
+		modifiedAt = pgtype.Timestamptz{Time: parsed, Valid: true}
+	}
+
+	// Orders with amounts that can't be read are rejected rather than stored as zero
+	amounts := &orderAmounts{currency: order.Currency}
+	grossTotal := amounts.woo("total", order.Total)
+	taxTotal := amounts.woo("total_tax", order.TotalTax)
+	shippingTotal := amounts.woo("shipping_total", order.ShippingTotal)
+	netTotal := grossTotal
+	amounts.add("net total", &netTotal, taxTotal.Neg())
+	type lineAmounts struct{ unitPrice, subtotal, tax money.Money }
+	lines := make([]lineAmounts, len(order.LineItems))
+	for i, line := range order.LineItems {
+		lines[i].unitPrice = money.Zero(order.Currency)
+		if line.Quantity != 0 {
+			lines[i].unitPrice = amounts.woo("line subtotal", line.Subtotal).Div(line.Quantity)
+		}
+		lines[i].subtotal = amounts.woo("line total", line.Total)
+		lines[i].tax = amounts.woo("line total_tax", line.TotalTax)
+	}
+	if amounts.err != nil {
+		return fmt.Errorf("failed to read woocommerce order %d: %w", order.ID, amounts.err)
+	}
+
+	return s.withTx(ctx, func(q *db.Queries) error {
+		var customerID pgtype.Int8
+		// Guest checkouts have no customer ID, so key them on their email instead
+		customerExternalID := strconv.Itoa(order.CustomerID)
+		if order.CustomerID == 0 {
+			customerExternalID = ""
+			if order.Billing.Email != "" {
+				customerExternalID = "guest:" + strings.ToLower(order.Billing.Email)
+			}
+		}
+		if customerExternalID != "" {
+			customer, err := q.UpsertCustomer(ctx, db.UpsertCustomerParams{
+				Origin:      WooCommerce,
+				ExternalID:  customerExternalID,
+				CompanyName: order.Billing.Company,
+				ContactName: strings.TrimSpace(order.Billing.FirstName + " " + order.Billing.LastName),
+				Email:       order.Billing.Email,
+				Phone:       order.Billing.Phone,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to upsert customer %s: %w", customerExternalID, err)
+			}
+			customerID = pgtype.Int8{Int64: customer.ID, Valid: true}
+		}
+
+		stored, err := q.UpsertOrder(ctx, db.UpsertOrderParams{
+			Origin:             WooCommerce,
+			ExternalID:         externalID,
+			Number:             order.Number,
+			Status:             order.Status,
+			Lifecycle:          string(WooStatus(order.Status)),
+			CustomerID:         customerID,
+			Currency:           order.Currency,
+			NetTotalMinor:      netTotal.Amount,
+			TaxTotalMinor:      taxTotal.Amount,
+			ShippingTotalMinor: shippingTotal.Amount,
+			GrossTotalMinor:    grossTotal.Amount,
+			CustomerNote:       order.CustomerNote,
+			PlacedAt:           placedAt,
+			ModifiedAt:         modifiedAt,
+			Raw:                raw,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upsert order %d: %w", order.ID, err)
+		}
+
+		addresses := map[string]woocommerce.OrderAddress{
+			AddressBilling:  order.Billing,
+			AddressShipping: order.Shipping,
+		}
+		for kind, address := range addresses {
+			_, err := q.UpsertAddress(ctx, db.UpsertAddressParams{
+				OrderID:     stored.ID,
+				Kind:        kind,
+				CompanyName: address.Company,
+				ContactName: strings.TrimSpace(address.FirstName + " " + address.LastName),
+				Line1:       address.Address1,
+				Line2:       address.Address2,
+				City:        address.City,
+				State:       address.State,
+				PostalCode:  address.Postcode,
+				Country:     address.Country,
+				Email:       address.Email,
+				Phone:       address.Phone,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to upsert %s address for order %d: %w", kind, order.ID, err)
+			}
+		}
+
+		if err := q.DeleteOrderLines(ctx, stored.ID); err != nil {
+			return fmt.Errorf("failed to clear lines for order %d: %w", order.ID, err)
+		}
+		for i, line := range order.LineItems {
+			_, err := q.InsertOrderLine(ctx, db.InsertOrderLineParams{
+				OrderID:        stored.ID,
+				ExternalID:     strconv.Itoa(line.ID),
+				Sku:            line.SKU,
+				Name:           line.Name,
+				Options:        wooLineOptions(line),
+				Quantity:       int32(line.Quantity),
+				UnitPriceMinor: lines[i].unitPrice.Amount,
+				SubTotalMinor:  lines[i].subtotal.Amount,
+				TaxAmountMinor: lines[i].tax.Amount,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to insert line %d for order %d: %w", line.ID, order.ID, err)
+			}
+		}
+
+		return recordOrderStock(ctx, q, stored.ID, placedAt, consumesStock(WooCommerce, order.Status), wooStockLines(order))
+	})
+}
+
+// ListStoredOrders returns orders from the local store, newest first. A
+// non-empty status keeps only orders at that point in their lifecycle.
+func (s *OrderService) ListStoredOrders(ctx context.Context, status Status, limit, offset int) ([]Order, error) {
+	var rows []db.Order
+	var err error
+	if status == "" {
+		rows, err = s.Queries.ListOrders(ctx, db.ListOrdersParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+	} else {
+		rows, err = s.Queries.ListOrdersByLifecycle(ctx, db.ListOrdersByLifecycleParams{
+			Lifecycle: string(status),
+			Limit:     int32(limit),
+			Offset:    int32(offset),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored orders: %w", err)
+	}
+
+	orders := make([]Order, 0, len(rows))
+	for _, row := range rows {
+		o, err := s.convertStoredOrder(row)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// convertStoredOrder converts a stored order back into an Order using the raw channel payload
+func (s *OrderService) convertStoredOrder(row db.Order) (Order, error) {
+	switch row.Origin {
+	case Orderspace:
+		var order orderspace.Order
+		if err := json.Unmarshal(row.Raw, &order); err != nil {
+			return Order{}, fmt.Errorf("failed to unmarshal stored orderspace order %s: %w", row.ExternalID, err)
+		}
+		return s.ConvertOrderspaceOrder(order)
+	case WooCommerce:
+		var order woocommerce.Order
+		if err := json.Unmarshal(row.Raw, &order); err != nil {
+			return Order{}, fmt.Errorf("failed to unmarshal stored woocommerce order %s: %w", row.ExternalID, err)
+		}
+		return s.ConvertWooOrder(order)
+	default:
+		return Order{}, fmt.Errorf("unknown origin %q for stored order %d", row.Origin, row.ID)
+	}
+}
+
+// withTx runs fn inside a database transaction, rolling back on error
+func (s *OrderService) withTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := s.DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// storedPlacedAt parses when an order was placed for storing. An unreadable
+// date is stored as NULL and logged rather than failing the save, so one bad
+// order can't stall sync; the order list shows its date as unknown.
+func storedPlacedAt(origin, orderID, layout, value string) pgtype.Timestamptz {
+	placedAt, err := time.Parse(layout, value)
+	if err != nil {
+		slog.Warn("Storing order without a placed date", "origin", origin, "orderID", orderID, "error_message", err)
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: placedAt, Valid: true}
+}
+
+// wooDateLayout is the timestamp format used by the WooCommerce REST API
+const wooDateLayout = "2006-01-02T15:04:05"
+
+// wooLineOptions flattens the visible line item meta (e.g. grind, weight) into a display string
+func wooLineOptions(line woocommerce.OrderLineItem) string {
+	var options []string
+	for _, meta := range line.MetaData {
+		// Keys starting with an underscore are WooCommerce/plugin internals
+		if strings.HasPrefix(meta.Key, "_") {
+			continue
+		}
+		if value, ok := meta.Value.(string); ok && value != "" {
+			options = append(options, meta.Key+": "+value)
+		}
+	}
+	return strings.Join(options, ", ")
+}
@@ -3,6 +3,7 @@ package orderspace
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,8 @@ import (
 	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/dukerupert/paddy-cap/service/transport"
 )
 
 // Client represents an Orderspace REST API client
@@ -18,11 +21,20 @@ type Client struct {
 	ClientID     string
 	ClientSecret string
 	HTTPClient   *http.Client
-	accessToken  string
-	tokenExpiry  time.Time
+	TokenURL     string
+	tokens       *tokenSource
+	retry        *transport.Retry
+	limiter      *transport.RateLimit
 }
 
-// Error represents an Orderspace API error response
+// DefaultTokenURL is the Orderspace OAuth token endpoint
+const DefaultTokenURL = "https://identity.orderspace.com/oauth/token"
+
+// Channel identifies Orderspace in errors returned by the client
+const Channel = "orderspace"
+
+// Error represents an Orderspace API error response.
+// It is returned wrapped in a *transport.Error carrying the HTTP status.
 type Error struct {
 	Message string `json:"message"`
 	Code    int    `json:"code,omitempty"`
@@ -55,6 +67,7 @@ type Response struct {
 	Data       interface{}
 	Pagination *PaginationInfo
 	Headers    http.Header
+	Retries    int // Retries made before this response was received
 }
 
 // RequestOptions holds optional parameters for API requests
@@ -66,70 +79,95 @@ type RequestOptions struct {
 
 // NewClient creates a new Orderspace client
 func NewClient(baseUrl, clientID, clientSecret string) *Client {
-	return &Client{
+	limiter := transport.NewRateLimit(http.DefaultTransport, 0, 0)
+	retry := transport.NewRetry(limiter, transport.DefaultRetryConfig)
+	c := &Client{
 		BaseURL:      baseUrl,
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: retry,
 		},
+		TokenURL: DefaultTokenURL,
+		retry:    retry,
+		limiter:  limiter,
 	}
+	c.tokens = newTokenSource(c.getAccessToken)
+	return c
 }
 
-// SetTimeout sets the HTTP client timeout
+// SetTimeout sets how long each attempt at a request may take. Retries get
+// their own time, so a request can take longer in all.
 func (c *Client) SetTimeout(timeout time.Duration) {
-	c.HTTPClient.Timeout = timeout
+	if c.retry != nil {
+		c.retry.SetAttemptTimeout(timeout)
+	}
+}
+
+// SetRateLimit limits requests to rps per second with the given burst.
+// A non-positive rps removes the limit.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	if c.limiter != nil {
+		c.limiter.SetLimit(rps, burst)
+	}
+}
+
+// RateLimitWait reports how long a request made now would wait for the rate limiter
+func (c *Client) RateLimitWait() time.Duration {
+	if c.limiter == nil {
+		return 0
+	}
+	return c.limiter.Wait()
+}
+
+// Retries reports how many requests have been retried since the client was created
+func (c *Client) Retries() int64 {
+	if c.retry == nil {
+		return 0
+	}
+	return c.retry.Retries()
+}
+
+// SetTokenURL sets the OAuth token endpoint (default is DefaultTokenURL)
+func (c *Client) SetTokenURL(tokenURL string) {
+	c.TokenURL = tokenURL
 }
 
 // getAccessToken obtains a new access token using OAuth2 client credentials flow
-func (c *Client) getAccessToken() error {
-	tokenURL := "https://identity.orderspace.com/oauth/token"
-	
+func (c *Client) getAccessToken(ctx context.Context) (*TokenResponse, error) {
 	data := url.Values{}
 	data.Set("client_id", c.ClientID)
 	data.Set("client_secret", c.ClientSecret)
 	data.Set("grant_type", "client_credentials")
 	
-	req, err := http.NewRequest("POST", tokenURL, bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.TokenURL, bytes.NewBufferString(data.Encode()))
 	if err != nil {
-		return fmt.Errorf("failed to create token request: %w", err)
+		return nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to get access token: %w", err)
+		return nil, fmt.Errorf("failed to get access token: %w", transport.NewError(Channel, "POST", c.TokenURL, nil, nil, err))
 	}
 	defer resp.Body.Close()
 	
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read token response: %w", err)
+		return nil, fmt.Errorf("failed to read token response: %w", err)
 	}
 	
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("token request failed: %w", transport.NewError(Channel, "POST", c.TokenURL, resp, body, nil))
 	}
 	
 	var tokenResp TokenResponse
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return fmt.Errorf("failed to parse token response: %w", err)
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
 	}
 	
-	c.accessToken = tokenResp.AccessToken
-	// Set expiry to be 30 seconds before actual expiry to allow for refresh
-	c.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn-30) * time.Second)
-	
-	return nil
-}
-
-// ensureValidToken ensures we have a valid access token
-func (c *Client) ensureValidToken() error {
-	if c.accessToken == "" || time.Now().After(c.tokenExpiry) {
-		return c.getAccessToken()
-	}
-	return nil
+	return &tokenResp, nil
 }
 
 // buildURL constructs the full API endpoint URL
@@ -158,72 +196,55 @@ func (c *Client) buildURL(endpoint string, options *RequestOptions) string {
 	return u.String()
 }
 
-// addAuth adds authentication to the request
-func (c *Client) addAuth(req *http.Request) error {
-	if err := c.ensureValidToken(); err != nil {
-		return err
+// addAuth adds authentication to the request and returns the token used
+func (c *Client) addAuth(req *http.Request) (string, error) {
+	token, err := c.tokens.Token(req.Context())
+	if err != nil {
+		return "", err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	return nil
+	req.Header.Set("Authorization", "Bearer "+token)
+	return token, nil
 }
 
 // makeRequest performs the HTTP request and handles the response
-func (c *Client) makeRequest(method, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
 	url := c.buildURL(endpoint, options)
+	ctx, retries := transport.CountRetries(ctx)
 	
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
-	}
-	
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	
-	// Add authentication
-	if err := c.addAuth(req); err != nil {
-		return nil, fmt.Errorf("failed to add authentication: %w", err)
-	}
-	
-	// Make the request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
 	
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
+	resp, respBody, err := c.do(ctx, method, endpoint, url, jsonBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 	
 	// Handle error responses
 	if resp.StatusCode >= 400 {
-		var apiError Error
-		if err := json.Unmarshal(respBody, &apiError); err != nil {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		var apiErr error
+		var osError Error
+		if err := json.Unmarshal(respBody, &osError); err == nil && osError.Message != "" {
+			osError.Code = resp.StatusCode
+			apiErr = &osError
 		}
-		apiError.Code = resp.StatusCode
-		return nil, &apiError
+		return nil, transport.NewError(Channel, method, endpoint, resp, respBody, apiErr)
 	}
 	
 	// Create response wrapper
 	response := &Response{
-		Headers: resp.Header,
-		Pagination: &PaginationInfo{
-			// Orderspace uses cursor-based pagination
-			// We'll need to determine HasMore from the response data
-		},
+		Headers:    resp.Header,
+		Pagination: &PaginationInfo{},
+		Retries:    retries(),
+	}
+	if options != nil {
+		response.Pagination.Limit = options.Limit
+		response.Pagination.StartingAfter = options.StartingAfter
 	}
 	
 	// Parse JSON response into Data field
@@ -234,12 +255,17 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 		}
 		response.Data = data
 		
-		// For pagination, check if we got a full page (indicating there might be more)
-		if options != nil && options.Limit > 0 {
-			if dataSlice, ok := data.([]interface{}); ok {
-				response.Pagination.HasMore = len(dataSlice) == options.Limit
-				response.Pagination.Limit = options.Limit
-				response.Pagination.StartingAfter = options.StartingAfter
+		// Orderspace uses cursor-based pagination and reports whether another
+		// page exists in the "has_more" field of list responses
+		switch v := data.(type) {
+		case map[string]interface{}:
+			if hasMore, ok := v["has_more"].(bool); ok {
+				response.Pagination.HasMore = hasMore
+			}
+		case []interface{}:
+			// Bare arrays carry no signal, so a full page is the best guess
+			if options != nil && options.Limit > 0 {
+				response.Pagination.HasMore = len(v) == options.Limit
 			}
 		}
 	}
@@ -247,42 +273,89 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 	return response, nil
 }
 
+// do sends the request with a bearer token and returns the response and its body.
+// A 401 means the cached token was revoked or expired early, so it is dropped
+// and the request is retried once with a freshly issued token.
+func (c *Client) do(ctx context.Context, method, endpoint, url string, jsonBody []byte) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		var reqBody io.Reader
+		if jsonBody != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
+		
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		
+		// Set headers
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		
+		// Add authentication
+		token, err := c.addAuth(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add authentication: %w", err)
+		}
+		
+		// Make the request
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, nil, transport.NewError(Channel, method, endpoint, nil, nil, err)
+		}
+		
+		// Read response body
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			c.tokens.Invalidate(token)
+			continue
+		}
+		
+		return resp, respBody, nil
+	}
+}
+
 // GET performs a GET request
-func (c *Client) GET(endpoint string, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("GET", endpoint, nil, options)
+func (c *Client) GET(ctx context.Context, endpoint string, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "GET", endpoint, nil, options)
 }
 
 // POST performs a POST request
-func (c *Client) POST(endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("POST", endpoint, body, options)
+func (c *Client) POST(ctx context.Context, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "POST", endpoint, body, options)
 }
 
 // PUT performs a PUT request
-func (c *Client) PUT(endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("PUT", endpoint, body, options)
+func (c *Client) PUT(ctx context.Context, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "PUT", endpoint, body, options)
 }
 
 // DELETE performs a DELETE request
-func (c *Client) DELETE(endpoint string, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("DELETE", endpoint, nil, options)
+func (c *Client) DELETE(ctx context.Context, endpoint string, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "DELETE", endpoint, nil, options)
 }
 
 // PATCH performs a PATCH request
-func (c *Client) PATCH(endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("PATCH", endpoint, body, options)
+func (c *Client) PATCH(ctx context.Context, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "PATCH", endpoint, body, options)
 }
 
 // GetWithPagination is a helper method for paginated GET requests
-func (c *Client) GetWithPagination(endpoint string, limit int, startingAfter string, params map[string]string) (*Response, error) {
+func (c *Client) GetWithPagination(ctx context.Context, endpoint string, limit int, startingAfter string, params map[string]string) (*Response, error) {
 	options := &RequestOptions{
 		Limit:         limit,
 		StartingAfter: startingAfter,
 		Params:        params,
 	}
-	return c.GET(endpoint, options)
+	return c.GET(ctx, endpoint, options)
 }
 
 // GetNextPage gets the next page of results using cursor pagination
-func (c *Client) GetNextPage(endpoint string, lastID string, limit int, params map[string]string) (*Response, error) {
-	return c.GetWithPagination(endpoint, limit, lastID, params)
+func (c *Client) GetNextPage(ctx context.Context, endpoint string, lastID string, limit int, params map[string]string) (*Response, error) {
+	return c.GetWithPagination(ctx, endpoint, limit, lastID, params)
 }
\ No newline at end of file
@@ -0,0 +1,535 @@
+package orderspace_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/transport"
+)
+
+func newTestClient(t *testing.T) (*orderspacetest.Server, *orderspace.Client) {
+	t.Helper()
+	srv := orderspacetest.NewServer(orderspacetest.Orders(), orderspacetest.Customers(), orderspacetest.Products())
+	t.Cleanup(srv.Close)
+	return srv, srv.NewClient()
+}
+
+func TestListOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *orderspace.OrderListOptions
+		wantIDs  []string
+		wantMore bool
+	}{
+		{
+			name:    "no options",
+			options: nil,
+			wantIDs: []string{"or_3kq9Zt1x", "or_7Pm4Yc2r", "or_1Aa0Bb9c"},
+		},
+		{
+			name:     "first page",
+			options:  &orderspace.OrderListOptions{Limit: 2},
+			wantIDs:  []string{"or_3kq9Zt1x", "or_7Pm4Yc2r"},
+			wantMore: true,
+		},
+		{
+			name:    "second page",
+			options: &orderspace.OrderListOptions{Limit: 2, StartingAfter: "or_7Pm4Yc2r"},
+			wantIDs: []string{"or_1Aa0Bb9c"},
+		},
+		{
+			name:    "exact page is not reported as having more",
+			options: &orderspace.OrderListOptions{Limit: 3},
+			wantIDs: []string{"or_3kq9Zt1x", "or_7Pm4Yc2r", "or_1Aa0Bb9c"},
+		},
+		{
+			name:    "filter by customer",
+			options: &orderspace.OrderListOptions{CustomerID: "cu_8Hn2Lw0p"},
+			wantIDs: []string{"or_3kq9Zt1x", "or_1Aa0Bb9c"},
+		},
+		{
+			name:    "filter by status",
+			options: &orderspace.OrderListOptions{Status: "released"},
+			wantIDs: []string{"or_7Pm4Yc2r"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newTestClient(t)
+
+			res, err := c.ListOrders(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("ListOrders: %v", err)
+			}
+
+			var got []string
+			for _, o := range res.Orders {
+				got = append(got, o.ID)
+			}
+			if !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("order IDs = %v, want %v", got, tt.wantIDs)
+			}
+			if res.Pagination.HasMore != tt.wantMore {
+				t.Errorf("HasMore = %v, want %v", res.Pagination.HasMore, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestAllOrders(t *testing.T) {
+	_, c := newTestClient(t)
+
+	var got []string
+	for o, err := range c.AllOrders(context.Background(), &orderspace.OrderListOptions{Limit: 1}) {
+		if err != nil {
+			t.Fatalf("AllOrders: %v", err)
+		}
+		got = append(got, o.ID)
+	}
+
+	want := []string{"or_3kq9Zt1x", "or_7Pm4Yc2r", "or_1Aa0Bb9c"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order IDs = %v, want %v", got, want)
+	}
+}
+
+func TestAllOrdersStopsWhenCallerBreaks(t *testing.T) {
+	_, c := newTestClient(t)
+
+	count := 0
+	for _, err := range c.AllOrders(context.Background(), &orderspace.OrderListOptions{Limit: 1}) {
+		if err != nil {
+			t.Fatalf("AllOrders: %v", err)
+		}
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("iterated %d orders after break, want 1", count)
+	}
+}
+
+func TestGetOrder(t *testing.T) {
+	_, c := newTestClient(t)
+
+	order, err := c.GetOrder(context.Background(), "or_7Pm4Yc2r")
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.Number != 1042 {
+		t.Errorf("Number = %d, want 1042", order.Number)
+	}
+	if len(order.OrderLines) != 1 || order.OrderLines[0].Dispatched != 10 {
+		t.Errorf("OrderLines = %+v, want one line with 10 dispatched", order.OrderLines)
+	}
+}
+
+func TestGetOrderNotFound(t *testing.T) {
+	_, c := newTestClient(t)
+
+	_, err := c.GetOrder(context.Background(), "or_missing")
+	var apiErr *orderspace.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetOrder error = %v, want *orderspace.Error", err)
+	}
+	if apiErr.Code != http.StatusNotFound {
+		t.Errorf("Code = %d, want %d", apiErr.Code, http.StatusNotFound)
+	}
+
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("GetOrder error = %v, want *transport.Error", err)
+	}
+	if reqErr.StatusCode != http.StatusNotFound || reqErr.Channel != orderspace.Channel || reqErr.Endpoint != "orders/or_missing" {
+		t.Errorf("error = %+v, want 404 from orderspace orders/or_missing", reqErr)
+	}
+	if reqErr.Retryable {
+		t.Error("404 reported as retryable")
+	}
+}
+
+func TestTokenIsReused(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := c.ListOrders(ctx, nil); err != nil {
+			t.Fatalf("ListOrders: %v", err)
+		}
+	}
+	if n := srv.TokenRequests(); n != 1 {
+		t.Errorf("token requests = %d, want 1", n)
+	}
+}
+
+func TestConcurrentRequestsShareTokenRefresh(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := c.GetOrder(ctx, "or_3kq9Zt1x"); err != nil {
+				t.Errorf("GetOrder: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if n := srv.TokenRequests(); n != 1 {
+		t.Errorf("token requests = %d, want 1", n)
+	}
+}
+
+func TestRevokedTokenIsRefreshedOnce(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := c.ListOrders(ctx, nil); err != nil {
+		t.Fatalf("ListOrders: %v", err)
+	}
+	srv.RevokeTokens()
+	if _, err := c.ListOrders(ctx, nil); err != nil {
+		t.Fatalf("ListOrders after revoke: %v", err)
+	}
+	if n := srv.TokenRequests(); n != 2 {
+		t.Errorf("token requests = %d, want 2", n)
+	}
+}
+
+func TestInvalidCredentials(t *testing.T) {
+	srv, _ := newTestClient(t)
+	c := orderspace.NewClient(srv.URL, orderspacetest.ClientID, "wrong-secret")
+	c.SetTokenURL(srv.URL + "/oauth/token")
+
+	_, err := c.ListOrders(context.Background(), nil)
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("ListOrders error = %v, want *transport.Error", err)
+	}
+	if reqErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want %d", reqErr.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestListCustomers(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *orderspace.CustomerListOptions
+		wantIDs  []string
+		wantMore bool
+	}{
+		{
+			name:    "no options",
+			options: nil,
+			wantIDs: []string{"cu_8Hn2Lw0p", "cu_2Xb7Qe9k", "cu_5Kd1Rr3m"},
+		},
+		{
+			name:     "first page",
+			options:  &orderspace.CustomerListOptions{Limit: 2},
+			wantIDs:  []string{"cu_8Hn2Lw0p", "cu_2Xb7Qe9k"},
+			wantMore: true,
+		},
+		{
+			name:    "second page",
+			options: &orderspace.CustomerListOptions{Limit: 2, StartingAfter: "cu_2Xb7Qe9k"},
+			wantIDs: []string{"cu_5Kd1Rr3m"},
+		},
+		{
+			name:    "filter by status",
+			options: &orderspace.CustomerListOptions{Status: "inactive"},
+			wantIDs: []string{"cu_5Kd1Rr3m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newTestClient(t)
+
+			res, err := c.ListCustomers(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("ListCustomers: %v", err)
+			}
+
+			var got []string
+			for _, cu := range res.Customers {
+				got = append(got, cu.ID)
+			}
+			if !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("customer IDs = %v, want %v", got, tt.wantIDs)
+			}
+			if res.Pagination.HasMore != tt.wantMore {
+				t.Errorf("HasMore = %v, want %v", res.Pagination.HasMore, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestAllCustomers(t *testing.T) {
+	_, c := newTestClient(t)
+
+	var got []string
+	for cu, err := range c.AllCustomers(context.Background(), &orderspace.CustomerListOptions{Limit: 1}) {
+		if err != nil {
+			t.Fatalf("AllCustomers: %v", err)
+		}
+		got = append(got, cu.ID)
+	}
+
+	want := []string{"cu_8Hn2Lw0p", "cu_2Xb7Qe9k", "cu_5Kd1Rr3m"}
+	if !slices.Equal(got, want) {
+		t.Errorf("customer IDs = %v, want %v", got, want)
+	}
+}
+
+func TestGetCustomer(t *testing.T) {
+	_, c := newTestClient(t)
+
+	customer, err := c.GetCustomer(context.Background(), "cu_8Hn2Lw0p")
+	if err != nil {
+		t.Fatalf("GetCustomer: %v", err)
+	}
+	if customer.CompanyName != "Bean There Cafe" {
+		t.Errorf("CompanyName = %q, want %q", customer.CompanyName, "Bean There Cafe")
+	}
+	if len(customer.Buyers) != 2 || len(customer.Addresses) != 1 {
+		t.Errorf("got %d buyers and %d addresses, want 2 and 1", len(customer.Buyers), len(customer.Addresses))
+	}
+
+	_, err = c.GetCustomer(context.Background(), "cu_missing")
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetCustomer(cu_missing) error = %v, want 404", err)
+	}
+}
+
+func TestCreateAndUpdateCustomer(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := c.CreateCustomer(ctx, &orderspace.Customer{
+		CompanyName: "Roast Office",
+		Buyers:      []orderspace.Buyer{{Name: "Kit Lowe", EmailAddress: "kit@roastoffice.example"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateCustomer: %v", err)
+	}
+	if created.ID == "" || created.Status != "active" {
+		t.Errorf("created customer = %+v, want an ID and active status", created)
+	}
+
+	created.Phone = "01632 960000"
+	updated, err := c.UpdateCustomer(ctx, created.ID, created)
+	if err != nil {
+		t.Fatalf("UpdateCustomer: %v", err)
+	}
+	if updated.Phone != "01632 960000" {
+		t.Errorf("Phone = %q, want %q", updated.Phone, "01632 960000")
+	}
+
+	got, err := c.GetCustomer(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetCustomer: %v", err)
+	}
+	if got.Phone != updated.Phone || len(got.Buyers) != 1 {
+		t.Errorf("stored customer = %+v, want updated phone and one buyer", got)
+	}
+
+	if _, err := c.CreateCustomer(ctx, &orderspace.Customer{}); err == nil {
+		t.Error("CreateCustomer without company name succeeded, want error")
+	}
+}
+
+func TestListProducts(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	var got []string
+	for p, err := range c.AllProducts(ctx, &orderspace.ProductListOptions{Limit: 1}) {
+		if err != nil {
+			t.Fatalf("AllProducts: %v", err)
+		}
+		got = append(got, p.ID)
+	}
+	if want := []string{"pr_4Ka9Tm2x", "pr_9Hs5Vc1j", "pr_2Wd6Nk7q"}; !slices.Equal(got, want) {
+		t.Errorf("product IDs = %v, want %v", got, want)
+	}
+
+	res, err := c.ListProducts(ctx, &orderspace.ProductListOptions{Active: "true"})
+	if err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if len(res.Products) != 2 {
+		t.Errorf("got %d active products, want 2", len(res.Products))
+	}
+}
+
+func TestGetProduct(t *testing.T) {
+	_, c := newTestClient(t)
+
+	product, err := c.GetProduct(context.Background(), "pr_4Ka9Tm2x")
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if len(product.Variants) != 2 {
+		t.Fatalf("got %d variants, want 2", len(product.Variants))
+	}
+	v := product.Variants[1]
+	if v.SKU != "ESP-1KG-WB" || v.Options["Size"] != "1kg" || v.RRP != 32 {
+		t.Errorf("variant = %+v, want ESP-1KG-WB, 1kg, RRP 32", v)
+	}
+	if len(v.PriceListPrices) != 1 || v.PriceListPrices[0].UnitPrice != 17 {
+		t.Errorf("PriceListPrices = %+v, want one price of 17", v.PriceListPrices)
+	}
+
+	_, err = c.GetProduct(context.Background(), "pr_missing")
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetProduct(pr_missing) error = %v, want 404", err)
+	}
+}
+
+func TestListPriceLists(t *testing.T) {
+	_, c := newTestClient(t)
+
+	priceLists, err := c.ListPriceLists(context.Background())
+	if err != nil {
+		t.Fatalf("ListPriceLists: %v", err)
+	}
+	if len(priceLists) != 2 || !priceLists[0].Default || priceLists[1].Name != "Trade Plus" {
+		t.Errorf("price lists = %+v, want default Standard and Trade Plus", priceLists)
+	}
+}
+
+func TestSetVariantStock(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	variant, err := c.SetVariantStock(ctx, "va_6Rt2Wb8n", 12)
+	if err != nil {
+		t.Fatalf("SetVariantStock: %v", err)
+	}
+	if variant.StockLevel == nil || *variant.StockLevel != 12 {
+		t.Errorf("StockLevel = %v, want 12", variant.StockLevel)
+	}
+
+	product, err := c.GetProduct(ctx, "pr_4Ka9Tm2x")
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if got := product.Variants[1].StockLevel; got == nil || *got != 12 {
+		t.Errorf("stored StockLevel = %v, want 12", got)
+	}
+
+	_, err = c.SetVariantStock(ctx, "va_missing", 1)
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("SetVariantStock(va_missing) error = %v, want 404", err)
+	}
+}
+
+func TestCreateDispatch(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	dispatch, err := c.CreateDispatch(ctx, &orderspace.Dispatch{
+		OrderID:        "or_7Pm4Yc2r",
+		Courier:        "Royal Mail",
+		TrackingNumber: "RM123456789GB",
+		DispatchLines:  []orderspace.DispatchLine{{OrderLineID: "ol_3", Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("CreateDispatch: %v", err)
+	}
+	if dispatch.ID == "" || dispatch.Created == "" || dispatch.TrackingNumber != "RM123456789GB" {
+		t.Errorf("dispatch = %+v, want an ID, created time and the tracking number", dispatch)
+	}
+	if got := len(srv.Dispatches()); got != 1 {
+		t.Errorf("server has %d dispatches, want 1", got)
+	}
+
+	order, err := c.GetOrder(ctx, "or_7Pm4Yc2r")
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got := order.OrderLines[0].Dispatched; got != 14 || order.Status != "released" {
+		t.Errorf("after partial dispatch: dispatched = %d, status = %q, want 14 and released", got, order.Status)
+	}
+
+	if _, err := c.CreateDispatch(ctx, &orderspace.Dispatch{
+		OrderID:       "or_7Pm4Yc2r",
+		DispatchLines: []orderspace.DispatchLine{{OrderLineID: "ol_3", Quantity: 6}},
+	}); err != nil {
+		t.Fatalf("CreateDispatch of the rest: %v", err)
+	}
+	if order, _ = c.GetOrder(ctx, "or_7Pm4Yc2r"); order.Status != "fulfilled" {
+		t.Errorf("status after dispatching everything = %q, want fulfilled", order.Status)
+	}
+
+	_, err = c.CreateDispatch(ctx, &orderspace.Dispatch{
+		OrderID:       "or_7Pm4Yc2r",
+		DispatchLines: []orderspace.DispatchLine{{OrderLineID: "ol_3", Quantity: 1}},
+	})
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("over-dispatching error = %v, want 422", err)
+	}
+}
+
+func TestCreateOrder(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	order, err := c.CreateOrder(ctx, &orderspace.NewOrder{
+		CustomerID:   "cu_2Xb7Qe9k",
+		DeliveryDate: "2026-10-20",
+		CustomerNote: "Back door please",
+		OrderLines: []orderspace.NewOrderLine{
+			{SKU: "ESP-1KG-WB", Quantity: 3},
+			{SKU: "FLT-250-FL", Quantity: 2, UnitPrice: 5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if order.ID == "" || order.Number != 1044 || order.Status != "new" || order.CompanyName != "Grind & Gather" {
+		t.Errorf("order = %+v, want a new order #1044 for Grind & Gather", order)
+	}
+	if len(order.OrderLines) != 2 || order.OrderLines[0].UnitPrice != 18.5 || order.NetTotal != 65.5 {
+		t.Errorf("lines = %+v, net = %v, want variant price then the given price, totalling 65.5", order.OrderLines, order.NetTotal)
+	}
+	if order.ShippingAddress.City != "Bath" || order.DeliveryDate != "2026-10-20" {
+		t.Errorf("shipping to %q on %q, want the customer's Bath address on 2026-10-20", order.ShippingAddress.City, order.DeliveryDate)
+	}
+
+	got, err := c.GetOrder(ctx, order.ID)
+	if err != nil || got.Number != 1044 {
+		t.Errorf("GetOrder(%s) = %+v, %v, want the created order", order.ID, got, err)
+	}
+
+	tests := []struct {
+		name  string
+		order orderspace.NewOrder
+	}{
+		{"unknown customer", orderspace.NewOrder{CustomerID: "cu_missing", OrderLines: []orderspace.NewOrderLine{{SKU: "ESP-250-WB", Quantity: 1}}}},
+		{"no lines", orderspace.NewOrder{CustomerID: "cu_2Xb7Qe9k"}},
+		{"inactive product", orderspace.NewOrder{CustomerID: "cu_2Xb7Qe9k", OrderLines: []orderspace.NewOrderLine{{SKU: "DEC-1KG-WB", Quantity: 1}}}},
+		{"zero quantity", orderspace.NewOrder{CustomerID: "cu_2Xb7Qe9k", OrderLines: []orderspace.NewOrderLine{{SKU: "ESP-250-WB"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.CreateOrder(ctx, &tt.order)
+			var reqErr *transport.Error
+			if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("err = %v, want 422", err)
+			}
+		})
+	}
+}
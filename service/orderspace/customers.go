@@ -0,0 +1,186 @@
+package orderspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// Customer represents an Orderspace wholesale customer
+type Customer struct {
+	ID                  string              `json:"id,omitempty"`
+	CompanyName         string              `json:"company_name"`
+	Created             string              `json:"created,omitempty"`
+	Status              string              `json:"status,omitempty"`
+	Reference           string              `json:"reference,omitempty"`
+	InternalNote        string              `json:"internal_note,omitempty"`
+	Buyers              []Buyer             `json:"buyers,omitempty"`
+	Phone               string              `json:"phone,omitempty"`
+	EmailAddresses      OrderEmailAddresses `json:"email_addresses"`
+	TaxNumber           string              `json:"tax_number,omitempty"`
+	TaxRateID           string              `json:"tax_rate_id,omitempty"`
+	Addresses           []OrderAddress      `json:"addresses,omitempty"`
+	MinimumSpend        float64             `json:"minimum_spend,omitempty"`
+	PaymentTermsID      string              `json:"payment_terms_id,omitempty"`
+	CustomerGroupID     string              `json:"customer_group_id,omitempty"`
+	PriceListID         string              `json:"price_list_id,omitempty"`
+	DefaultShippingType string              `json:"default_shipping_type,omitempty"`
+}
+
+// Buyer represents a person allowed to place orders on behalf of a customer
+type Buyer struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"email_address"`
+}
+
+// CustomersResponse represents the response when fetching multiple customers
+type CustomersResponse struct {
+	Customers  []Customer
+	Pagination *PaginationInfo
+	Headers    http.Header
+}
+
+// CustomerListOptions holds filtering options for listing customers
+type CustomerListOptions struct {
+	// Pagination
+	Limit         int
+	StartingAfter string
+
+	// Filtering
+	Status       string // Customer status filter
+	CreatedSince string // Filter customers created since this date (ISO 8601)
+	UpdatedSince string // Filter customers updated since this date (ISO 8601)
+
+	// Additional custom parameters
+	Params map[string]string
+}
+
+// ListCustomers retrieves customers with optional filtering
+func (c *Client) ListCustomers(ctx context.Context, options *CustomerListOptions) (*CustomersResponse, error) {
+	params := make(map[string]string)
+	requestOptions := &RequestOptions{
+		Params: params,
+	}
+
+	if options != nil {
+		// Set pagination
+		requestOptions.Limit = options.Limit
+		requestOptions.StartingAfter = options.StartingAfter
+
+		// Set filtering parameters
+		if options.Status != "" {
+			params["status"] = options.Status
+		}
+		if options.CreatedSince != "" {
+			params["created_since"] = options.CreatedSince
+		}
+		if options.UpdatedSince != "" {
+			params["updated_since"] = options.UpdatedSince
+		}
+
+		// Add any additional custom parameters
+		for key, value := range options.Params {
+			params[key] = value
+		}
+	}
+
+	response, err := c.GET(ctx, "customers", requestOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrappedResponse struct {
+		Customers []Customer `json:"customers"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal customers: %w", err)
+	}
+
+	return &CustomersResponse{
+		Customers:  wrappedResponse.Customers,
+		Pagination: response.Pagination,
+		Headers:    response.Headers,
+	}, nil
+}
+
+// AllCustomers iterates over every customer matching options, following the
+// starting_after cursor until Orderspace reports no more results
+func (c *Client) AllCustomers(ctx context.Context, options *CustomerListOptions) iter.Seq2[Customer, error] {
+	opts := CustomerListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.StartingAfter, func(startingAfter string) ([]Customer, string, bool, error) {
+		opts.StartingAfter = startingAfter
+		res, err := c.ListCustomers(ctx, &opts)
+		if err != nil {
+			return nil, "", false, err
+		}
+		next := ""
+		if len(res.Customers) > 0 {
+			next = res.Customers[len(res.Customers)-1].ID
+		}
+		return res.Customers, next, res.Pagination.HasMore, nil
+	})
+}
+
+// GetCustomer retrieves a single customer by ID
+func (c *Client) GetCustomer(ctx context.Context, customerID string) (*Customer, error) {
+	response, err := c.GET(ctx, fmt.Sprintf("customers/%s", customerID), nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCustomer(response)
+}
+
+// CreateCustomer creates a new customer and returns it as stored by Orderspace
+func (c *Client) CreateCustomer(ctx context.Context, customer *Customer) (*Customer, error) {
+	body := map[string]*Customer{"customer": customer}
+	response, err := c.POST(ctx, "customers", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCustomer(response)
+}
+
+// UpdateCustomer replaces the details of an existing customer
+func (c *Client) UpdateCustomer(ctx context.Context, customerID string, customer *Customer) (*Customer, error) {
+	body := map[string]*Customer{"customer": customer}
+	response, err := c.PUT(ctx, fmt.Sprintf("customers/%s", customerID), body, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCustomer(response)
+}
+
+// decodeCustomer unwraps the "customer" object Orderspace returns for single customers
+func decodeCustomer(response *Response) (*Customer, error) {
+	if response.Data == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+	var wrappedResponse struct {
+		Customer Customer `json:"customer"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal customer: %w", err)
+	}
+	return &wrappedResponse.Customer, nil
+}
+
+// decodeData converts the generic JSON held in Response.Data into v
+func decodeData(data interface{}, v any) error {
+	if data == nil {
+		return nil
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response data: %w", err)
+	}
+	return json.Unmarshal(jsonData, v)
+}
@@ -0,0 +1,45 @@
+package orderspace
+
+import (
+	"context"
+	"fmt"
+)
+
+// Dispatch is a shipment of some or all of the goods on an order
+type Dispatch struct {
+	ID             string         `json:"id,omitempty"`
+	OrderID        string         `json:"order_id"`
+	Created        string         `json:"created,omitempty"`
+	Courier        string         `json:"courier,omitempty"`
+	TrackingNumber string         `json:"tracking_number,omitempty"`
+	Note           string         `json:"note,omitempty"`
+	DispatchLines  []DispatchLine `json:"dispatch_lines"`
+}
+
+// DispatchLine is the quantity of one order line sent in a dispatch
+type DispatchLine struct {
+	OrderLineID string `json:"order_line_id"`
+	Quantity    int    `json:"quantity"`
+}
+
+// CreateDispatch records goods as sent and returns the dispatch as stored by
+// Orderspace. The order's lines count the quantities as dispatched, and the
+// order is fulfilled once every line has been sent.
+func (c *Client) CreateDispatch(ctx context.Context, dispatch *Dispatch) (*Dispatch, error) {
+	body := map[string]*Dispatch{"dispatch": dispatch}
+	response, err := c.POST(ctx, "dispatches", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+
+	var wrappedResponse struct {
+		Dispatch Dispatch `json:"dispatch"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dispatch: %w", err)
+	}
+	return &wrappedResponse.Dispatch, nil
+}
@@ -1,8 +1,10 @@
 package orderspace
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"log/slog"
 	"net/http"
 )
@@ -106,7 +108,7 @@ type OrderListOptions struct {
 }
 
 // ListOrders retrieves orders with optional filtering
-func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error) {
+func (c *Client) ListOrders(ctx context.Context, options *OrderListOptions) (*OrdersResponse, error) {
 	params := make(map[string]string)
 	requestOptions := &RequestOptions{
 		Params: params,
@@ -143,7 +145,7 @@ func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error)
 		}
 	}
 
-	response, err := c.GET("orders", requestOptions)
+	response, err := c.GET(ctx, "orders", requestOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -177,14 +179,39 @@ func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error)
 	}, nil
 }
 
+// AllOrders iterates over every order matching options, following the
+// starting_after cursor until Orderspace reports no more results
+func (c *Client) AllOrders(ctx context.Context, options *OrderListOptions) iter.Seq2[Order, error] {
+	opts := OrderListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.StartingAfter, func(startingAfter string) ([]Order, string, bool, error) {
+		opts.StartingAfter = startingAfter
+		res, err := c.ListOrders(ctx, &opts)
+		if err != nil {
+			return nil, "", false, err
+		}
+		next := ""
+		if len(res.Orders) > 0 {
+			next = res.Orders[len(res.Orders)-1].ID
+		}
+		return res.Orders, next, res.Pagination.HasMore, nil
+	})
+}
+
 // GetOrder retrieves a single order by ID
-func (c *Client) GetOrder(orderID string) (*Order, error) {
+func (c *Client) GetOrder(ctx context.Context, orderID string) (*Order, error) {
 	slog.Info("GetOrder called", "orderID", orderID)
 
 	endpoint := fmt.Sprintf("orders/%s", orderID)
 	slog.Debug("Making GET request", "endpoint", endpoint)
 
-	response, err := c.GET(endpoint, nil)
+	response, err := c.GET(ctx, endpoint, nil)
 	if err != nil {
 		slog.Error("GET request failed", "endpoint", endpoint, "error", err)
 		return nil, err
@@ -218,59 +245,100 @@ func (c *Client) GetOrder(orderID string) (*Order, error) {
 	return &wrappedResponse.Order, nil
 }
 
+// NewOrder is an order to create for a customer. Lines are matched to product
+// variants by SKU.
+type NewOrder struct {
+	CustomerID       string         `json:"customer_id"`
+	DeliveryDate     string         `json:"delivery_date,omitempty"` // YYYY-MM-DD
+	CustomerPONumber string         `json:"customer_po_number,omitempty"`
+	CustomerNote     string         `json:"customer_note,omitempty"`
+	InternalNote     string         `json:"internal_note,omitempty"`
+	ShippingAddress  *OrderAddress  `json:"shipping_address,omitempty"` // defaults to the customer's address
+	BillingAddress   *OrderAddress  `json:"billing_address,omitempty"`
+	OrderLines       []NewOrderLine `json:"order_lines"`
+}
+
+// NewOrderLine is a quantity of one variant. A zero UnitPrice uses the
+// customer's price list.
+type NewOrderLine struct {
+	SKU       string  `json:"sku"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price,omitempty"`
+}
+
+// CreateOrder places an order and returns it as stored by Orderspace
+func (c *Client) CreateOrder(ctx context.Context, order *NewOrder) (*Order, error) {
+	body := map[string]*NewOrder{"order": order}
+	response, err := c.POST(ctx, "orders", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+
+	var wrappedResponse struct {
+		Order Order `json:"order"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+	return &wrappedResponse.Order, nil
+}
+
 // Helper methods for common order queries
 
 // GetAllOrders retrieves orders with basic pagination
-func (c *Client) GetAllOrders(limit int, startingAfter string) (*OrdersResponse, error) {
+func (c *Client) GetAllOrders(ctx context.Context, limit int, startingAfter string) (*OrdersResponse, error) {
 	options := &OrderListOptions{
 		Limit:         limit,
 		StartingAfter: startingAfter,
 	}
-	return c.ListOrders(options)
+	return c.ListOrders(ctx, options)
 }
 
 // GetOrdersByStatus retrieves orders filtered by status
-func (c *Client) GetOrdersByStatus(status string, limit int, startingAfter string) (*OrdersResponse, error) {
+func (c *Client) GetOrdersByStatus(ctx context.Context, status string, limit int, startingAfter string) (*OrdersResponse, error) {
 	options := &OrderListOptions{
 		Status:        status,
 		Limit:         limit,
 		StartingAfter: startingAfter,
 	}
-	return c.ListOrders(options)
+	return c.ListOrders(ctx, options)
 }
 
 // GetOrdersByCustomer retrieves orders for a specific customer
-func (c *Client) GetOrdersByCustomer(customerID string, limit int, startingAfter string) (*OrdersResponse, error) {
+func (c *Client) GetOrdersByCustomer(ctx context.Context, customerID string, limit int, startingAfter string) (*OrdersResponse, error) {
 	options := &OrderListOptions{
 		CustomerID:    customerID,
 		Limit:         limit,
 		StartingAfter: startingAfter,
 	}
-	return c.ListOrders(options)
+	return c.ListOrders(ctx, options)
 }
 
 // GetRecentOrders retrieves orders created since a specific date
-func (c *Client) GetRecentOrders(createdSince string, limit int, startingAfter string) (*OrdersResponse, error) {
+func (c *Client) GetRecentOrders(ctx context.Context, createdSince string, limit int, startingAfter string) (*OrdersResponse, error) {
 	options := &OrderListOptions{
 		CreatedSince:  createdSince,
 		Limit:         limit,
 		StartingAfter: startingAfter,
 	}
-	return c.ListOrders(options)
+	return c.ListOrders(ctx, options)
 }
 
 // GetOrdersInDateRange retrieves orders within a date range
-func (c *Client) GetOrdersInDateRange(createdSince, createdUntil string, limit int, startingAfter string) (*OrdersResponse, error) {
+func (c *Client) GetOrdersInDateRange(ctx context.Context, createdSince, createdUntil string, limit int, startingAfter string) (*OrdersResponse, error) {
 	options := &OrderListOptions{
 		CreatedSince:  createdSince,
 		CreatedUntil:  createdUntil,
 		Limit:         limit,
 		StartingAfter: startingAfter,
 	}
-	return c.ListOrders(options)
+	return c.ListOrders(ctx, options)
 }
 
 // GetLast10Orders is a convenience method to get the last 10 orders
-func (c *Client) GetLast10Orders() (*OrdersResponse, error) {
-	return c.GetAllOrders(10, "")
+func (c *Client) GetLast10Orders(ctx context.Context) (*OrdersResponse, error) {
+	return c.GetAllOrders(ctx, 10, "")
 }
\ No newline at end of file
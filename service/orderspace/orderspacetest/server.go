@@ -0,0 +1,599 @@
+// Package orderspacetest provides an in-memory fake of the Orderspace API for tests.
+//
+// The fake implements the OAuth token endpoint, paginated GET /orders,
+// GET /orders/{id} and POST /orders, list/get/create/update for /customers, list/get for
+// /products, PUT /variants/{id} for stock levels, GET /price_lists and POST
+// /dispatches, seeded from fixture JSON in testdata.
+package orderspacetest
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+)
+
+// Credentials accepted by the fake token endpoint
+const (
+	ClientID     = "test-client-id"
+	ClientSecret = "test-client-secret"
+)
+
+//go:embed testdata/orders.json
+var ordersFixture []byte
+
+//go:embed testdata/customers.json
+var customersFixture []byte
+
+//go:embed testdata/products.json
+var productsFixture []byte
+
+//go:embed testdata/price_lists.json
+var priceListsFixture []byte
+
+// Orders returns the fixture orders, newest first
+func Orders() []orderspace.Order {
+	var orders []orderspace.Order
+	if err := json.Unmarshal(ordersFixture, &orders); err != nil {
+		panic("orderspacetest: invalid orders fixture: " + err.Error())
+	}
+	return orders
+}
+
+// Customers returns the fixture customers, oldest first
+func Customers() []orderspace.Customer {
+	var customers []orderspace.Customer
+	if err := json.Unmarshal(customersFixture, &customers); err != nil {
+		panic("orderspacetest: invalid customers fixture: " + err.Error())
+	}
+	return customers
+}
+
+// Products returns the fixture products
+func Products() []orderspace.Product {
+	var products []orderspace.Product
+	if err := json.Unmarshal(productsFixture, &products); err != nil {
+		panic("orderspacetest: invalid products fixture: " + err.Error())
+	}
+	return products
+}
+
+// PriceLists returns the fixture price lists, which every Server serves
+func PriceLists() []orderspace.PriceList {
+	var priceLists []orderspace.PriceList
+	if err := json.Unmarshal(priceListsFixture, &priceLists); err != nil {
+		panic("orderspacetest: invalid price lists fixture: " + err.Error())
+	}
+	return priceLists
+}
+
+// Server is a fake Orderspace API backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	orders        []orderspace.Order
+	customers     []orderspace.Customer
+	products      []orderspace.Product
+	priceLists    []orderspace.PriceList
+	dispatches    []orderspace.Dispatch
+	tokens        map[string]bool
+	tokenRequests int
+}
+
+// NewServer starts a fake Orderspace API serving the given orders, customers and
+// products in the order given. Callers should Close it when done.
+func NewServer(orders []orderspace.Order, customers []orderspace.Customer, products []orderspace.Product) *Server {
+	s := &Server{
+		orders:     orders,
+		customers:  customers,
+		products:   products,
+		priceLists: PriceLists(),
+		tokens:     make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/token", s.handleToken)
+	mux.HandleFunc("GET /orders", s.authenticated(s.handleListOrders))
+	mux.HandleFunc("POST /orders", s.authenticated(s.handleCreateOrder))
+	mux.HandleFunc("GET /orders/{id}", s.authenticated(s.handleGetOrder))
+	mux.HandleFunc("GET /customers", s.authenticated(s.handleListCustomers))
+	mux.HandleFunc("POST /customers", s.authenticated(s.handleCreateCustomer))
+	mux.HandleFunc("GET /customers/{id}", s.authenticated(s.handleGetCustomer))
+	mux.HandleFunc("PUT /customers/{id}", s.authenticated(s.handleUpdateCustomer))
+	mux.HandleFunc("GET /products", s.authenticated(s.handleListProducts))
+	mux.HandleFunc("GET /products/{id}", s.authenticated(s.handleGetProduct))
+	mux.HandleFunc("PUT /variants/{id}", s.authenticated(s.handleUpdateVariant))
+	mux.HandleFunc("GET /price_lists", s.authenticated(s.handleListPriceLists))
+	mux.HandleFunc("POST /dispatches", s.authenticated(s.handleCreateDispatch))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// NewClient returns an orderspace.Client configured to talk to the fake
+func (s *Server) NewClient() *orderspace.Client {
+	c := orderspace.NewClient(s.URL, ClientID, ClientSecret)
+	c.SetTokenURL(s.URL + "/oauth/token")
+	return c
+}
+
+// TokenRequests reports how many access tokens have been issued
+func (s *Server) TokenRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenRequests
+}
+
+// Dispatches returns the dispatches created so far, oldest first
+func (s *Server) Dispatches() []orderspace.Dispatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.dispatches)
+}
+
+// RevokeTokens invalidates every issued token, as if they had expired server-side
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.tokens)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, "unsupported grant_type")
+		return
+	}
+	if r.PostForm.Get("client_id") != ClientID || r.PostForm.Get("client_secret") != ClientSecret {
+		writeError(w, http.StatusUnauthorized, "invalid client credentials")
+		return
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.tokenRequests++
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, orderspace.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+		Scope:       "read write",
+	})
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		valid := ok && s.tokens[token]
+		s.mu.Unlock()
+		if !valid {
+			writeError(w, http.StatusUnauthorized, "invalid or expired access token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	matched := slices.DeleteFunc(slices.Clone(s.orders), func(o orderspace.Order) bool {
+		return (q.Get("status") != "" && o.Status != q.Get("status")) ||
+			(q.Get("customer_id") != "" && o.CustomerID != q.Get("customer_id")) ||
+			(q.Get("created_since") != "" && o.Created < q.Get("created_since")) ||
+			(q.Get("created_until") != "" && o.Created > q.Get("created_until"))
+	})
+	s.mu.Unlock()
+
+	writePage(w, r, "orders", matched, func(o orderspace.Order) string { return o.ID })
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	i := slices.IndexFunc(s.orders, func(o orderspace.Order) bool { return o.ID == id })
+	var order orderspace.Order
+	if i >= 0 {
+		order = s.orders[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"order": order})
+}
+
+func (s *Server) handleListCustomers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	matched := slices.DeleteFunc(slices.Clone(s.customers), func(c orderspace.Customer) bool {
+		return (q.Get("status") != "" && c.Status != q.Get("status")) ||
+			(q.Get("created_since") != "" && c.Created < q.Get("created_since"))
+	})
+	s.mu.Unlock()
+
+	writePage(w, r, "customers", matched, func(c orderspace.Customer) string { return c.ID })
+}
+
+func (s *Server) handleGetCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.customerIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "customer not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"customer": s.customers[i]})
+}
+
+func (s *Server) handleCreateCustomer(w http.ResponseWriter, r *http.Request) {
+	customer, ok := decodeCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer.ID = fmt.Sprintf("cu_test%04d", len(s.customers)+1)
+	customer.Created = time.Now().UTC().Format(time.RFC3339)
+	if customer.Status == "" {
+		customer.Status = "active"
+	}
+	s.customers = append(s.customers, customer)
+	writeJSON(w, http.StatusCreated, map[string]any{"customer": customer})
+}
+
+func (s *Server) handleUpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	customer, ok := decodeCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.customerIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "customer not found")
+		return
+	}
+	customer.ID = s.customers[i].ID
+	customer.Created = s.customers[i].Created
+	s.customers[i] = customer
+	writeJSON(w, http.StatusOK, map[string]any{"customer": customer})
+}
+
+func (s *Server) handleListProducts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	matched := slices.DeleteFunc(slices.Clone(s.products), func(p orderspace.Product) bool {
+		return q.Get("active") != "" && strconv.FormatBool(p.Active) != q.Get("active")
+	})
+	s.mu.Unlock()
+
+	writePage(w, r, "products", matched, func(p orderspace.Product) string { return p.ID })
+}
+
+func (s *Server) handleGetProduct(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	i := slices.IndexFunc(s.products, func(p orderspace.Product) bool { return p.ID == id })
+	var product orderspace.Product
+	if i >= 0 {
+		product = s.products[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "product not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"product": product})
+}
+
+func (s *Server) handleUpdateVariant(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Variant struct {
+			StockLevel *int `json:"stock_level"`
+		} `json:"variant"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	for i := range s.products {
+		for j := range s.products[i].Variants {
+			variant := &s.products[i].Variants[j]
+			if variant.ID != id {
+				continue
+			}
+			if body.Variant.StockLevel != nil {
+				variant.StockLevel = body.Variant.StockLevel
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"variant": variant})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "variant not found")
+}
+
+func (s *Server) handleListPriceLists(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"price_lists": s.priceLists})
+}
+
+// handleCreateDispatch records lines as dispatched, refusing to send more of a
+// line than is outstanding, and marks the order fulfilled once every line is sent
+func (s *Server) handleCreateDispatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Dispatch orderspace.Dispatch `json:"dispatch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	dispatch := body.Dispatch
+	if len(dispatch.DispatchLines) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "dispatch_lines is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.orders, func(o orderspace.Order) bool { return o.ID == dispatch.OrderID })
+	if i < 0 {
+		writeError(w, http.StatusUnprocessableEntity, "order not found")
+		return
+	}
+	order := &s.orders[i]
+	lines := slices.Clone(order.OrderLines)
+	for _, dl := range dispatch.DispatchLines {
+		j := slices.IndexFunc(lines, func(l orderspace.OrderLine) bool { return l.ID == dl.OrderLineID })
+		if j < 0 {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("order line %s not found", dl.OrderLineID))
+			return
+		}
+		if dl.Quantity < 1 || lines[j].Dispatched+dl.Quantity > lines[j].Quantity {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("quantity for order line %s exceeds the quantity outstanding", dl.OrderLineID))
+			return
+		}
+		lines[j].Dispatched += dl.Quantity
+	}
+	order.OrderLines = lines
+	if !slices.ContainsFunc(lines, func(l orderspace.OrderLine) bool { return !l.Shipping && l.Dispatched < l.Quantity }) {
+		order.Status = "fulfilled"
+	}
+
+	dispatch.ID = fmt.Sprintf("di_test%04d", len(s.dispatches)+1)
+	dispatch.Created = time.Now().UTC().Format(time.RFC3339)
+	s.dispatches = append(s.dispatches, dispatch)
+	writeJSON(w, http.StatusCreated, map[string]any{"dispatch": dispatch})
+}
+
+// handleCreateOrder places an order for an existing customer, pricing each
+// line from the customer's price list unless a unit price is given
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Order orderspace.NewOrder `json:"order"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	req := body.Order
+	if len(req.OrderLines) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "order_lines is required")
+		return
+	}
+	if req.DeliveryDate != "" {
+		if _, err := time.Parse("2006-01-02", req.DeliveryDate); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "delivery_date must be YYYY-MM-DD")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ci := s.customerIndex(req.CustomerID)
+	if ci < 0 {
+		writeError(w, http.StatusUnprocessableEntity, "customer not found")
+		return
+	}
+	customer := s.customers[ci]
+
+	currency := ""
+	for _, pl := range s.priceLists {
+		if pl.Default || currency == "" {
+			currency = pl.Currency
+		}
+	}
+	number := 0
+	for _, o := range s.orders {
+		number = max(number, o.Number)
+	}
+	number++
+
+	order := orderspace.Order{
+		ID:               fmt.Sprintf("or_test%04d", number),
+		Number:           number,
+		Created:          time.Now().UTC().Format(time.RFC3339),
+		Status:           "new",
+		CustomerID:       customer.ID,
+		CompanyName:      customer.CompanyName,
+		Phone:            customer.Phone,
+		EmailAddresses:   customer.EmailAddresses,
+		CreatedBy:        "api",
+		DeliveryDate:     req.DeliveryDate,
+		InternalNote:     req.InternalNote,
+		CustomerPONumber: req.CustomerPONumber,
+		CustomerNote:     req.CustomerNote,
+		ShippingType:     "delivery",
+		Currency:         currency,
+	}
+	if len(customer.Addresses) > 0 {
+		order.ShippingAddress, order.BillingAddress = customer.Addresses[0], customer.Addresses[0]
+	}
+	if req.ShippingAddress != nil {
+		order.ShippingAddress = *req.ShippingAddress
+	}
+	if req.BillingAddress != nil {
+		order.BillingAddress = *req.BillingAddress
+	}
+
+	for n, line := range req.OrderLines {
+		product, variant, ok := s.variantBySKU(line.SKU)
+		if !ok || !product.Active {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("sku %q not found", line.SKU))
+			return
+		}
+		if line.Quantity < 1 {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("quantity for sku %q must be at least 1", line.SKU))
+			return
+		}
+		price := line.UnitPrice
+		if price == 0 {
+			price = variant.UnitPrice
+			for _, p := range variant.PriceListPrices {
+				if p.PriceListID == customer.PriceListID {
+					price = p.UnitPrice
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(variant.Options))
+		for k := range variant.Options {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		options := make([]string, 0, len(keys))
+		for _, k := range keys {
+			options = append(options, variant.Options[k])
+		}
+
+		subTotal := price * float64(line.Quantity)
+		order.OrderLines = append(order.OrderLines, orderspace.OrderLine{
+			ID:        fmt.Sprintf("%s_l%d", order.ID, n+1),
+			SKU:       variant.SKU,
+			Name:      product.Name,
+			Options:   strings.Join(options, ", "),
+			Quantity:  line.Quantity,
+			UnitPrice: price,
+			SubTotal:  subTotal,
+		})
+		order.NetTotal += subTotal
+	}
+	order.GrossTotal = order.NetTotal
+
+	s.orders = append([]orderspace.Order{order}, s.orders...)
+	writeJSON(w, http.StatusCreated, map[string]any{"order": order})
+}
+
+// variantBySKU finds the product variant with the given SKU. s.mu must be held.
+func (s *Server) variantBySKU(sku string) (orderspace.Product, orderspace.Variant, bool) {
+	for _, p := range s.products {
+		for _, v := range p.Variants {
+			if sku != "" && v.SKU == sku {
+				return p, v, true
+			}
+		}
+	}
+	return orderspace.Product{}, orderspace.Variant{}, false
+}
+
+// customerIndex returns the position of the customer with the given ID, or -1. s.mu must be held.
+func (s *Server) customerIndex(id string) int {
+	return slices.IndexFunc(s.customers, func(c orderspace.Customer) bool { return c.ID == id })
+}
+
+func decodeCustomer(w http.ResponseWriter, r *http.Request) (orderspace.Customer, bool) {
+	var body struct {
+		Customer orderspace.Customer `json:"customer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return orderspace.Customer{}, false
+	}
+	if body.Customer.CompanyName == "" {
+		writeError(w, http.StatusUnprocessableEntity, "company_name is required")
+		return orderspace.Customer{}, false
+	}
+	return body.Customer, true
+}
+
+// writePage writes one page of items under key, applying the limit and
+// starting_after cursor parameters the way Orderspace list endpoints do
+func writePage[T any](w http.ResponseWriter, r *http.Request, key string, items []T, id func(T) string) {
+	q := r.URL.Query()
+
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	start := 0
+	if after := q.Get("starting_after"); after != "" {
+		i := slices.IndexFunc(items, func(item T) bool { return id(item) == after })
+		if i < 0 {
+			writeError(w, http.StatusBadRequest, "unknown starting_after cursor")
+			return
+		}
+		start = i + 1
+	}
+	end := min(start+limit, len(items))
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		key:        items[start:end],
+		"has_more": end < len(items),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,43 @@
+package orderspace
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is the page size used by the All* iterators when none is given
+const DefaultPageSize = 50
+
+// paginate yields every item from a cursor-paginated endpoint. fetch is called with
+// the cursor to start after and returns one page, the cursor for the next page and
+// whether the API reported more results. Iteration stops on the first error, when
+// the API reports no more results, or when the caller stops ranging.
+func paginate[T any](ctx context.Context, startingAfter string, fetch func(startingAfter string) (items []T, next string, hasMore bool, err error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := startingAfter
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			items, next, hasMore, err := fetch(cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if !hasMore || len(items) == 0 || next == "" {
+				return
+			}
+			cursor = next
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package orderspace
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// Product represents an Orderspace product and its sellable variants
+type Product struct {
+	ID          string    `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	Categories  []string  `json:"categories"`
+	Variants    []Variant `json:"variants"`
+}
+
+// Variant represents one sellable option of a product, such as a bag size or grind
+type Variant struct {
+	ID              string            `json:"id"`
+	SKU             string            `json:"sku"`
+	Options         map[string]string `json:"options"` // option name to value, e.g. "Size": "250g"
+	UnitPrice       float64           `json:"unit_price"`
+	RRP             float64           `json:"rrp"` // recommended retail price, zero when not set
+	Barcode         string            `json:"barcode"`
+	Weight          float64           `json:"weight"`
+	Backorder       bool              `json:"backorder"`
+	StockLevel      *int              `json:"stock_level"` // nil when stock isn't tracked
+	PriceListPrices []PriceListPrice  `json:"price_list_prices"`
+}
+
+// PriceListPrice is a variant's unit price on a specific price list
+type PriceListPrice struct {
+	PriceListID string  `json:"price_list_id"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+// PriceList represents a set of customer-specific prices
+type PriceList struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Default  bool   `json:"default"`
+}
+
+// ProductsResponse represents the response when fetching multiple products
+type ProductsResponse struct {
+	Products   []Product
+	Pagination *PaginationInfo
+	Headers    http.Header
+}
+
+// ProductListOptions holds filtering options for listing products
+type ProductListOptions struct {
+	// Pagination
+	Limit         int
+	StartingAfter string
+
+	// Filtering
+	Active       string // "true" or "false"
+	UpdatedSince string // Filter products updated since this date (ISO 8601)
+
+	// Additional custom parameters
+	Params map[string]string
+}
+
+// ListProducts retrieves products with optional filtering
+func (c *Client) ListProducts(ctx context.Context, options *ProductListOptions) (*ProductsResponse, error) {
+	params := make(map[string]string)
+	requestOptions := &RequestOptions{
+		Params: params,
+	}
+
+	if options != nil {
+		// Set pagination
+		requestOptions.Limit = options.Limit
+		requestOptions.StartingAfter = options.StartingAfter
+
+		// Set filtering parameters
+		if options.Active != "" {
+			params["active"] = options.Active
+		}
+		if options.UpdatedSince != "" {
+			params["updated_since"] = options.UpdatedSince
+		}
+
+		// Add any additional custom parameters
+		for key, value := range options.Params {
+			params[key] = value
+		}
+	}
+
+	response, err := c.GET(ctx, "products", requestOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrappedResponse struct {
+		Products []Product `json:"products"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal products: %w", err)
+	}
+
+	return &ProductsResponse{
+		Products:   wrappedResponse.Products,
+		Pagination: response.Pagination,
+		Headers:    response.Headers,
+	}, nil
+}
+
+// AllProducts iterates over every product matching options, following the
+// starting_after cursor until Orderspace reports no more results
+func (c *Client) AllProducts(ctx context.Context, options *ProductListOptions) iter.Seq2[Product, error] {
+	opts := ProductListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.StartingAfter, func(startingAfter string) ([]Product, string, bool, error) {
+		opts.StartingAfter = startingAfter
+		res, err := c.ListProducts(ctx, &opts)
+		if err != nil {
+			return nil, "", false, err
+		}
+		next := ""
+		if len(res.Products) > 0 {
+			next = res.Products[len(res.Products)-1].ID
+		}
+		return res.Products, next, res.Pagination.HasMore, nil
+	})
+}
+
+// GetProduct retrieves a single product by ID
+func (c *Client) GetProduct(ctx context.Context, productID string) (*Product, error) {
+	response, err := c.GET(ctx, fmt.Sprintf("products/%s", productID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+
+	var wrappedResponse struct {
+		Product Product `json:"product"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &wrappedResponse.Product, nil
+}
+
+// ListPriceLists retrieves every price list. Accounts have a handful at most,
+// so the endpoint is not paginated.
+func (c *Client) ListPriceLists(ctx context.Context) ([]PriceList, error) {
+	response, err := c.GET(ctx, "price_lists", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrappedResponse struct {
+		PriceLists []PriceList `json:"price_lists"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal price lists: %w", err)
+	}
+	return wrappedResponse.PriceLists, nil
+}
+
+// SetVariantStock sets the stock level of a variant and returns the updated variant
+func (c *Client) SetVariantStock(ctx context.Context, variantID string, quantity int) (*Variant, error) {
+	body := map[string]any{"variant": map[string]int{"stock_level": quantity}}
+	response, err := c.PUT(ctx, fmt.Sprintf("variants/%s", variantID), body, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return nil, fmt.Errorf("no data in response")
+	}
+
+	var wrappedResponse struct {
+		Variant Variant `json:"variant"`
+	}
+	if err := decodeData(response.Data, &wrappedResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal variant: %w", err)
+	}
+	return &wrappedResponse.Variant, nil
+}
@@ -0,0 +1,82 @@
+package orderspace
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// tokenRefreshWindow is how long before expiry a token is refreshed in the background
+const tokenRefreshWindow = 2 * time.Minute
+
+// tokenSource caches the OAuth access token and is safe for concurrent use.
+// Concurrent refreshes are collapsed into a single token request, and tokens
+// close to expiry are refreshed in the background while still being served.
+type tokenSource struct {
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	group  singleflight.Group
+	fetch  func(ctx context.Context) (*TokenResponse, error)
+}
+
+func newTokenSource(fetch func(ctx context.Context) (*TokenResponse, error)) *tokenSource {
+	return &tokenSource{fetch: fetch}
+}
+
+// Token returns a valid access token, fetching a new one if the cached token has expired
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	token, expiry := ts.token, ts.expiry
+	ts.mu.Unlock()
+
+	now := time.Now()
+	if token != "" && now.Before(expiry) {
+		if expiry.Sub(now) < tokenRefreshWindow {
+			// Still usable, so refresh without making this caller wait
+			ts.group.DoChan("token", ts.refresh(context.WithoutCancel(ctx)))
+		}
+		return token, nil
+	}
+
+	// The refresh is shared by every waiting caller, so it must not be
+	// cancelled just because the caller that started it gave up
+	ch := ts.group.DoChan("token", ts.refresh(context.WithoutCancel(ctx)))
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Invalidate drops the cached token if it is still the one that was rejected,
+// so the next call to Token fetches a fresh one
+func (ts *tokenSource) Invalidate(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token == token {
+		ts.token = ""
+		ts.expiry = time.Time{}
+	}
+}
+
+func (ts *tokenSource) refresh(ctx context.Context) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		resp, err := ts.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		ts.token = resp.AccessToken
+		ts.expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+		return ts.token, nil
+	}
+}
@@ -0,0 +1,207 @@
+package ordersync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+)
+
+// Backfiller walks the full order history of a channel and writes it to the local store.
+// Progress is checkpointed after every page in backfill_state, so an interrupted run
+// resumes from its last cursor when started again with the same since date.
+type Backfiller struct {
+	logger   *slog.Logger
+	orders   *order.OrderService
+	pageSize int
+}
+
+func NewBackfiller(logger *slog.Logger, orderService *order.OrderService, pageSize int) *Backfiller {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	return &Backfiller{
+		logger:   logger,
+		orders:   orderService,
+		pageSize: pageSize,
+	}
+}
+
+// Run backfills the given channel ("orderspace", "woocommerce" or "all") from since onwards
+func (b *Backfiller) Run(ctx context.Context, channel string, since time.Time) error {
+	switch channel {
+	case order.Orderspace:
+		return b.BackfillOrderspace(ctx, since)
+	case order.WooCommerce:
+		return b.BackfillWooCommerce(ctx, since)
+	case "all":
+		if err := b.BackfillOrderspace(ctx, since); err != nil {
+			return err
+		}
+		return b.BackfillWooCommerce(ctx, since)
+	default:
+		return fmt.Errorf("unknown channel %q", channel)
+	}
+}
+
+// BackfillOrderspace walks every Orderspace order created since the given date using cursor pagination
+func (b *Backfiller) BackfillOrderspace(ctx context.Context, since time.Time) error {
+	state, err := b.resume(ctx, order.Orderspace, since)
+	if err != nil {
+		return err
+	}
+
+	written := int(state.OrdersWritten)
+	options := &orderspace.OrderListOptions{
+		Limit:         b.pageSize,
+		StartingAfter: state.Cursor,
+		CreatedSince:  since.Format(time.RFC3339),
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := b.orders.OrderspaceClient.ListOrders(ctx, options)
+		if err != nil {
+			return fmt.Errorf("failed to list orderspace orders after %q: %w", options.StartingAfter, err)
+		}
+		for _, o := range res.Orders {
+			err := b.orders.SaveOrderspaceOrder(ctx, o)
+			if errors.Is(err, order.ErrUnreadableAmount) {
+				b.logger.Error("Skipping orderspace order with unreadable amounts", "orderID", o.ID, "error_message", err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		written += len(res.Orders)
+
+		if len(res.Orders) == 0 || !res.Pagination.HasMore {
+			break
+		}
+		options.StartingAfter = res.Orders[len(res.Orders)-1].ID
+		if err := b.checkpoint(ctx, order.Orderspace, options.StartingAfter, written); err != nil {
+			return err
+		}
+		b.logger.Info("Backfill progress", "channel", order.Orderspace, "orders_written", written, "cursor", options.StartingAfter)
+	}
+
+	return b.complete(ctx, order.Orderspace, written)
+}
+
+// BackfillWooCommerce walks every WooCommerce order created since the given date page by page
+func (b *Backfiller) BackfillWooCommerce(ctx context.Context, since time.Time) error {
+	state, err := b.resume(ctx, order.WooCommerce, since)
+	if err != nil {
+		return err
+	}
+
+	page := 1
+	if state.Cursor != "" {
+		page, err = strconv.Atoi(state.Cursor)
+		if err != nil {
+			return fmt.Errorf("invalid woocommerce backfill cursor %q: %w", state.Cursor, err)
+		}
+	}
+
+	written := int(state.OrdersWritten)
+	// Oldest first, so orders placed while the backfill runs land on later pages
+	// instead of shifting the pages we have already written
+	options := &woocommerce.OrderListOptions{
+		Page:        page,
+		PerPage:     b.pageSize,
+		After:       since.UTC().Format("2006-01-02T15:04:05"),
+		DatesAreGMT: true,
+		OrderBy:     "date",
+		Order:       "asc",
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		res, err := b.orders.WooClient.ListOrders(ctx, options)
+		if err != nil {
+			return fmt.Errorf("failed to list woocommerce orders page %d: %w", options.Page, err)
+		}
+		for _, o := range res.Orders {
+			err := b.orders.SaveWooOrder(ctx, o)
+			if errors.Is(err, order.ErrUnreadableAmount) {
+				b.logger.Error("Skipping woocommerce order with unreadable amounts", "orderID", o.ID, "error_message", err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		written += len(res.Orders)
+
+		if len(res.Orders) == 0 || options.Page >= res.Pagination.TotalPages {
+			break
+		}
+		options.Page++
+		if err := b.checkpoint(ctx, order.WooCommerce, strconv.Itoa(options.Page), written); err != nil {
+			return err
+		}
+		b.logger.Info("Backfill progress", "channel", order.WooCommerce, "orders_written", written,
+			"page", options.Page-1, "total_pages", res.Pagination.TotalPages, "total_orders", res.Pagination.Total)
+	}
+
+	return b.complete(ctx, order.WooCommerce, written)
+}
+
+// resume returns the saved state for channel when it belongs to an unfinished run
+// with the same since date, and starts a fresh run otherwise
+func (b *Backfiller) resume(ctx context.Context, channel string, since time.Time) (db.BackfillState, error) {
+	state, err := b.orders.Queries.GetBackfillState(ctx, channel)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return db.BackfillState{}, fmt.Errorf("failed to read %s backfill state: %w", channel, err)
+	}
+	if err == nil && !state.CompletedAt.Valid && state.Since.Equal(since) {
+		b.logger.Info("Resuming backfill", "channel", channel, "since", since, "cursor", state.Cursor, "orders_written", state.OrdersWritten)
+		return state, nil
+	}
+
+	state, err = b.orders.Queries.StartBackfill(ctx, db.StartBackfillParams{
+		Channel: channel,
+		Since:   since,
+	})
+	if err != nil {
+		return db.BackfillState{}, fmt.Errorf("failed to start %s backfill: %w", channel, err)
+	}
+	b.logger.Info("Starting backfill", "channel", channel, "since", since)
+	return state, nil
+}
+
+func (b *Backfiller) checkpoint(ctx context.Context, channel, cursor string, written int) error {
+	err := b.orders.Queries.UpdateBackfillCursor(ctx, db.UpdateBackfillCursorParams{
+		Channel:       channel,
+		Cursor:        cursor,
+		OrdersWritten: int32(written),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint %s backfill: %w", channel, err)
+	}
+	return nil
+}
+
+func (b *Backfiller) complete(ctx context.Context, channel string, written int) error {
+	if err := b.checkpoint(ctx, channel, "", written); err != nil {
+		return err
+	}
+	if err := b.orders.Queries.CompleteBackfill(ctx, channel); err != nil {
+		return fmt.Errorf("failed to complete %s backfill: %w", channel, err)
+	}
+	b.logger.Info("Backfill complete", "channel", channel, "orders_written", written)
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package ordersync mirrors Orderspace and WooCommerce orders into the local store
+package ordersync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/jackc/pgx/v5"
+)
+
+type WorkerConfig struct {
+	// Interval between incremental sync runs
+	Interval time.Duration
+	// InitialLookback is how far back the first run reaches when a channel has no high-water mark
+	InitialLookback time.Duration
+	// PageSize is the number of orders requested per API call
+	PageSize int
+	// ListingInterval is how often the product listings are refreshed. Each
+	// refresh walks both channels' catalogues, so it runs less often than order sync.
+	ListingInterval time.Duration
+}
+
+// Worker periodically pulls changed orders from both channels and upserts them locally
+type Worker struct {
+	logger *slog.Logger
+	cfg    WorkerConfig
+	orders *order.OrderService
+
+	listingsSyncedAt time.Time // zero until the first successful refresh
+}
+
+// overlap is subtracted from each high-water mark so clock skew between us and
+// the channel APIs cannot cause an update to be skipped. Upserts make re-reads harmless.
+const overlap = time.Minute
+
+func New(logger *slog.Logger, cfg WorkerConfig, orderService *order.OrderService) *Worker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.InitialLookback <= 0 {
+		cfg.InitialLookback = 30 * 24 * time.Hour
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = 50
+	}
+	if cfg.ListingInterval <= 0 {
+		cfg.ListingInterval = time.Hour
+	}
+	return &Worker{
+		logger: logger,
+		cfg:    cfg,
+		orders: orderService,
+	}
+}
+
+// Run syncs both channels immediately and then on every interval until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("Order sync worker started", "interval", w.cfg.Interval)
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.SyncAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Order sync worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll runs an incremental sync for every channel, refreshes the product
+// listings when they are due, then pushes any stock levels the new orders
+// changed back to the channels, logging failures
+func (w *Worker) SyncAll(ctx context.Context) {
+	if err := w.SyncOrderspace(ctx); err != nil {
+		w.logger.Error("orderspace sync failed", "error_message", err)
+	}
+	if err := w.SyncWooCommerce(ctx); err != nil {
+		w.logger.Error("woocommerce sync failed", "error_message", err)
+	}
+	if time.Since(w.listingsSyncedAt) >= w.cfg.ListingInterval {
+		if err := w.SyncListings(ctx); err != nil {
+			w.logger.Error("product listing sync failed", "error_message", err)
+		}
+	}
+	pushed, err := w.orders.PushPendingStock(ctx)
+	if err != nil {
+		w.logger.Error("stock push failed", "error_message", err)
+	}
+	if pushed > 0 {
+		w.logger.Info("Stock levels pushed", "skus_pushed", pushed)
+	}
+}
+
+// SyncOrderspace pulls every Orderspace order updated since the last high-water mark
+func (w *Worker) SyncOrderspace(ctx context.Context) error {
+	runStart := time.Now().UTC()
+	since, err := w.highWaterMark(ctx, order.Orderspace)
+	if err != nil {
+		return err
+	}
+
+	retries := w.orders.OrderspaceClient.Retries()
+	synced := 0
+	options := &orderspace.OrderListOptions{
+		Limit:        w.cfg.PageSize,
+		UpdatedSince: since.Format(time.RFC3339),
+	}
+	for o, err := range w.orders.OrderspaceClient.AllOrders(ctx, options) {
+		if err != nil {
+			return fmt.Errorf("failed to list orderspace orders: %w", err)
+		}
+		err := w.orders.SaveOrderspaceOrder(ctx, o)
+		if errors.Is(err, order.ErrUnreadableAmount) {
+			w.logger.Error("Skipping orderspace order with unreadable amounts", "orderID", o.ID, "error_message", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		synced++
+	}
+
+	w.logger.Info("Orderspace sync complete", "orders_synced", synced, "since", since, "api_retries", w.orders.OrderspaceClient.Retries()-retries)
+	return w.saveHighWaterMark(ctx, order.Orderspace, runStart, synced)
+}
+
+// SyncWooCommerce pulls every WooCommerce order modified since the last high-water mark
+func (w *Worker) SyncWooCommerce(ctx context.Context) error {
+	runStart := time.Now().UTC()
+	since, err := w.highWaterMark(ctx, order.WooCommerce)
+	if err != nil {
+		return err
+	}
+
+	retries := w.orders.WooClient.Retries()
+	synced := 0
+	options := &woocommerce.OrderListOptions{
+		PerPage:     w.cfg.PageSize,
+		Modified:    since.Format("2006-01-02T15:04:05"),
+		DatesAreGMT: true,
+		OrderBy:     "id",
+		Order:       "asc",
+	}
+	for o, err := range w.orders.WooClient.AllOrders(ctx, options) {
+		if err != nil {
+			return fmt.Errorf("failed to list woocommerce orders: %w", err)
+		}
+		err := w.orders.SaveWooOrder(ctx, o)
+		if errors.Is(err, order.ErrUnreadableAmount) {
+			// Retrying can't fix it, so the order waits until it next changes
+			w.logger.Error("Skipping woocommerce order with unreadable amounts", "orderID", o.ID, "error_message", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		synced++
+	}
+
+	w.logger.Info("WooCommerce sync complete", "orders_synced", synced, "since", since, "api_retries", w.orders.WooClient.Retries()-retries)
+	return w.saveHighWaterMark(ctx, order.WooCommerce, runStart, synced)
+}
+
+// SyncListings refreshes the stored product listings of both channels
+func (w *Worker) SyncListings(ctx context.Context) error {
+	stored, err := w.orders.SyncProductListings(ctx)
+	if err != nil {
+		return err
+	}
+	w.listingsSyncedAt = time.Now()
+	w.logger.Info("Product listing sync complete", "listings_synced", stored)
+	return nil
+}
+
+// highWaterMark returns the point to resume a channel from, falling back to the initial lookback
+func (w *Worker) highWaterMark(ctx context.Context, channel string) (time.Time, error) {
+	state, err := w.orders.Queries.GetSyncState(ctx, channel)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Now().UTC().Add(-w.cfg.InitialLookback), nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read %s sync state: %w", channel, err)
+	}
+	return state.HighWaterMark.Add(-overlap).UTC(), nil
+}
+
+// saveHighWaterMark records that everything up to mark has been synced for channel
+func (w *Worker) saveHighWaterMark(ctx context.Context, channel string, mark time.Time, synced int) error {
+	err := w.orders.Queries.UpsertSyncState(ctx, db.UpsertSyncStateParams{
+		Channel:       channel,
+		HighWaterMark: mark,
+		OrdersSynced:  int32(synced),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save %s sync state: %w", channel, err)
+	}
+	return nil
+}
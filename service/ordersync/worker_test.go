@@ -0,0 +1,146 @@
+package ordersync
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/db/dbtest"
+	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// placedAtArg is the position of placed_at among the UpsertOrder arguments
+const placedAtArg = 13
+
+// newTestWorker returns a worker syncing the given orders from fake channels
+// into a fake store with no high-water marks yet
+func newTestWorker(t *testing.T, osOrders []orderspace.Order, wooOrders []woocommerce.Order) (*Worker, *dbtest.DB) {
+	t.Helper()
+	osrv := orderspacetest.NewServer(osOrders, nil, nil)
+	t.Cleanup(osrv.Close)
+	woo := woocommercetest.NewServer(wooOrders, nil, nil, nil)
+	t.Cleanup(woo.Close)
+
+	store := dbtest.New()
+	store.Handle("GetSyncState", func(args []any) ([][]any, error) { return nil, nil })
+	// The lookback reaches back far enough for every fixture
+	w := New(slog.New(slog.DiscardHandler), WorkerConfig{InitialLookback: 20 * 365 * 24 * time.Hour}, &order.OrderService{
+		OrderspaceClient: osrv.NewClient(),
+		WooClient:        woo.NewClient(),
+		TitleCaser:       cases.Title(language.English),
+		DB:               store,
+		Queries:          db.New(store),
+	})
+	return w, store
+}
+
+func TestSyncStoresOrdersWithUnreadableDates(t *testing.T) {
+	osOrders := orderspacetest.Orders()
+	osOrders[0].Created = "yesterday"
+	wooOrders := woocommercetest.Orders()
+	wooOrders[0].DateCreatedGMT = ""
+	w, store := newTestWorker(t, osOrders, wooOrders)
+	ctx := context.Background()
+
+	if err := w.SyncOrderspace(ctx); err != nil {
+		t.Fatalf("SyncOrderspace: %v", err)
+	}
+	if err := w.SyncWooCommerce(ctx); err != nil {
+		t.Fatalf("SyncWooCommerce: %v", err)
+	}
+
+	placed := map[string]pgtype.Timestamptz{}
+	for _, call := range store.Calls("UpsertOrder") {
+		placed[call.Args[0].(string)+" "+call.Args[1].(string)] = call.Args[placedAtArg].(pgtype.Timestamptz)
+	}
+	if len(placed) != len(osOrders)+len(wooOrders) {
+		t.Errorf("stored %d orders, want all %d", len(placed), len(osOrders)+len(wooOrders))
+	}
+	for _, key := range []string{order.Orderspace + " " + osOrders[0].ID, order.WooCommerce + " " + strconv.Itoa(wooOrders[0].ID)} {
+		if at, ok := placed[key]; !ok || at.Valid {
+			t.Errorf("%s stored = %v with placed_at %v, want it stored without a date", key, ok, at)
+		}
+	}
+	if at := placed[order.Orderspace+" "+osOrders[1].ID]; !at.Valid {
+		t.Errorf("orderspace %s placed_at = %v, want its date", osOrders[1].ID, at)
+	}
+
+	marks := store.Calls("UpsertSyncState")
+	if len(marks) != 2 {
+		t.Fatalf("saved %d high-water marks, want one per channel", len(marks))
+	}
+	for i, channel := range []string{order.Orderspace, order.WooCommerce} {
+		if got := marks[i].Args[0]; got != channel {
+			t.Errorf("high-water mark %d for %v, want %s", i, got, channel)
+		}
+	}
+}
+
+func TestSyncSkipsOrdersWithUnreadableAmounts(t *testing.T) {
+	wooOrders := woocommercetest.Orders()
+	wooOrders[0].Total = "12,50"
+	w, store := newTestWorker(t, nil, wooOrders)
+
+	if err := w.SyncWooCommerce(context.Background()); err != nil {
+		t.Fatalf("SyncWooCommerce: %v", err)
+	}
+	stored := store.Calls("UpsertOrder")
+	if len(stored) != len(wooOrders)-1 {
+		t.Errorf("stored %d orders, want all but the unreadable one of %d", len(stored), len(wooOrders))
+	}
+	for _, call := range stored {
+		if call.Args[1] == strconv.Itoa(wooOrders[0].ID) {
+			t.Errorf("stored order %v, want it skipped", call.Args[1])
+		}
+	}
+	marks := store.Calls("UpsertSyncState")
+	if len(marks) != 1 || marks[0].Args[2] != int32(len(wooOrders)-1) {
+		t.Errorf("high-water marks = %+v, want one counting the stored orders", marks)
+	}
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	w, _ := newTestWorker(t, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run still going 5s after its context was cancelled")
+	}
+}
+
+func TestSyncStoresAmountsInMinorUnits(t *testing.T) {
+	osOrders := orderspacetest.Orders()[:1]
+	osOrders[0].Currency = "KWD"
+	osOrders[0].GrossTotal, osOrders[0].NetTotal = 12.345, 10.287
+	w, store := newTestWorker(t, osOrders, nil)
+
+	if err := w.SyncOrderspace(context.Background()); err != nil {
+		t.Fatalf("SyncOrderspace: %v", err)
+	}
+	stored := store.Calls("UpsertOrder")
+	if len(stored) != 1 {
+		t.Fatalf("stored %d orders, want 1", len(stored))
+	}
+	// net, tax, shipping and gross follow the currency in the UpsertOrder arguments
+	net, tax, gross := stored[0].Args[7], stored[0].Args[8], stored[0].Args[10]
+	if net != int64(10287) || tax != int64(2058) || gross != int64(12345) {
+		t.Errorf("stored net %v, tax %v, gross %v fils, want 10287, 2058 and 12345", net, tax, gross)
+	}
+}
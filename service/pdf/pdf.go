@@ -0,0 +1,206 @@
+// Package pdf writes simple PDF documents of text, lines and boxes. It uses the
+// standard Helvetica fonts every PDF reader provides, so no font is embedded and
+// text is limited to the Windows-1252 character set; other characters print as "?".
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font is one of the standard fonts a Document can use
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// Document is a PDF of one or more A4 pages
+type Document struct {
+	Title string
+	pages []*Page
+}
+
+// Page is a page of a Document. Positions are in points from the top left
+// corner of the page, with y increasing down the page.
+type Page struct {
+	content bytes.Buffer
+}
+
+// New returns an empty document with the given title
+func New(title string) *Document {
+	return &Document{Title: title}
+}
+
+// AddPage appends a blank page to the document and returns it
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the number of pages in the document
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Text draws s in font at size, with the left end of its baseline at x, y
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(PageHeight-y), escape(encode(s)))
+}
+
+// Line draws a straight line width points thick
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect draws the outline of a w by h box whose top left corner is at x, y
+func (p *Page) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n", num(width), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// WriteTo writes the document as a PDF file. A document without pages gets one blank page.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Objects 1 to 5 are the catalog, page tree, two fonts and document info;
+	// each page is then a page object followed by its content stream
+	var objects []string
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (paddy-cap) >>", escape(encode(d.Title))),
+	)
+	for i, p := range d.pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				num(PageWidth), num(PageHeight), 7+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+		)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.WriteTo(w)
+}
+
+// Width returns how wide s is in points when drawn in font at size
+func Width(font Font, size float64, s string) float64 {
+	widths := &helveticaWidths
+	if font == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+	units := 0
+	for _, c := range []byte(encode(s)) {
+		if c >= 32 && c <= 126 {
+			units += widths[c-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, breaking between words where
+// it can. Explicit line breaks in s are kept.
+func Wrap(font Font, size float64, s string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := strings.TrimSpace(line + " " + word)
+			if line == "" || Width(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			lines = append(lines, line)
+			line = word
+		}
+		// A single word wider than the line is split wherever it has to be
+		runes := []rune(line)
+		for Width(font, size, string(runes)) > width && len(runes) > 1 {
+			cut := len(runes)
+			for cut > 1 && Width(font, size, string(runes[:cut])) > width {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			runes = runes[cut:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// encode converts s to Windows-1252, the byte encoding of WinAnsiEncoding
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// escape escapes the characters that are special in a PDF string literal
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`, "\n", `\n`).Replace(s)
+}
+
+// num formats a coordinate with at most two decimal places
+func num(f float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", f), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Glyph widths of the printable ASCII characters, from space to tilde, in
+// thousandths of the font size. Taken from the Adobe font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
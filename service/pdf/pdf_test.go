@@ -0,0 +1,122 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New("Packing slip (#1043)")
+	page := doc.AddPage()
+	page.Text(40, 50, HelveticaBold, 16, "Order #1043")
+	page.Text(40, 70, Helvetica, 10, `Beans (£12.50) \ bag`)
+	page.Line(40, 80, 555, 80, 0.5)
+	page.Rect(40, 90, 10, 10, 1)
+	doc.AddPage().Text(40, 50, Helvetica, 10, "Page two ✓")
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "%PDF-1.4\n") {
+		t.Errorf("output starts %q, want a PDF header", out[:min(len(out), 10)])
+	}
+	if !strings.HasSuffix(out, "%%EOF\n") {
+		t.Errorf("output doesn't end with %%%%EOF")
+	}
+	for _, want := range []string{
+		"/Count 2",
+		"/Title (Packing slip \\(#1043\\))",
+		"BT /F2 16 Tf 40 791.89 Td (Order #1043) Tj ET",
+		"(Beans \\(\xa312.50\\) \\\\ bag) Tj",
+		"0.5 w 40 761.89 m 555 761.89 l S",
+		"1 w 40 741.89 10 10 re S",
+		"(Page two ?) Tj",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q", want)
+		}
+	}
+
+	// Every xref entry must point at the start of its object
+	xref := strings.LastIndex(out, "\nxref\n") + 1
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	if start == nil || start[1] != strconv.Itoa(xref) {
+		t.Fatalf("startxref = %v, want %d", start, xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[xref:], -1)
+	if len(entries) != 5+2*doc.Pages() {
+		t.Fatalf("xref has %d objects, want %d", len(entries), 5+2*doc.Pages())
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, out[offset:offset+10], want)
+		}
+	}
+
+	// Stream lengths must match the bytes between stream and endstream
+	for _, m := range regexp.MustCompile(`(?s)/Length (\d+) >>\nstream\n(.*?)endstream`).FindAllStringSubmatch(out, -1) {
+		if n, _ := strconv.Atoi(m[1]); n != len(m[2]) {
+			t.Errorf("stream /Length %d, want %d", n, len(m[2]))
+		}
+	}
+}
+
+func TestWriteToEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := New("").WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if !strings.Contains(buf.String(), "/Count 1") {
+		t.Error("empty document should be written with one blank page")
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		font Font
+		s    string
+		want float64
+	}{
+		{Helvetica, "Hello", 22.78},
+		{HelveticaBold, "Hello", 24.45},
+		{Helvetica, "£5", 11.12},
+		{Helvetica, "", 0},
+	}
+	for _, tt := range tests {
+		if got := Width(tt.font, 10, tt.s); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("Width(%d, 10, %q) = %v, want %v", tt.font, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width float64
+		want  []string
+	}{
+		{"fits", "Leave in porch", 200, []string{"Leave in porch"}},
+		{"words", "Leave in the porch please", 60, []string{"Leave in the", "porch please"}},
+		{"newlines", "Back door\nRing twice", 200, []string{"Back door", "Ring twice"}},
+		{"long word", "ABCDEFGHIJ", 30, []string{"ABCD", "EFGH", "IJ"}},
+		{"empty", "", 100, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Wrap(Helvetica, 10, tt.s, tt.width); !slices.Equal(got, tt.want) {
+				t.Errorf("Wrap(%q, %v) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+		})
+	}
+}
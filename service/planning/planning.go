@@ -0,0 +1,395 @@
+// Package planning turns open orders from both channels into a roast plan:
+// how much of each coffee is needed for each delivery day
+package planning
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/db"
+	"github.com/dukerupert/paddy-cap/service/order"
+	"github.com/dukerupert/paddy-cap/service/orderspace"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+)
+
+// plannedStatuses are the lifecycle statuses of orders that still have to be
+// roasted and sent. Orders awaiting payment are left out until they are paid.
+func plannedStatuses() []string {
+	var statuses []string
+	for _, s := range order.Statuses {
+		if s.ToFulfil() {
+			statuses = append(statuses, string(s))
+		}
+	}
+	return statuses
+}
+
+type Config struct {
+	// WooLeadDays is the number of days between a WooCommerce order being
+	// placed and delivered. WooCommerce orders have no delivery date, and
+	// neither do Orderspace orders placed without one.
+	WooLeadDays int
+	// Location is the business timezone, which decides the day an order is
+	// due on. Defaults to UTC.
+	Location *time.Location
+}
+
+// Planner builds roast plans from the orders in the local store
+type Planner struct {
+	cfg     Config
+	queries *db.Queries
+}
+
+func New(logger *slog.Logger, cfg Config, queries *db.Queries) *Planner {
+	if cfg.WooLeadDays < 0 {
+		cfg.WooLeadDays = 0
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	logger.Info("Planner initialized", "woo_lead_days", cfg.WooLeadDays, "location", cfg.Location)
+	return &Planner{cfg: cfg, queries: queries}
+}
+
+// Line is the demand from one order line
+type Line struct {
+	Order        string // origin and number, e.g. "orderspace #1042"
+	DeliveryDate time.Time
+	SKU          string
+	Product      string // line name without its weight or variation options
+	Grind        string
+	Weight       string
+	UnitKilos    float64 // zero when the weight is unknown
+	Quantity     int
+}
+
+// Item is the total of one SKU, grind and weight due on a day
+type Item struct {
+	SKU      string  `json:"sku"`
+	Product  string  `json:"product"`
+	Grind    string  `json:"grind"`
+	Weight   string  `json:"weight"`
+	Quantity int     `json:"quantity"`
+	Kilos    float64 `json:"kilos"`
+	Orders   int     `json:"orders"`
+
+	orders map[string]bool
+}
+
+// Coffee is the total weight of one coffee, across bag sizes and grinds
+type Coffee struct {
+	Product string  `json:"product"`
+	Kilos   float64 `json:"kilos"`
+	// UnknownWeight counts units whose weight couldn't be read from the
+	// product name or options, so aren't included in Kilos
+	UnknownWeight int `json:"unknown_weight"`
+}
+
+// Day is everything due for delivery on one date
+type Day struct {
+	Date    time.Time `json:"date"`
+	Overdue bool      `json:"overdue"` // the date has passed but the orders are still open
+	Kilos   float64   `json:"kilos"`
+	Coffees []Coffee  `json:"coffees"`
+	Items   []Item    `json:"items"`
+}
+
+// Plan is the roast plan for the days from From up to the horizon. Open
+// orders that were due before From are included as overdue days.
+type Plan struct {
+	From    time.Time `json:"from"`
+	Horizon int       `json:"horizon_days"`
+	Kilos   float64   `json:"kilos"`
+	Totals  []Coffee  `json:"totals"`
+	Days    []Day     `json:"days"`
+}
+
+// Plan builds the roast plan for the horizon days starting on the day of from
+func (p *Planner) Plan(ctx context.Context, from time.Time, horizon int) (*Plan, error) {
+	rows, err := p.queries.ListOpenOrders(ctx, plannedStatuses())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open orders: %w", err)
+	}
+
+	var lines []Line
+	for _, row := range rows {
+		orderLines, err := p.expandOrder(row)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, orderLines...)
+	}
+	return buildPlan(lines, from, horizon, p.cfg.Location), nil
+}
+
+// expandOrder returns the demand of each product line of a stored order
+func (p *Planner) expandOrder(row db.Order) ([]Line, error) {
+	// Orders whose placed date couldn't be read count from when they were synced
+	placedAt := row.SyncedAt
+	if row.PlacedAt.Valid {
+		placedAt = row.PlacedAt.Time
+	}
+	delivery := dateOf(placedAt, p.cfg.Location).AddDate(0, 0, p.cfg.WooLeadDays)
+	if row.DeliveryDate.Valid {
+		d := row.DeliveryDate.Time
+		delivery = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, p.cfg.Location)
+	}
+
+	switch row.Origin {
+	case order.Orderspace:
+		var o orderspace.Order
+		if err := json.Unmarshal(row.Raw, &o); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stored orderspace order %s: %w", row.ExternalID, err)
+		}
+		return orderspaceLines(o, delivery), nil
+	case order.WooCommerce:
+		var o woocommerce.Order
+		if err := json.Unmarshal(row.Raw, &o); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stored woocommerce order %s: %w", row.ExternalID, err)
+		}
+		return wooLines(o, delivery), nil
+	default:
+		return nil, fmt.Errorf("unknown origin %q for stored order %d", row.Origin, row.ID)
+	}
+}
+
+// orderspaceLines returns the undispatched quantity of each product line,
+// reading grind and weight from the comma-separated options
+func orderspaceLines(o orderspace.Order, delivery time.Time) []Line {
+	var lines []Line
+	for _, ol := range o.OrderLines {
+		remaining := ol.Quantity - ol.Dispatched
+		if ol.Shipping || remaining <= 0 {
+			continue
+		}
+		var options []option
+		for _, value := range strings.Split(ol.Options, ",") {
+			options = append(options, option{value: value})
+		}
+		line := describe(ol.Name, options)
+		line.Order = fmt.Sprintf("%s #%d", order.Orderspace, o.Number)
+		line.DeliveryDate = delivery
+		line.SKU = ol.SKU
+		line.Quantity = remaining
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// wooLines returns each line item, reading grind and weight from its visible meta data
+func wooLines(o woocommerce.Order, delivery time.Time) []Line {
+	var lines []Line
+	for _, item := range o.LineItems {
+		if item.Quantity <= 0 {
+			continue
+		}
+		var options []option
+		for _, meta := range item.MetaData {
+			// Keys starting with an underscore are WooCommerce/plugin internals
+			if value, ok := meta.Value.(string); ok && !strings.HasPrefix(meta.Key, "_") {
+				options = append(options, option{key: meta.Key, value: value})
+			}
+		}
+		line := describe(item.Name, options)
+		line.Order = fmt.Sprintf("%s #%s", order.WooCommerce, o.Number)
+		line.DeliveryDate = delivery
+		line.SKU = item.SKU
+		line.Quantity = item.Quantity
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// option is a product option, keyed by attribute name when the channel gives one
+type option struct {
+	key, value string
+}
+
+// weightPattern matches bag weights such as "250g", "1kg" or "2.5 kg"
+var weightPattern = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)\s*(kg|g)\b`)
+
+// describe splits a line into product, grind and weight. The weight comes
+// from a weight or size option, any option that looks like a weight, or the
+// name; the grind from a grind option, or else every other option. The
+// product is the name without the weight or variation options.
+func describe(name string, options []option) Line {
+	var weight, grind string
+	var others []string
+	for _, o := range options {
+		key, value := strings.ToLower(o.key), strings.TrimSpace(o.value)
+		switch {
+		case value == "":
+		case strings.Contains(key, "grind"):
+			grind = value
+		case strings.Contains(key, "weight") || strings.Contains(key, "size") || weightPattern.MatchString(value):
+			weight = value
+		default:
+			others = append(others, value)
+		}
+	}
+	if grind == "" {
+		grind = strings.Join(others, ", ")
+	}
+
+	product := strings.TrimSpace(name)
+	m := weightPattern.FindString(product)
+	if weight == "" {
+		weight = m
+	}
+	if parent, _, ok := strings.Cut(product, " - "); ok {
+		// WooCommerce names variations "Parent - Option, Option"
+		product = parent
+	} else if m != "" {
+		product = strings.Join(strings.Fields(strings.Replace(product, m, "", 1)), " ")
+	}
+	return Line{Product: product, Grind: grind, Weight: weight, UnitKilos: kilos(weight)}
+}
+
+// kilos converts a weight such as "250g" to kilograms, returning zero if it can't be read
+func kilos(weight string) float64 {
+	m := weightPattern.FindStringSubmatch(weight)
+	if m == nil {
+		return 0
+	}
+	amount, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0
+	}
+	if strings.EqualFold(m[2], "g") {
+		return amount / 1000
+	}
+	return amount
+}
+
+// buildPlan totals the lines due before the horizon by day, then by SKU,
+// grind and weight, and by coffee. Days start at midnight in loc.
+func buildPlan(lines []Line, from time.Time, horizon int, loc *time.Location) *Plan {
+	from = dateOf(from, loc)
+	end := from.AddDate(0, 0, horizon)
+	plan := &Plan{From: from, Horizon: horizon}
+
+	days := map[time.Time]*Day{}
+	items := map[time.Time]map[string]*Item{}
+	for _, line := range lines {
+		date := dateOf(line.DeliveryDate, loc)
+		if !date.Before(end) {
+			continue
+		}
+		day, ok := days[date]
+		if !ok {
+			day = &Day{Date: date, Overdue: date.Before(from)}
+			days[date] = day
+			items[date] = map[string]*Item{}
+		}
+
+		key := strings.ToUpper(strings.TrimSpace(line.SKU)) + "|" + line.Product + "|" + line.Grind + "|" + line.Weight
+		item, ok := items[date][key]
+		if !ok {
+			item = &Item{
+				SKU:     strings.TrimSpace(line.SKU),
+				Product: line.Product,
+				Grind:   line.Grind,
+				Weight:  line.Weight,
+				orders:  map[string]bool{},
+			}
+			items[date][key] = item
+		}
+		item.Quantity += line.Quantity
+		item.Kilos += line.UnitKilos * float64(line.Quantity)
+		item.orders[line.Order] = true
+		item.Orders = len(item.orders)
+	}
+
+	totals := map[string]*Coffee{}
+	for date, day := range days {
+		coffees := map[string]*Coffee{}
+		for _, item := range items[date] {
+			day.Items = append(day.Items, *item)
+			day.Kilos += item.Kilos
+			addCoffee(coffees, *item)
+			addCoffee(totals, *item)
+		}
+		slices.SortFunc(day.Items, func(a, b Item) int {
+			return cmp.Or(
+				cmp.Compare(a.Product, b.Product),
+				cmp.Compare(kilos(a.Weight), kilos(b.Weight)),
+				cmp.Compare(a.Grind, b.Grind),
+				cmp.Compare(a.SKU, b.SKU),
+			)
+		})
+		day.Coffees = sortedCoffees(coffees)
+		plan.Kilos += day.Kilos
+		plan.Days = append(plan.Days, *day)
+	}
+	slices.SortFunc(plan.Days, func(a, b Day) int { return a.Date.Compare(b.Date) })
+	plan.Totals = sortedCoffees(totals)
+	return plan
+}
+
+func addCoffee(coffees map[string]*Coffee, item Item) {
+	c, ok := coffees[item.Product]
+	if !ok {
+		c = &Coffee{Product: item.Product}
+		coffees[item.Product] = c
+	}
+	c.Kilos += item.Kilos
+	if item.Kilos == 0 {
+		c.UnknownWeight += item.Quantity
+	}
+}
+
+// sortedCoffees returns the coffees heaviest first
+func sortedCoffees(coffees map[string]*Coffee) []Coffee {
+	sorted := make([]Coffee, 0, len(coffees))
+	for _, c := range coffees {
+		sorted = append(sorted, *c)
+	}
+	slices.SortFunc(sorted, func(a, b Coffee) int {
+		return cmp.Or(cmp.Compare(b.Kilos, a.Kilos), cmp.Compare(a.Product, b.Product))
+	})
+	return sorted
+}
+
+// WriteCSV writes one row per day and item, for loading into a spreadsheet
+func (p *Plan) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "overdue", "sku", "product", "grind", "weight", "quantity", "kilos", "orders"}); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+	for _, day := range p.Days {
+		for _, item := range day.Items {
+			err := cw.Write([]string{
+				day.Date.Format("2006-01-02"),
+				strconv.FormatBool(day.Overdue),
+				item.SKU,
+				item.Product,
+				item.Grind,
+				item.Weight,
+				strconv.Itoa(item.Quantity),
+				strconv.FormatFloat(item.Kilos, 'f', 3, 64),
+				strconv.Itoa(item.Orders),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to write csv row: %w", err)
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// dateOf returns midnight in loc on the day of t there
+func dateOf(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
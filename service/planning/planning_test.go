@@ -0,0 +1,141 @@
+package planning
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/orderspace/orderspacetest"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []option
+		want    Line
+	}{
+		{
+			name:    "House Espresso 1kg",
+			options: []option{{value: "Whole Bean"}},
+			want:    Line{Product: "House Espresso", Grind: "Whole Bean", Weight: "1kg", UnitKilos: 1},
+		},
+		{
+			name:    "Filter Blend",
+			options: []option{{value: " 250g"}, {value: "Cafetiere"}},
+			want:    Line{Product: "Filter Blend", Grind: "Cafetiere", Weight: "250g", UnitKilos: 0.25},
+		},
+		{
+			name:    "House Espresso Bulk - 2.5 kg, Whole Bean",
+			options: []option{{key: "grind", value: "Espresso"}},
+			want:    Line{Product: "House Espresso Bulk", Grind: "Espresso", Weight: "2.5 kg", UnitKilos: 2.5},
+		},
+		{
+			name:    "Gift Card",
+			options: []option{{key: "Recipient", value: "Sam"}},
+			want:    Line{Product: "Gift Card", Grind: "Sam"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(tt.name, tt.options); got != tt.want {
+				t.Errorf("describe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.Local) }
+	var lines []Line
+	for _, o := range orderspacetest.Orders() {
+		lines = append(lines, orderspaceLines(o, day(17))...)
+	}
+	for _, o := range woocommercetest.Orders() {
+		lines = append(lines, wooLines(o, day(14))...)
+	}
+	// A partly dispatched order only needs what is left to send
+	partial := orderspacetest.Orders()[0]
+	partial.Number = 2001
+	partial.OrderLines[0].Dispatched = 4
+	lines = append(lines, orderspaceLines(partial, day(17))...)
+	// and anything due after the horizon is left for a later plan
+	later := orderspacetest.Orders()[0]
+	lines = append(lines, orderspaceLines(later, day(22))...)
+
+	plan := buildPlan(lines, day(15).Add(9*time.Hour), 7, time.Local)
+
+	if len(plan.Days) != 2 {
+		t.Fatalf("got %d days, want 2: %+v", len(plan.Days), plan.Days)
+	}
+	overdue, due := plan.Days[0], plan.Days[1]
+	if !overdue.Date.Equal(day(14)) || !overdue.Overdue {
+		t.Errorf("first day = %s overdue %v, want 14 March overdue", overdue.Date, overdue.Overdue)
+	}
+	if !due.Date.Equal(day(17)) || due.Overdue {
+		t.Errorf("second day = %s overdue %v, want 17 March on time", due.Date, due.Overdue)
+	}
+
+	// Orderspace: 6 bags of House Espresso 1kg plus 2 left on the partial
+	// order, and 10 Filter Blend 250g not yet dispatched. The fulfilled order
+	// has nothing left to send.
+	var espresso *Item
+	for i, item := range due.Items {
+		if item.SKU == "ESP-1KG-WB" {
+			espresso = &due.Items[i]
+		}
+		if item.SKU == "SHIP-STD" {
+			t.Errorf("shipping line included: %+v", item)
+		}
+	}
+	if espresso == nil || espresso.Quantity != 8 || espresso.Kilos != 8 || espresso.Orders != 2 {
+		t.Errorf("espresso = %+v, want 8 bags, 8 kg from 2 orders", espresso)
+	}
+	if due.Kilos != 10.5 {
+		t.Errorf("17 March kilos = %v, want 10.5", due.Kilos)
+	}
+	if len(due.Coffees) != 2 || due.Coffees[0].Product != "House Espresso" || due.Coffees[1].Kilos != 2.5 {
+		t.Errorf("17 March coffees = %+v, want House Espresso then Filter Blend 2.5kg", due.Coffees)
+	}
+
+	// WooCommerce: every fixture order, open or not, lands on the overdue day
+	if len(overdue.Items) != 4 {
+		t.Errorf("14 March items = %+v, want 4", overdue.Items)
+	}
+	if plan.Totals[0].Product != "House Espresso" || plan.Totals[0].Kilos != 8.75 {
+		t.Errorf("totals = %+v, want House Espresso 8.75 kg first", plan.Totals)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(rows) != 1+len(overdue.Items)+len(due.Items) {
+		t.Errorf("got %d csv rows, want header and one per item", len(rows))
+	}
+	if !strings.Contains(buf.String(), "2025-03-17,false,ESP-1KG-WB,House Espresso,Whole Bean,1kg,8,8.000,2") {
+		t.Errorf("csv missing espresso row:\n%s", buf.String())
+	}
+}
+
+func TestBuildPlanLocation(t *testing.T) {
+	// Half past eleven at night in UTC is already the next day an hour east
+	east := time.FixedZone("UTC+1", 60*60)
+	placed := time.Date(2025, 6, 30, 23, 30, 0, 0, time.UTC)
+	lines := []Line{{Order: "woocommerce #1", DeliveryDate: placed, SKU: "ESP-250-WB", Product: "House Espresso", Weight: "250g", UnitKilos: 0.25, Quantity: 1}}
+
+	for _, tt := range []struct {
+		loc  *time.Location
+		want time.Time
+	}{
+		{time.UTC, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)},
+		{east, time.Date(2025, 7, 1, 0, 0, 0, 0, east)},
+	} {
+		plan := buildPlan(lines, placed, 7, tt.loc)
+		if len(plan.Days) != 1 || !plan.Days[0].Date.Equal(tt.want) {
+			t.Errorf("%s: days = %+v, want one on %s", tt.loc, plan.Days, tt.want)
+		}
+	}
+}
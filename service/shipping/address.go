@@ -0,0 +1,67 @@
+package shipping
+
+import (
+	"regexp"
+	"strings"
+)
+
+// countryCodes maps the country names customers type into checkout forms to
+// ISO codes. Anything else must already be a two-letter code.
+var countryCodes = map[string]string{
+	"UK":               "GB",
+	"UNITED KINGDOM":   "GB",
+	"GREAT BRITAIN":    "GB",
+	"ENGLAND":          "GB",
+	"SCOTLAND":         "GB",
+	"WALES":            "GB",
+	"NORTHERN IRELAND": "GB",
+	"IRELAND":          "IE",
+	"UNITED STATES":    "US",
+	"USA":              "US",
+}
+
+// ukPostcode matches a UK postcode with its spaces removed
+var ukPostcode = regexp.MustCompile(`^[A-Z]{1,2}[0-9][A-Z0-9]?[0-9][A-Z]{2}$`)
+
+// NormalizeAddress tidies an address from either sales channel into the form
+// carriers expect: spacing collapsed, an ISO country code, and UK postcodes
+// upper-cased with their single space. It fails when a carrier couldn't
+// deliver to the address, naming the field at fault.
+func NormalizeAddress(a Address) (Address, error) {
+	for _, f := range []*string{&a.Name, &a.Company, &a.Line1, &a.Line2, &a.City, &a.State, &a.PostalCode, &a.Country, &a.Email, &a.Phone} {
+		*f = strings.Join(strings.Fields(*f), " ")
+	}
+	if a.Line1 == "" {
+		a.Line1, a.Line2 = a.Line2, ""
+	}
+
+	country := strings.ToUpper(a.Country)
+	if code, ok := countryCodes[country]; ok {
+		country = code
+	}
+	if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return a, &ShipmentError{Field: "country", Problem: "must be a two-letter country code"}
+	}
+	a.Country = country
+
+	a.PostalCode = strings.ToUpper(a.PostalCode)
+	if a.Country == "GB" && a.PostalCode != "" {
+		compact := strings.ReplaceAll(a.PostalCode, " ", "")
+		if !ukPostcode.MatchString(compact) {
+			return a, &ShipmentError{Field: "postal_code", Problem: "is not a valid UK postcode"}
+		}
+		a.PostalCode = compact[:len(compact)-3] + " " + compact[len(compact)-3:]
+	}
+
+	switch {
+	case a.Name == "" && a.Company == "":
+		return a, &ShipmentError{Field: "name", Problem: "or company is required"}
+	case a.Line1 == "":
+		return a, &ShipmentError{Field: "line1", Problem: "is required"}
+	case a.City == "":
+		return a, &ShipmentError{Field: "city", Problem: "is required"}
+	case a.PostalCode == "" && a.Country != "IE":
+		return a, &ShipmentError{Field: "postal_code", Problem: "is required"}
+	}
+	return a, nil
+}
@@ -0,0 +1,321 @@
+// Package easypost implements shipping.Carrier on the EasyPost REST API.
+//
+// A quote is an EasyPost shipment and its rates; buying a rate buys postage on
+// that shipment, so the quote ID, label ID and shipment ID are all the same.
+package easypost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+	"github.com/dukerupert/paddy-cap/service/transport"
+)
+
+// DefaultBaseURL is EasyPost's production API
+const DefaultBaseURL = "https://api.easypost.com"
+
+// Channel identifies EasyPost in errors returned by the client
+const Channel = "easypost"
+
+// Client is an EasyPost API client
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	retry      *transport.Retry
+	limiter    *transport.RateLimit
+}
+
+// Error represents an EasyPost API error response.
+// It is returned wrapped in a *transport.Error carrying the HTTP status.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("EasyPost API Error %s: %s", e.Code, e.Message)
+}
+
+// NewClient creates a new EasyPost client. Test keys buy test labels.
+func NewClient(apiKey string) *Client {
+	limiter := transport.NewRateLimit(http.DefaultTransport, 0, 0)
+	retry := transport.NewRetry(limiter, transport.DefaultRetryConfig)
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		APIKey:  apiKey,
+		HTTPClient: &http.Client{
+			Transport: retry,
+		},
+		retry:   retry,
+		limiter: limiter,
+	}
+}
+
+// Name returns "easypost"
+func (c *Client) Name() string {
+	return Channel
+}
+
+// address is an EasyPost address
+type address struct {
+	Name    string `json:"name,omitempty"`
+	Company string `json:"company,omitempty"`
+	Street1 string `json:"street1"`
+	Street2 string `json:"street2,omitempty"`
+	City    string `json:"city"`
+	State   string `json:"state,omitempty"`
+	Zip     string `json:"zip"`
+	Country string `json:"country"`
+	Email   string `json:"email,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+}
+
+// parcel is an EasyPost parcel, in ounces and inches
+type parcel struct {
+	Weight float64 `json:"weight"`
+	Length float64 `json:"length,omitempty"`
+	Width  float64 `json:"width,omitempty"`
+	Height float64 `json:"height,omitempty"`
+}
+
+type rate struct {
+	ID           string `json:"id"`
+	Carrier      string `json:"carrier"`
+	Service      string `json:"service"`
+	Rate         string `json:"rate"`
+	Currency     string `json:"currency"`
+	DeliveryDays int    `json:"delivery_days"`
+}
+
+type shipment struct {
+	ID           string `json:"id"`
+	Rates        []rate `json:"rates"`
+	SelectedRate *rate  `json:"selected_rate"`
+	TrackingCode string `json:"tracking_code"`
+	RefundStatus string `json:"refund_status"`
+	PostageLabel *struct {
+		LabelURL string `json:"label_url"`
+	} `json:"postage_label"`
+	Tracker *struct {
+		PublicURL string `json:"public_url"`
+	} `json:"tracker"`
+}
+
+type tracker struct {
+	TrackingCode    string `json:"tracking_code"`
+	Status          string `json:"status"`
+	EstDeliveryDate string `json:"est_delivery_date"`
+	TrackingDetails []struct {
+		Datetime         string `json:"datetime"`
+		Status           string `json:"status"`
+		Message          string `json:"message"`
+		TrackingLocation struct {
+			City    string `json:"city"`
+			State   string `json:"state"`
+			Country string `json:"country"`
+		} `json:"tracking_location"`
+	} `json:"tracking_details"`
+}
+
+// Quote creates a shipment and returns the rates EasyPost offers for it
+func (c *Client) Quote(ctx context.Context, s shipping.Shipment) (*shipping.Quote, error) {
+	if err := s.Parcel.Valid(); err != nil {
+		return nil, err
+	}
+	body := map[string]any{"shipment": map[string]any{
+		"to_address":   toAddress(s.To),
+		"from_address": toAddress(s.From),
+		"parcel":       toParcel(s.Parcel),
+		"reference":    s.Reference,
+	}}
+	var created shipment
+	if err := c.do(ctx, http.MethodPost, "shipments", body, &created); err != nil {
+		return nil, err
+	}
+
+	quote := &shipping.Quote{ID: created.ID}
+	for _, r := range created.Rates {
+		rate, err := fromRate(r)
+		if err != nil {
+			return nil, err
+		}
+		quote.Rates = append(quote.Rates, rate)
+	}
+	return quote, nil
+}
+
+// BuyLabel buys postage at the given rate on the quoted shipment
+func (c *Client) BuyLabel(ctx context.Context, quoteID, rateID string) (*shipping.Label, error) {
+	body := map[string]any{"rate": map[string]string{"id": rateID}}
+	var bought shipment
+	if err := c.do(ctx, http.MethodPost, "shipments/"+url.PathEscape(quoteID)+"/buy", body, &bought); err != nil {
+		return nil, err
+	}
+	if bought.SelectedRate == nil || bought.PostageLabel == nil {
+		return nil, fmt.Errorf("no label in response for shipment %s", quoteID)
+	}
+
+	rate, err := fromRate(*bought.SelectedRate)
+	if err != nil {
+		return nil, err
+	}
+	label := &shipping.Label{
+		ID:             bought.ID,
+		Carrier:        rate.Carrier,
+		Service:        rate.Service,
+		TrackingNumber: bought.TrackingCode,
+		LabelURL:       bought.PostageLabel.LabelURL,
+		Price:          rate.Price,
+	}
+	if bought.Tracker != nil {
+		label.TrackingURL = bought.Tracker.PublicURL
+	}
+	return label, nil
+}
+
+// VoidLabel asks EasyPost to refund the shipment's postage
+func (c *Client) VoidLabel(ctx context.Context, labelID string) error {
+	var refunded shipment
+	if err := c.do(ctx, http.MethodPost, "shipments/"+url.PathEscape(labelID)+"/refund", nil, &refunded); err != nil {
+		return err
+	}
+	if refunded.RefundStatus == "rejected" || refunded.RefundStatus == "not_applicable" {
+		return fmt.Errorf("refund %s for shipment %s", refunded.RefundStatus, labelID)
+	}
+	return nil
+}
+
+// Track creates, or returns EasyPost's existing, tracker for a tracking number
+func (c *Client) Track(ctx context.Context, trackingNumber string) (*shipping.Tracking, error) {
+	body := map[string]any{"tracker": map[string]string{"tracking_code": trackingNumber}}
+	var t tracker
+	if err := c.do(ctx, http.MethodPost, "trackers", body, &t); err != nil {
+		return nil, err
+	}
+
+	tracking := &shipping.Tracking{TrackingNumber: t.TrackingCode, Status: t.Status}
+	if t.EstDeliveryDate != "" {
+		if est, err := time.Parse(time.RFC3339, t.EstDeliveryDate); err == nil {
+			tracking.EstimatedDelivery = est
+		}
+	}
+	// EasyPost lists details oldest first
+	for i := len(t.TrackingDetails) - 1; i >= 0; i-- {
+		d := t.TrackingDetails[i]
+		event := shipping.TrackingEvent{Status: d.Status, Description: d.Message}
+		if at, err := time.Parse(time.RFC3339, d.Datetime); err == nil {
+			event.Time = at
+		}
+		for _, part := range []string{d.TrackingLocation.City, d.TrackingLocation.State, d.TrackingLocation.Country} {
+			if part == "" {
+				continue
+			}
+			if event.Location != "" {
+				event.Location += ", "
+			}
+			event.Location += part
+		}
+		tracking.Events = append(tracking.Events, event)
+	}
+	return tracking, nil
+}
+
+// do sends a request to the v2 API and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, endpoint string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+"/v2/"+endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(c.APIKey, "")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return transport.NewError(Channel, method, endpoint, nil, nil, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var apiErr error
+		var wrapped struct {
+			Error Error `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &wrapped); err == nil && wrapped.Error.Code != "" {
+			apiErr = &wrapped.Error
+		}
+		return transport.NewError(Channel, method, endpoint, resp, respBody, apiErr)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return nil
+}
+
+func toAddress(a shipping.Address) address {
+	return address{
+		Name:    a.Name,
+		Company: a.Company,
+		Street1: a.Line1,
+		Street2: a.Line2,
+		City:    a.City,
+		State:   a.State,
+		Zip:     a.PostalCode,
+		Country: a.Country,
+		Email:   a.Email,
+		Phone:   a.Phone,
+	}
+}
+
+// toParcel converts to EasyPost's units, rounding up to a tenth so the
+// declared weight is never under the real one
+func toParcel(p shipping.Parcel) parcel {
+	tenths := func(v float64) float64 { return math.Ceil(v*10) / 10 }
+	return parcel{
+		Weight: tenths(float64(p.WeightGrams) / 28.349523125),
+		Length: tenths(float64(p.LengthCM) / 2.54),
+		Width:  tenths(float64(p.WidthCM) / 2.54),
+		Height: tenths(float64(p.HeightCM) / 2.54),
+	}
+}
+
+func fromRate(r rate) (shipping.Rate, error) {
+	price, err := money.Parse(r.Rate, r.Currency)
+	if err != nil {
+		return shipping.Rate{}, fmt.Errorf("failed to parse rate %s: %w", r.ID, err)
+	}
+	return shipping.Rate{
+		ID:           r.ID,
+		Carrier:      r.Carrier,
+		Service:      r.Service,
+		Price:        price,
+		DeliveryDays: r.DeliveryDays,
+	}, nil
+}
+
+var _ shipping.Carrier = (*Client)(nil)
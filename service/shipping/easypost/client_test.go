@@ -0,0 +1,165 @@
+package easypost_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+	"github.com/dukerupert/paddy-cap/service/shipping/easypost"
+	"github.com/dukerupert/paddy-cap/service/transport"
+)
+
+// newTestClient serves canned EasyPost responses, recording each request body by path
+func newTestClient(t *testing.T) (*easypost.Client, map[string]map[string]any) {
+	t.Helper()
+	bodies := map[string]map[string]any{}
+	mux := http.NewServeMux()
+	handle := func(pattern, response string) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if user, _, _ := r.BasicAuth(); user != "EZTK_test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":{"code":"APIKEY.INACTIVE","message":"This api key is no longer active."}}`))
+				return
+			}
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			bodies[r.URL.Path] = body
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(response))
+		})
+	}
+	handle("POST /v2/shipments", `{"id":"shp_1","rates":[
+		{"id":"rate_1","carrier":"RoyalMail","service":"RoyalMail2ndClass","rate":"3.85","currency":"GBP","delivery_days":2},
+		{"id":"rate_2","carrier":"RoyalMail","service":"RoyalMail1stClass","rate":"4.60","currency":"GBP","delivery_days":1}]}`)
+	handle("POST /v2/shipments/shp_1/buy", `{"id":"shp_1","tracking_code":"RM123456785GB",
+		"selected_rate":{"id":"rate_1","carrier":"RoyalMail","service":"RoyalMail2ndClass","rate":"3.85","currency":"GBP"},
+		"postage_label":{"label_url":"https://easypost-files.example/label.png"},
+		"tracker":{"public_url":"https://track.easypost.com/djE6"}}`)
+	handle("POST /v2/shipments/shp_1/refund", `{"id":"shp_1","refund_status":"submitted"}`)
+	handle("POST /v2/shipments/shp_2/refund", `{"id":"shp_2","refund_status":"rejected"}`)
+	handle("POST /v2/trackers", `{"tracking_code":"RM123456785GB","status":"in_transit","est_delivery_date":"2026-10-17T00:00:00Z",
+		"tracking_details":[
+			{"datetime":"2026-10-15T09:00:00Z","status":"pre_transit","message":"Label created"},
+			{"datetime":"2026-10-15T18:30:00Z","status":"in_transit","message":"Collected","tracking_location":{"city":"Leeds","country":"GB"}}]}`)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client := easypost.NewClient("EZTK_test")
+	client.BaseURL = srv.URL
+	return client, bodies
+}
+
+func TestQuote(t *testing.T) {
+	client, bodies := newTestClient(t)
+
+	quote, err := client.Quote(context.Background(), shipping.Shipment{
+		From:      shipping.Address{Company: "Paddy Cap", Line1: "1 Mill Lane", City: "Leeds", PostalCode: "LS1 1AA", Country: "GB"},
+		To:        shipping.Address{Name: "Ada", Line1: "2 High Street", City: "York", PostalCode: "YO1 7HH", Country: "GB"},
+		Parcel:    shipping.Parcel{WeightGrams: 500, LengthCM: 30, WidthCM: 20, HeightCM: 5},
+		Reference: "#1001",
+	})
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if quote.ID != "shp_1" || len(quote.Rates) != 2 {
+		t.Fatalf("quote = %+v, want shp_1 with 2 rates", quote)
+	}
+	if got, want := quote.Rates[1].Price, money.New(460, "GBP"); got != want {
+		t.Errorf("rate price = %v, want %v", got, want)
+	}
+
+	sent := bodies["/v2/shipments"]["shipment"].(map[string]any)
+	parcel := sent["parcel"].(map[string]any)
+	if parcel["weight"] != 17.7 || parcel["length"] != 11.9 {
+		t.Errorf("parcel = %v, want weight 17.7oz and length 11.9in", parcel)
+	}
+	if to := sent["to_address"].(map[string]any); to["street1"] != "2 High Street" || to["zip"] != "YO1 7HH" {
+		t.Errorf("to_address = %v", to)
+	}
+	if sent["reference"] != "#1001" {
+		t.Errorf("reference = %v, want #1001", sent["reference"])
+	}
+}
+
+func TestQuoteRejectsParcel(t *testing.T) {
+	client, bodies := newTestClient(t)
+
+	_, err := client.Quote(context.Background(), shipping.Shipment{})
+	var shipErr *shipping.ShipmentError
+	if !errors.As(err, &shipErr) || shipErr.Field != "weight_grams" {
+		t.Fatalf("err = %v, want weight_grams ShipmentError", err)
+	}
+	if len(bodies) != 0 {
+		t.Errorf("sent %d requests, want none", len(bodies))
+	}
+}
+
+func TestBuyLabel(t *testing.T) {
+	client, bodies := newTestClient(t)
+
+	label, err := client.BuyLabel(context.Background(), "shp_1", "rate_1")
+	if err != nil {
+		t.Fatalf("BuyLabel: %v", err)
+	}
+	want := shipping.Label{
+		ID:             "shp_1",
+		Carrier:        "RoyalMail",
+		Service:        "RoyalMail2ndClass",
+		TrackingNumber: "RM123456785GB",
+		TrackingURL:    "https://track.easypost.com/djE6",
+		LabelURL:       "https://easypost-files.example/label.png",
+		Price:          money.New(385, "GBP"),
+	}
+	if *label != want {
+		t.Errorf("label = %+v, want %+v", *label, want)
+	}
+	if rate := bodies["/v2/shipments/shp_1/buy"]["rate"].(map[string]any); rate["id"] != "rate_1" {
+		t.Errorf("bought rate = %v, want rate_1", rate)
+	}
+}
+
+func TestVoidLabel(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	if err := client.VoidLabel(context.Background(), "shp_1"); err != nil {
+		t.Errorf("VoidLabel(shp_1): %v", err)
+	}
+	if err := client.VoidLabel(context.Background(), "shp_2"); err == nil {
+		t.Error("VoidLabel(shp_2) succeeded, want rejected refund error")
+	}
+}
+
+func TestTrack(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	tracking, err := client.Track(context.Background(), "RM123456785GB")
+	if err != nil {
+		t.Fatalf("Track: %v", err)
+	}
+	if tracking.Status != "in_transit" || tracking.EstimatedDelivery.IsZero() {
+		t.Errorf("tracking = %+v, want in_transit with an estimated delivery", tracking)
+	}
+	if len(tracking.Events) != 2 || tracking.Events[0].Description != "Collected" || tracking.Events[0].Location != "Leeds, GB" {
+		t.Errorf("events = %+v, want newest first with location", tracking.Events)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	client, _ := newTestClient(t)
+	client.APIKey = "EZTK_revoked"
+
+	_, err := client.Track(context.Background(), "RM123456785GB")
+	var apiErr *transport.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Channel != easypost.Channel {
+		t.Fatalf("err = %v, want 401 transport.Error", err)
+	}
+	var epErr *easypost.Error
+	if !errors.As(err, &epErr) || epErr.Code != "APIKEY.INACTIVE" {
+		t.Errorf("err = %v, want APIKEY.INACTIVE", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package shipping buys postage for orders through a carrier's API.
+//
+// Carrier is implemented by an adapter per provider, such as the easypost
+// package, and by shippingtest.Carrier for tests. Addresses are normalised
+// with NormalizeAddress before being quoted, so every adapter sees the same
+// shape whichever sales channel the order came from.
+package shipping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/money"
+)
+
+// Carrier quotes, buys and tracks shipping labels through one provider
+type Carrier interface {
+	// Name identifies the provider, e.g. "easypost", and is stored with each label
+	Name() string
+	// Quote returns the services available for a shipment and their prices.
+	// The quote's ID and one of its rates' IDs buy a label.
+	Quote(ctx context.Context, shipment Shipment) (*Quote, error)
+	// BuyLabel pays for the rate from a quote and returns the label to print
+	BuyLabel(ctx context.Context, quoteID, rateID string) (*Label, error)
+	// VoidLabel cancels an unused label so its cost is refunded
+	VoidLabel(ctx context.Context, labelID string) error
+	// Track returns where a parcel has got to
+	Track(ctx context.Context, trackingNumber string) (*Tracking, error)
+}
+
+// Errors carriers return for references they don't know or can't act on
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	ErrRateNotFound  = errors.New("rate not found")
+	ErrLabelNotFound = errors.New("label not found")
+	ErrLabelVoided   = errors.New("label already voided")
+)
+
+// Address is where a parcel is sent from or to. Country is an ISO 3166 alpha-2 code.
+type Address struct {
+	Name       string `json:"name,omitempty"`
+	Company    string `json:"company,omitempty"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Email      string `json:"email,omitempty"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+// Parcel is the size and weight of what is being sent. Dimensions are optional.
+type Parcel struct {
+	WeightGrams int `json:"weight_grams"`
+	LengthCM    int `json:"length_cm,omitempty"`
+	WidthCM     int `json:"width_cm,omitempty"`
+	HeightCM    int `json:"height_cm,omitempty"`
+}
+
+// Valid checks the parcel has a weight and no negative dimensions
+func (p Parcel) Valid() error {
+	switch {
+	case p.WeightGrams <= 0:
+		return &ShipmentError{Field: "weight_grams", Problem: "must be more than zero"}
+	case p.LengthCM < 0 || p.WidthCM < 0 || p.HeightCM < 0:
+		return &ShipmentError{Field: "dimensions", Problem: "must not be negative"}
+	}
+	return nil
+}
+
+// Shipment is a parcel to be sent between two addresses
+type Shipment struct {
+	From      Address `json:"from"`
+	To        Address `json:"to"`
+	Parcel    Parcel  `json:"parcel"`
+	Reference string  `json:"reference,omitempty"` // printed on the label, e.g. the order number
+}
+
+// Quote is the set of rates offered for a shipment
+type Quote struct {
+	ID    string `json:"id"`
+	Rates []Rate `json:"rates"`
+}
+
+// Rate is the price of sending a shipment with one service
+type Rate struct {
+	ID           string      `json:"id"`
+	Carrier      string      `json:"carrier"` // the delivery company, e.g. "Royal Mail"
+	Service      string      `json:"service"` // e.g. "Tracked 48"
+	Price        money.Money `json:"price"`
+	DeliveryDays int         `json:"delivery_days,omitempty"` // zero when the carrier doesn't say
+}
+
+// Label is a paid shipping label
+type Label struct {
+	ID             string      `json:"id"`
+	Carrier        string      `json:"carrier"`
+	Service        string      `json:"service"`
+	TrackingNumber string      `json:"tracking_number"`
+	TrackingURL    string      `json:"tracking_url,omitempty"`
+	LabelURL       string      `json:"label_url"` // the label to print, usually a PDF or PNG
+	Price          money.Money `json:"price"`
+}
+
+// Tracking is a parcel's progress, newest event first
+type Tracking struct {
+	TrackingNumber    string          `json:"tracking_number"`
+	Status            string          `json:"status"`                      // e.g. "in_transit", "delivered"
+	EstimatedDelivery time.Time       `json:"estimated_delivery,omitzero"` // zero when unknown
+	Events            []TrackingEvent `json:"events"`
+}
+
+// TrackingEvent is one scan or update on a parcel's journey
+type TrackingEvent struct {
+	Time        time.Time `json:"time"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location,omitempty"`
+}
+
+// ShipmentError explains why a shipment can't be quoted, naming the field at fault
+type ShipmentError struct {
+	Field   string
+	Problem string
+}
+
+func (e *ShipmentError) Error() string {
+	return fmt.Sprintf("invalid shipment: %s %s", e.Field, e.Problem)
+}
@@ -0,0 +1,53 @@
+package shipping
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	got, err := NormalizeAddress(Address{
+		Name:       "  Alex   Morgan ",
+		Line1:      "",
+		Line2:      "12 High  Street",
+		City:       "Bristol",
+		PostalCode: "bs14dj",
+		Country:    "United Kingdom",
+	})
+	want := Address{Name: "Alex Morgan", Line1: "12 High Street", City: "Bristol", PostalCode: "BS1 4DJ", Country: "GB"}
+	if err != nil || got != want {
+		t.Errorf("NormalizeAddress = %+v, %v, want %+v", got, err, want)
+	}
+
+	if got, err := NormalizeAddress(Address{Company: "Café Lumière", Line1: "4 Rue Oberkampf", City: "Paris", PostalCode: "75011", Country: "fr"}); err != nil || got.Country != "FR" {
+		t.Errorf("french address = %+v, %v, want country FR", got, err)
+	}
+	if _, err := NormalizeAddress(Address{Name: "Aoife", Line1: "1 Quay St", City: "Galway", Country: "Ireland"}); err != nil {
+		t.Errorf("irish address without an Eircode: %v", err)
+	}
+
+	invalid := map[string]Address{
+		"country":     {Name: "A", Line1: "1 St", City: "Leeds", PostalCode: "LS1 1AA", Country: "Narnia"},
+		"postal_code": {Name: "A", Line1: "1 St", City: "Leeds", PostalCode: "12345", Country: "GB"},
+		"name":        {Line1: "1 St", City: "Leeds", PostalCode: "LS1 1AA", Country: "GB"},
+		"line1":       {Name: "A", City: "Leeds", PostalCode: "LS1 1AA", Country: "GB"},
+		"city":        {Name: "A", Line1: "1 St", PostalCode: "LS1 1AA", Country: "GB"},
+	}
+	for field, a := range invalid {
+		var se *ShipmentError
+		if _, err := NormalizeAddress(a); !errors.As(err, &se) || se.Field != field {
+			t.Errorf("NormalizeAddress(%+v) error = %v, want a %s ShipmentError", a, err, field)
+		}
+	}
+}
+
+func TestParcelValid(t *testing.T) {
+	if err := (Parcel{WeightGrams: 1200, LengthCM: 30}).Valid(); err != nil {
+		t.Errorf("Valid() = %v, want nil", err)
+	}
+	for _, p := range []Parcel{{}, {WeightGrams: -5}, {WeightGrams: 100, HeightCM: -1}} {
+		if err := p.Valid(); err == nil {
+			t.Errorf("%+v.Valid() = nil, want an error", p)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package shippingtest provides an in-memory shipping.Carrier for tests.
+//
+// The fake quotes two services priced by weight, buys labels with made-up
+// tracking numbers, voids them and reports voided labels as cancelled when
+// tracked. It never calls out to a real carrier.
+package shippingtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/money"
+	"github.com/dukerupert/paddy-cap/service/shipping"
+)
+
+// Name is the provider name the fake reports
+const Name = "fake"
+
+// Services offered by the fake, with their base price and price per started kilo in pence
+var services = []struct {
+	name     string
+	base, kg int64
+	days     int
+}{
+	{"Tracked 48", 350, 100, 2},
+	{"Tracked 24", 450, 150, 1},
+}
+
+// Carrier is a fake shipping.Carrier. Its zero value is not usable; call NewCarrier.
+type Carrier struct {
+	mu     sync.Mutex
+	quotes map[string]*quote
+	labels map[string]*label
+	next   int
+}
+
+type quote struct {
+	shipment shipping.Shipment
+	rates    []shipping.Rate
+	bought   bool
+}
+
+type label struct {
+	shipping.Label
+	shipment shipping.Shipment
+	boughtAt time.Time
+	voided   bool
+}
+
+// NewCarrier returns a fake carrier with no quotes or labels
+func NewCarrier() *Carrier {
+	return &Carrier{quotes: map[string]*quote{}, labels: map[string]*label{}}
+}
+
+// Name returns "fake"
+func (c *Carrier) Name() string {
+	return Name
+}
+
+// Quote prices each service by the parcel's weight
+func (c *Carrier) Quote(ctx context.Context, shipment shipping.Shipment) (*shipping.Quote, error) {
+	if err := shipment.Parcel.Valid(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID("quo")
+	kilos := int64((shipment.Parcel.WeightGrams + 999) / 1000)
+	q := &quote{shipment: shipment}
+	for i, s := range services {
+		q.rates = append(q.rates, shipping.Rate{
+			ID:           fmt.Sprintf("%s_rate%d", id, i+1),
+			Carrier:      "Fake Post",
+			Service:      s.name,
+			Price:        money.New(s.base+s.kg*kilos, "GBP"),
+			DeliveryDays: s.days,
+		})
+	}
+	c.quotes[id] = q
+	return &shipping.Quote{ID: id, Rates: slices.Clone(q.rates)}, nil
+}
+
+// BuyLabel buys a rate from a quote. Each quote can be bought once.
+func (c *Carrier) BuyLabel(ctx context.Context, quoteID, rateID string) (*shipping.Label, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	q, ok := c.quotes[quoteID]
+	if !ok || q.bought {
+		return nil, shipping.ErrQuoteNotFound
+	}
+	i := slices.IndexFunc(q.rates, func(r shipping.Rate) bool { return r.ID == rateID })
+	if i < 0 {
+		return nil, shipping.ErrRateNotFound
+	}
+	rate := q.rates[i]
+	q.bought = true
+
+	id := c.nextID("lbl")
+	tracking := fmt.Sprintf("FP%09dGB", c.next)
+	l := &label{
+		Label: shipping.Label{
+			ID:             id,
+			Carrier:        rate.Carrier,
+			Service:        rate.Service,
+			TrackingNumber: tracking,
+			TrackingURL:    "https://tracking.example/" + tracking,
+			LabelURL:       "https://labels.example/" + id + ".pdf",
+			Price:          rate.Price,
+		},
+		shipment: q.shipment,
+		boughtAt: time.Now(),
+	}
+	c.labels[id] = l
+	label := l.Label
+	return &label, nil
+}
+
+// VoidLabel cancels a label that hasn't already been voided
+func (c *Carrier) VoidLabel(ctx context.Context, labelID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.labels[labelID]
+	switch {
+	case !ok:
+		return shipping.ErrLabelNotFound
+	case l.voided:
+		return shipping.ErrLabelVoided
+	}
+	l.voided = true
+	return nil
+}
+
+// Track reports a bought label as waiting for collection, or cancelled once voided
+func (c *Carrier) Track(ctx context.Context, trackingNumber string) (*shipping.Tracking, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, l := range c.labels {
+		if l.TrackingNumber != trackingNumber {
+			continue
+		}
+		tracking := &shipping.Tracking{
+			TrackingNumber: trackingNumber,
+			Status:         "pre_transit",
+			Events: []shipping.TrackingEvent{{
+				Time:        l.boughtAt,
+				Status:      "pre_transit",
+				Description: "Label created, awaiting collection",
+				Location:    l.shipment.From.City,
+			}},
+		}
+		if l.voided {
+			tracking.Status = "cancelled"
+		}
+		return tracking, nil
+	}
+	return nil, shipping.ErrLabelNotFound
+}
+
+// Labels returns every label bought, voided or not, in no particular order
+func (c *Carrier) Labels() []shipping.Label {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	labels := make([]shipping.Label, 0, len(c.labels))
+	for _, l := range c.labels {
+		labels = append(labels, l.Label)
+	}
+	return labels
+}
+
+// Voided reports whether the label has been voided
+func (c *Carrier) Voided(labelID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.labels[labelID]
+	return ok && l.voided
+}
+
+// nextID returns a new ID with the given prefix. c.mu must be held.
+func (c *Carrier) nextID(prefix string) string {
+	c.next++
+	return fmt.Sprintf("%s_test%04d", prefix, c.next)
+}
+
+var _ shipping.Carrier = (*Carrier)(nil)
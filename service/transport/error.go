@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxBodyExcerpt is how much of a failed response body is kept on an Error
+const maxBodyExcerpt = 512
+
+// Error is returned by the API clients for any failed request, whether the
+// channel answered with an error status or could not be reached at all.
+// Channel-specific error details, when the body could be decoded, are
+// available through Unwrap.
+type Error struct {
+	Channel    string // sales channel the request was sent to
+	Method     string
+	Endpoint   string
+	StatusCode int    // zero when no response was received
+	RequestID  string // request ID reported by the channel, if any
+	Body       string // excerpt of the response body
+	Retryable  bool   // whether the same request may succeed if sent again later
+	Err        error  // decoded channel error or underlying network error
+}
+
+// NewError builds an Error from a failed request. resp and body may be nil
+// when no response was received.
+func NewError(channel, method, endpoint string, resp *http.Response, body []byte, err error) *Error {
+	e := &Error{
+		Channel:   channel,
+		Method:    method,
+		Endpoint:  endpoint,
+		Body:      excerpt(body),
+		Retryable: resp == nil && !errors.Is(err, context.Canceled),
+		Err:       err,
+	}
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		e.RequestID = requestID(resp.Header)
+		e.Retryable = IsRetryableStatus(resp.StatusCode)
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", e.Channel, e.Method, e.Endpoint)
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, ": HTTP %d", e.StatusCode)
+	}
+	if e.Err != nil {
+		b.WriteString(": " + e.Err.Error())
+	} else if e.Body != "" {
+		b.WriteString(": " + e.Body)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryableStatus reports whether a response status indicates a transient failure
+func IsRetryableStatus(status int) bool {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return true
+	case status == http.StatusNotImplemented:
+		return false
+	default:
+		return status >= 500
+	}
+}
+
+// requestID returns the identifier the channel, or the CDN in front of it, assigned to the request
+func requestID(h http.Header) string {
+	for _, key := range []string{"X-Request-Id", "Cf-Ray"} {
+		if v := h.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func excerpt(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > maxBodyExcerpt {
+		return strings.ToValidUTF8(s[:maxBodyExcerpt], "") + "…"
+	}
+	return s
+}
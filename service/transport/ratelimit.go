@@ -0,0 +1,58 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit is an http.RoundTripper that holds requests back so they stay
+// within a token-bucket limit shared by every goroutine using the client
+type RateLimit struct {
+	Base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// NewRateLimit wraps base, or http.DefaultTransport if base is nil.
+// A non-positive rps disables limiting.
+func NewRateLimit(base http.RoundTripper, rps float64, burst int) *RateLimit {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &RateLimit{Base: base, limiter: rate.NewLimiter(rate.Inf, 0)}
+	t.SetLimit(rps, burst)
+	return t
+}
+
+// SetLimit changes the allowed requests per second and burst size.
+// A non-positive rps disables limiting.
+func (t *RateLimit) SetLimit(rps float64, burst int) {
+	if rps <= 0 {
+		t.limiter.SetLimit(rate.Inf)
+		return
+	}
+	t.limiter.SetBurst(max(burst, 1))
+	t.limiter.SetLimit(rate.Limit(rps))
+}
+
+// Wait reports how long a request made now would be held back, zero when not throttled
+func (t *RateLimit) Wait() time.Duration {
+	limit := t.limiter.Limit()
+	if limit == rate.Inf {
+		return 0
+	}
+	tokens := t.limiter.Tokens()
+	if tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / float64(limit) * float64(time.Second))
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RateLimit) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.Base.RoundTrip(req)
+}
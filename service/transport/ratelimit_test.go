@@ -0,0 +1,57 @@
+package transport_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/transport"
+)
+
+func TestRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	rl := transport.NewRateLimit(nil, 20, 2)
+	client := &http.Client{Transport: rl}
+
+	if wait := rl.Wait(); wait != 0 {
+		t.Errorf("Wait before any requests = %v, want 0", wait)
+	}
+
+	start := time.Now()
+	for range 4 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The burst of 2 goes straight through, the next 2 wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 90ms", elapsed)
+	}
+	if wait := rl.Wait(); wait <= 0 {
+		t.Errorf("Wait after exhausting the burst = %v, want > 0", wait)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	rl := transport.NewRateLimit(nil, 0, 0)
+	client := &http.Client{Transport: rl}
+	for range 10 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if wait := rl.Wait(); wait != 0 {
+		t.Errorf("Wait with limiting disabled = %v, want 0", wait)
+	}
+}
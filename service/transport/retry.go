@@ -0,0 +1,191 @@
+// Package transport provides the HTTP plumbing shared by the sales channel API clients
+package transport
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderRetryAfter mirrors server.HeaderRetryAfter, which this package cannot import
+const HeaderRetryAfter = "Retry-After"
+
+// RetryConfig controls how failed requests are retried
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on each subsequent retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than this is not waited for.
+	MaxDelay time.Duration
+	// AttemptTimeout limits each attempt, from sending the request to reading
+	// the end of the response body, so a hung attempt still leaves time for
+	// the retries. Zero means no limit.
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryConfig is used by both API clients
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+	// The clients set no overall timeout, so a request can take up to
+	// (MaxRetries+1) attempts plus the backoff between them
+	AttemptTimeout: 30 * time.Second,
+}
+
+// Retry is an http.RoundTripper that retries idempotent requests on network
+// errors, 429 and 5xx responses with jittered exponential backoff, honouring
+// any Retry-After header the server sends
+type Retry struct {
+	Base    http.RoundTripper
+	cfg     RetryConfig
+	retries atomic.Int64
+}
+
+// NewRetry wraps base, or http.DefaultTransport if base is nil
+func NewRetry(base http.RoundTripper, cfg RetryConfig) *Retry {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Retry{Base: base, cfg: cfg}
+}
+
+// SetAttemptTimeout changes RetryConfig.AttemptTimeout. It is not safe to
+// call while requests are in flight.
+func (t *Retry) SetAttemptTimeout(timeout time.Duration) {
+	t.cfg.AttemptTimeout = timeout
+}
+
+// Retries reports how many retries this transport has made in total
+func (t *Retry) Retries() int64 {
+	return t.retries.Load()
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.attempt(req)
+		if !retryable || attempt >= t.cfg.MaxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get(HeaderRetryAfter)); ok {
+				if after > t.cfg.MaxDelay {
+					return resp, nil
+				}
+				delay = after
+			}
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		t.retries.Add(1)
+		if c, ok := ctx.Value(counterKey{}).(*atomic.Int64); ok {
+			c.Add(1)
+		}
+	}
+}
+
+// attempt sends req once, within the attempt timeout. The timeout keeps
+// running until the response body is closed.
+func (t *Retry) attempt(req *http.Request) (*http.Response, error) {
+	if t.cfg.AttemptTimeout <= 0 {
+		return t.Base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.cfg.AttemptTimeout)
+	resp, err := t.Base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody ends an attempt's timeout once its response body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// backoff returns the delay before the given retry: exponential, capped at
+// MaxDelay, with equal jitter so concurrent clients don't retry in lockstep
+func (t *Retry) backoff(attempt int) time.Duration {
+	d := t.cfg.BaseDelay << attempt
+	if d <= 0 || d > t.cfg.MaxDelay {
+		d = t.cfg.MaxDelay
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+type counterKey struct{}
+
+// CountRetries returns a context that records the retries made for requests
+// sent with it, and a func reporting the count so far
+func CountRetries(ctx context.Context) (context.Context, func() int) {
+	c := new(atomic.Int64)
+	return context.WithValue(ctx, counterKey{}, c), func() int { return int(c.Load()) }
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// A cancelled or expired context is the caller giving up, not a transient failure
+		return ctx.Err() == nil
+	}
+	return IsRetryableStatus(resp.StatusCode)
+}
+
+// retryAfter parses a Retry-After value given either in seconds or as an HTTP date
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,174 @@
+package transport_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/transport"
+)
+
+var testConfig = transport.RetryConfig{
+	MaxRetries: 3,
+	BaseDelay:  time.Millisecond,
+	MaxDelay:   50 * time.Millisecond,
+}
+
+// flakyServer fails the first n requests with the given status and headers, then succeeds
+func flakyServer(t *testing.T, n int, status int, header http.Header) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if calls.Add(1) <= int64(n) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		failures    int
+		status      int
+		header      http.Header
+		wantStatus  int
+		wantCalls   int64
+		wantRetries int
+	}{
+		{name: "success is not retried", method: http.MethodGet, failures: 0, status: http.StatusOK, wantStatus: http.StatusOK, wantCalls: 1},
+		{name: "502 is retried", method: http.MethodGet, failures: 1, status: http.StatusBadGateway, wantStatus: http.StatusOK, wantCalls: 2, wantRetries: 1},
+		{name: "429 is retried", method: http.MethodGet, failures: 2, status: http.StatusTooManyRequests, wantStatus: http.StatusOK, wantCalls: 3, wantRetries: 2},
+		{name: "put is retried", method: http.MethodPut, failures: 1, status: http.StatusServiceUnavailable, wantStatus: http.StatusOK, wantCalls: 2, wantRetries: 1},
+		{name: "gives up after max retries", method: http.MethodGet, failures: 10, status: http.StatusInternalServerError, wantStatus: http.StatusInternalServerError, wantCalls: 4, wantRetries: 3},
+		{name: "post is not retried", method: http.MethodPost, failures: 1, status: http.StatusBadGateway, wantStatus: http.StatusBadGateway, wantCalls: 1},
+		{name: "4xx is not retried", method: http.MethodGet, failures: 1, status: http.StatusNotFound, wantStatus: http.StatusNotFound, wantCalls: 1},
+		{name: "501 is not retried", method: http.MethodGet, failures: 1, status: http.StatusNotImplemented, wantStatus: http.StatusNotImplemented, wantCalls: 1},
+		{
+			name: "long Retry-After is not waited for", method: http.MethodGet, failures: 1, status: http.StatusTooManyRequests,
+			header:     http.Header{transport.HeaderRetryAfter: {"120"}},
+			wantStatus: http.StatusTooManyRequests, wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := flakyServer(t, tt.failures, tt.status, tt.header)
+			rt := transport.NewRetry(nil, testConfig)
+			client := &http.Client{Transport: rt}
+
+			ctx, retries := transport.CountRetries(context.Background())
+			req, err := http.NewRequestWithContext(ctx, tt.method, srv.URL, strings.NewReader(`{"a":1}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", n, tt.wantCalls)
+			}
+			if n := retries(); n != tt.wantRetries {
+				t.Errorf("counted retries = %d, want %d", n, tt.wantRetries)
+			}
+			if n := rt.Retries(); n != int64(tt.wantRetries) {
+				t.Errorf("transport retries = %d, want %d", n, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	srv, _ := flakyServer(t, 1, http.StatusServiceUnavailable, http.Header{transport.HeaderRetryAfter: {"1"}})
+	client := &http.Client{Transport: transport.NewRetry(nil, transport.RetryConfig{
+		MaxRetries: 1,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   5 * time.Second,
+	})}
+
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+}
+
+func TestRetryStopsWhenContextCancelled(t *testing.T) {
+	srv, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	client := &http.Client{Transport: transport.NewRetry(nil, transport.RetryConfig{
+		MaxRetries: 5,
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Second,
+	})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("Do succeeded, want context error")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server calls = %d, want 1", n)
+	}
+}
+
+func TestRetryTimesOutEachAttempt(t *testing.T) {
+	var calls atomic.Int64
+	hung := make(chan struct{})
+	defer close(hung)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-hung:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: transport.NewRetry(nil, transport.RetryConfig{
+		MaxRetries:     1,
+		BaseDelay:      time.Millisecond,
+		MaxDelay:       time.Millisecond,
+		AttemptTimeout: 100 * time.Millisecond,
+	})}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	// The second attempt's timeout runs until its body has been read
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("body = %q, %v, want the retry's response", body, err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("server calls = %d, want the hung attempt retried once", n)
+	}
+}
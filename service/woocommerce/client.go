@@ -2,6 +2,7 @@ package woocommerce
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,8 @@ import (
 	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/dukerupert/paddy-cap/service/transport"
 )
 
 // Client represents a WooCommerce REST API client
@@ -18,9 +21,15 @@ type Client struct {
 	ConsumerSecret string
 	HTTPClient     *http.Client
 	Version        string // API version, defaults to "v3"
+	retry          *transport.Retry
+	limiter        *transport.RateLimit
 }
 
-// Error represents a WooCommerce API error response
+// Channel identifies WooCommerce in errors returned by the client
+const Channel = "woocommerce"
+
+// Error represents a WooCommerce API error response.
+// It is returned wrapped in a *transport.Error carrying the HTTP status.
 type Error struct {
 	Code    string      `json:"code"`
 	Message string      `json:"message"`
@@ -44,6 +53,7 @@ type Response struct {
 	Data       interface{}
 	Pagination *PaginationInfo
 	Headers    http.Header
+	Retries    int // Retries made before this response was received
 }
 
 // RequestOptions holds optional parameters for API requests
@@ -56,20 +66,51 @@ type RequestOptions struct {
 
 // NewClient creates a new WooCommerce client
 func NewClient(baseURL, consumerKey, consumerSecret string) *Client {
+	limiter := transport.NewRateLimit(http.DefaultTransport, 0, 0)
+	retry := transport.NewRetry(limiter, transport.DefaultRetryConfig)
 	return &Client{
 		BaseURL:        baseURL,
 		ConsumerKey:    consumerKey,
 		ConsumerSecret: consumerSecret,
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: retry,
 		},
 		Version: "v3",
+		retry:   retry,
+		limiter: limiter,
 	}
 }
 
-// SetTimeout sets the HTTP client timeout
+// SetTimeout sets how long each attempt at a request may take. Retries get
+// their own time, so a request can take longer in all.
 func (c *Client) SetTimeout(timeout time.Duration) {
-	c.HTTPClient.Timeout = timeout
+	if c.retry != nil {
+		c.retry.SetAttemptTimeout(timeout)
+	}
+}
+
+// SetRateLimit limits requests to rps per second with the given burst.
+// A non-positive rps removes the limit.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	if c.limiter != nil {
+		c.limiter.SetLimit(rps, burst)
+	}
+}
+
+// RateLimitWait reports how long a request made now would wait for the rate limiter
+func (c *Client) RateLimitWait() time.Duration {
+	if c.limiter == nil {
+		return 0
+	}
+	return c.limiter.Wait()
+}
+
+// Retries reports how many requests have been retried since the client was created
+func (c *Client) Retries() int64 {
+	if c.retry == nil {
+		return 0
+	}
+	return c.retry.Retries()
 }
 
 // SetVersion sets the API version (default is v3)
@@ -114,8 +155,9 @@ func (c *Client) addAuth(req *http.Request) {
 }
 
 // makeRequest performs the HTTP request and handles the response
-func (c *Client) makeRequest(method, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
 	url := c.buildURL(endpoint, options)
+	ctx, retries := transport.CountRetries(ctx)
 
 	var reqBody io.Reader
 	if body != nil {
@@ -126,7 +168,7 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -141,7 +183,7 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 	// Make the request
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, transport.NewError(Channel, method, endpoint, nil, nil, err)
 	}
 	defer resp.Body.Close()
 
@@ -153,15 +195,20 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 
 	// Handle error responses
 	if resp.StatusCode >= 400 {
+		var apiErr error
 		var wcError Error
-		if err := json.Unmarshal(respBody, &wcError); err != nil {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		if err := json.Unmarshal(respBody, &wcError); err == nil && wcError.Code != "" {
+			apiErr = &wcError
 		}
-		return nil, &wcError
+		return nil, transport.NewError(Channel, method, endpoint, resp, respBody, apiErr)
 	}
 
 	// Parse pagination info from headers
 	pagination := &PaginationInfo{}
+	if options != nil {
+		pagination.Page = options.Page
+		pagination.PerPage = options.PerPage
+	}
 	if totalStr := resp.Header.Get("X-WP-Total"); totalStr != "" {
 		if total, err := strconv.Atoi(totalStr); err == nil {
 			pagination.Total = total
@@ -177,6 +224,7 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 	response := &Response{
 		Pagination: pagination,
 		Headers:    resp.Header,
+		Retries:    retries(),
 	}
 
 	// Parse JSON response into Data field
@@ -192,31 +240,31 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, options
 }
 
 // GET performs a GET request
-func (c *Client) GET(endpoint string, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("GET", endpoint, nil, options)
+func (c *Client) GET(ctx context.Context, endpoint string, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "GET", endpoint, nil, options)
 }
 
 // POST performs a POST request
-func (c *Client) POST(endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("POST", endpoint, body, options)
+func (c *Client) POST(ctx context.Context, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "POST", endpoint, body, options)
 }
 
 // PUT performs a PUT request
-func (c *Client) PUT(endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("PUT", endpoint, body, options)
+func (c *Client) PUT(ctx context.Context, endpoint string, body interface{}, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "PUT", endpoint, body, options)
 }
 
 // DELETE performs a DELETE request
-func (c *Client) DELETE(endpoint string, options *RequestOptions) (*Response, error) {
-	return c.makeRequest("DELETE", endpoint, nil, options)
+func (c *Client) DELETE(ctx context.Context, endpoint string, options *RequestOptions) (*Response, error) {
+	return c.makeRequest(ctx, "DELETE", endpoint, nil, options)
 }
 
 // GetWithPagination is a helper method for paginated GET requests
-func (c *Client) GetWithPagination(endpoint string, page, perPage int, params map[string]string) (*Response, error) {
+func (c *Client) GetWithPagination(ctx context.Context, endpoint string, page, perPage int, params map[string]string) (*Response, error) {
 	options := &RequestOptions{
 		Page:    page,
 		PerPage: perPage,
 		Params:  params,
 	}
-	return c.GET(endpoint, options)
+	return c.GET(ctx, endpoint, options)
 }
\ No newline at end of file
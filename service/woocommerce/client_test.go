@@ -0,0 +1,601 @@
+package woocommerce_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/dukerupert/paddy-cap/service/transport"
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+	"github.com/dukerupert/paddy-cap/service/woocommerce/woocommercetest"
+)
+
+func newTestClient(t *testing.T) (*woocommercetest.Server, *woocommerce.Client) {
+	t.Helper()
+	srv := woocommercetest.NewServer(woocommercetest.Orders(), woocommercetest.Customers(), woocommercetest.Products(), woocommercetest.Variations())
+	t.Cleanup(srv.Close)
+	return srv, srv.NewClient()
+}
+
+func orderIDs(orders []woocommerce.Order) []int {
+	var ids []int
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+func TestListOrders(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        *woocommerce.OrderListOptions
+		wantIDs        []int
+		wantTotal      int
+		wantTotalPages int
+	}{
+		{
+			name:           "defaults to newest first",
+			options:        nil,
+			wantIDs:        []int{5104, 5103, 5102, 5101},
+			wantTotal:      4,
+			wantTotalPages: 1,
+		},
+		{
+			name:           "first page",
+			options:        &woocommerce.OrderListOptions{Page: 1, PerPage: 3},
+			wantIDs:        []int{5104, 5103, 5102},
+			wantTotal:      4,
+			wantTotalPages: 2,
+		},
+		{
+			name:           "second page",
+			options:        &woocommerce.OrderListOptions{Page: 2, PerPage: 3},
+			wantIDs:        []int{5101},
+			wantTotal:      4,
+			wantTotalPages: 2,
+		},
+		{
+			name:           "oldest first",
+			options:        &woocommerce.OrderListOptions{OrderBy: "date", Order: "asc"},
+			wantIDs:        []int{5101, 5102, 5103, 5104},
+			wantTotal:      4,
+			wantTotalPages: 1,
+		},
+		{
+			name:           "filter by status",
+			options:        &woocommerce.OrderListOptions{Status: "processing"},
+			wantIDs:        []int{5104, 5102},
+			wantTotal:      2,
+			wantTotalPages: 1,
+		},
+		{
+			name:           "filter by date range",
+			options:        &woocommerce.OrderListOptions{After: "2025-03-11T00:00:00", Before: "2025-03-14T00:00:00"},
+			wantIDs:        []int{5103, 5102},
+			wantTotal:      2,
+			wantTotalPages: 1,
+		},
+		{
+			name:           "search by customer email",
+			options:        &woocommerce.OrderListOptions{Search: "jamie@"},
+			wantIDs:        []int{5104, 5102},
+			wantTotal:      2,
+			wantTotalPages: 1,
+		},
+		{
+			name:           "no matches",
+			options:        &woocommerce.OrderListOptions{Status: "refunded"},
+			wantIDs:        nil,
+			wantTotal:      0,
+			wantTotalPages: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newTestClient(t)
+
+			res, err := c.ListOrders(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("ListOrders: %v", err)
+			}
+			if got := orderIDs(res.Orders); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("order IDs = %v, want %v", got, tt.wantIDs)
+			}
+			if res.Pagination.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", res.Pagination.Total, tt.wantTotal)
+			}
+			if res.Pagination.TotalPages != tt.wantTotalPages {
+				t.Errorf("TotalPages = %d, want %d", res.Pagination.TotalPages, tt.wantTotalPages)
+			}
+		})
+	}
+}
+
+func TestAllOrders(t *testing.T) {
+	_, c := newTestClient(t)
+
+	var got []int
+	for o, err := range c.AllOrders(context.Background(), &woocommerce.OrderListOptions{PerPage: 1}) {
+		if err != nil {
+			t.Fatalf("AllOrders: %v", err)
+		}
+		got = append(got, o.ID)
+	}
+
+	want := []int{5104, 5103, 5102, 5101}
+	if !slices.Equal(got, want) {
+		t.Errorf("order IDs = %v, want %v", got, want)
+	}
+}
+
+func TestAllOrdersStopsWhenCallerBreaks(t *testing.T) {
+	_, c := newTestClient(t)
+
+	count := 0
+	for _, err := range c.AllOrders(context.Background(), &woocommerce.OrderListOptions{PerPage: 1}) {
+		if err != nil {
+			t.Fatalf("AllOrders: %v", err)
+		}
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("iterated %d orders after break, want 1", count)
+	}
+}
+
+func TestGetOrder(t *testing.T) {
+	_, c := newTestClient(t)
+
+	order, err := c.GetOrder(context.Background(), 5104)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if order.Billing.Email != "jamie@example.com" {
+		t.Errorf("Billing.Email = %q, want %q", order.Billing.Email, "jamie@example.com")
+	}
+	if len(order.LineItems) != 1 || order.LineItems[0].SKU != "ESP-250-WB" {
+		t.Errorf("LineItems = %+v, want one ESP-250-WB line", order.LineItems)
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	srv, c := newTestClient(t)
+
+	tests := []struct {
+		name       string
+		client     *woocommerce.Client
+		call       func(c *woocommerce.Client) error
+		wantCode   string
+		wantStatus int
+	}{
+		{
+			name:   "unknown order",
+			client: c,
+			call: func(c *woocommerce.Client) error {
+				_, err := c.GetOrder(context.Background(), 1)
+				return err
+			},
+			wantCode:   "woocommerce_rest_shop_order_invalid_id",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "bad credentials",
+			client: woocommerce.NewClient(srv.URL, woocommercetest.ConsumerKey, "wrong"),
+			call: func(c *woocommerce.Client) error {
+				_, err := c.ListOrders(context.Background(), nil)
+				return err
+			},
+			wantCode:   "woocommerce_rest_cannot_view",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "invalid parameter",
+			client: c,
+			call: func(c *woocommerce.Client) error {
+				_, err := c.ListOrders(context.Background(), &woocommerce.OrderListOptions{PerPage: 500})
+				return err
+			},
+			wantCode:   "rest_invalid_param",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.client)
+			var apiErr *woocommerce.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *woocommerce.Error", err)
+			}
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", apiErr.Code, tt.wantCode)
+			}
+			if apiErr.Message == "" {
+				t.Error("Message is empty")
+			}
+
+			var reqErr *transport.Error
+			if !errors.As(err, &reqErr) {
+				t.Fatalf("error = %v, want *transport.Error", err)
+			}
+			if reqErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", reqErr.StatusCode, tt.wantStatus)
+			}
+			if reqErr.Channel != woocommerce.Channel {
+				t.Errorf("Channel = %q, want %q", reqErr.Channel, woocommerce.Channel)
+			}
+			if reqErr.Retryable {
+				t.Error("client error reported as retryable")
+			}
+		})
+	}
+}
+
+func TestSubscriptionHelpers(t *testing.T) {
+	_, c := newTestClient(t)
+	orders := map[int]woocommerce.Order{}
+	for _, o := range woocommercetest.Orders() {
+		orders[o.ID] = o
+	}
+
+	tests := []struct {
+		id            int
+		wantSub       bool
+		wantRenewalID int
+		wantRenewal   bool
+		wantScheme    string
+	}{
+		{id: 5104, wantSub: false},
+		{id: 5102, wantSub: true, wantRenewalID: 812, wantRenewal: true},
+		{id: 5101, wantSub: true, wantScheme: "1_month"},
+	}
+
+	for _, tt := range tests {
+		order := orders[tt.id]
+		if got := c.IsSubscriptionOrder(&order); got != tt.wantSub {
+			t.Errorf("IsSubscriptionOrder(%d) = %v, want %v", tt.id, got, tt.wantSub)
+		}
+		id, ok := c.GetSubscriptionRenewalID(&order)
+		if id != tt.wantRenewalID || ok != tt.wantRenewal {
+			t.Errorf("GetSubscriptionRenewalID(%d) = %d, %v, want %d, %v", tt.id, id, ok, tt.wantRenewalID, tt.wantRenewal)
+		}
+		if got := c.GetSubscriptionScheme(&order); got != tt.wantScheme {
+			t.Errorf("GetSubscriptionScheme(%d) = %q, want %q", tt.id, got, tt.wantScheme)
+		}
+	}
+}
+
+func TestListSubscriptionOrders(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	subs, err := c.ListSubscriptionOrders(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSubscriptionOrders: %v", err)
+	}
+	if got, want := orderIDs(subs.Orders), []int{5102, 5101}; !slices.Equal(got, want) {
+		t.Errorf("subscription order IDs = %v, want %v", got, want)
+	}
+
+	renewals, err := c.ListSubscriptionRenewals(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSubscriptionRenewals: %v", err)
+	}
+	if got, want := orderIDs(renewals.Orders), []int{5102}; !slices.Equal(got, want) {
+		t.Errorf("renewal order IDs = %v, want %v", got, want)
+	}
+}
+
+func customerIDs(customers []woocommerce.Customer) []int {
+	var ids []int
+	for _, c := range customers {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestListCustomers(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   *woocommerce.CustomerListOptions
+		wantIDs   []int
+		wantTotal int
+	}{
+		{
+			name:      "defaults to name order",
+			options:   nil,
+			wantIDs:   []int{31, 23, 17},
+			wantTotal: 3,
+		},
+		{
+			name:      "by id descending",
+			options:   &woocommerce.CustomerListOptions{OrderBy: "id", Order: "desc"},
+			wantIDs:   []int{31, 23, 17},
+			wantTotal: 3,
+		},
+		{
+			name:      "second page",
+			options:   &woocommerce.CustomerListOptions{OrderBy: "id", Page: 2, PerPage: 2},
+			wantIDs:   []int{31},
+			wantTotal: 3,
+		},
+		{
+			name:      "exact email",
+			options:   &woocommerce.CustomerListOptions{Email: "Chris@Example.com"},
+			wantIDs:   []int{23},
+			wantTotal: 1,
+		},
+		{
+			name:      "search",
+			options:   &woocommerce.CustomerListOptions{Search: "lee"},
+			wantIDs:   []int{17},
+			wantTotal: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newTestClient(t)
+
+			res, err := c.ListCustomers(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("ListCustomers: %v", err)
+			}
+			if got := customerIDs(res.Customers); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("customer IDs = %v, want %v", got, tt.wantIDs)
+			}
+			if res.Pagination.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", res.Pagination.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestAllCustomers(t *testing.T) {
+	_, c := newTestClient(t)
+
+	var got []int
+	for cu, err := range c.AllCustomers(context.Background(), &woocommerce.CustomerListOptions{PerPage: 1, OrderBy: "id"}) {
+		if err != nil {
+			t.Fatalf("AllCustomers: %v", err)
+		}
+		got = append(got, cu.ID)
+	}
+
+	want := []int{17, 23, 31}
+	if !slices.Equal(got, want) {
+		t.Errorf("customer IDs = %v, want %v", got, want)
+	}
+}
+
+func TestGetCustomer(t *testing.T) {
+	_, c := newTestClient(t)
+
+	customer, err := c.GetCustomer(context.Background(), 31)
+	if err != nil {
+		t.Fatalf("GetCustomer: %v", err)
+	}
+	if customer.Billing.Company != "Bean There Cafe" {
+		t.Errorf("Billing.Company = %q, want %q", customer.Billing.Company, "Bean There Cafe")
+	}
+
+	_, err = c.GetCustomer(context.Background(), 99)
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetCustomer(99) error = %v, want HTTP 404", err)
+	}
+}
+
+func productIDs(products []woocommerce.Product) []int {
+	var ids []int
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestListProducts(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *woocommerce.ProductListOptions
+		wantIDs []int
+	}{
+		{
+			name:    "all",
+			options: nil,
+			wantIDs: []int{44, 45, 46, 47},
+		},
+		{
+			name:    "variable products",
+			options: &woocommerce.ProductListOptions{Type: woocommerce.ProductTypeVariable},
+			wantIDs: []int{47},
+		},
+		{
+			name:    "by SKU",
+			options: &woocommerce.ProductListOptions{SKU: "FLT-250-FL,ETH-250-WB"},
+			wantIDs: []int{45, 46},
+		},
+		{
+			name:    "search",
+			options: &woocommerce.ProductListOptions{Search: "espresso"},
+			wantIDs: []int{44, 47},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newTestClient(t)
+
+			res, err := c.ListProducts(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("ListProducts: %v", err)
+			}
+			if got := productIDs(res.Products); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("product IDs = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestGetProductAndVariations(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	product, err := c.GetProduct(ctx, 47)
+	if err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	if product.Type != woocommerce.ProductTypeVariable || !slices.Equal(product.Variations, []int{4701, 4702}) {
+		t.Errorf("product = %s with variations %v, want variable with [4701 4702]", product.Type, product.Variations)
+	}
+
+	var skus []string
+	for v, err := range c.AllVariations(ctx, product.ID, &woocommerce.ProductListOptions{PerPage: 1}) {
+		if err != nil {
+			t.Fatalf("AllVariations: %v", err)
+		}
+		skus = append(skus, v.SKU)
+	}
+	if want := []string{"ESP-1KG-WB", "ESP-1KG-ES"}; !slices.Equal(skus, want) {
+		t.Errorf("variation SKUs = %v, want %v", skus, want)
+	}
+
+	_, err = c.ListVariations(ctx, 99, nil)
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("ListVariations(99) error = %v, want HTTP 404", err)
+	}
+}
+
+func TestCurrentCurrency(t *testing.T) {
+	_, c := newTestClient(t)
+
+	currency, err := c.CurrentCurrency(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentCurrency: %v", err)
+	}
+	if currency.Code != woocommercetest.Currency {
+		t.Errorf("Code = %q, want %q", currency.Code, woocommercetest.Currency)
+	}
+}
+
+func TestSetStock(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	product, err := c.SetProductStock(ctx, 44, 0)
+	if err != nil {
+		t.Fatalf("SetProductStock: %v", err)
+	}
+	if !product.ManageStock || product.StockQuantity == nil || *product.StockQuantity != 0 || product.StockStatus != "outofstock" {
+		t.Errorf("product stock = %v/%v/%s, want managed, 0, outofstock", product.ManageStock, product.StockQuantity, product.StockStatus)
+	}
+
+	variation, err := c.SetVariationStock(ctx, 47, 4702, 8)
+	if err != nil {
+		t.Fatalf("SetVariationStock: %v", err)
+	}
+	if variation.StockQuantity == nil || *variation.StockQuantity != 8 || variation.StockStatus != "instock" {
+		t.Errorf("variation stock = %v/%s, want 8, instock", variation.StockQuantity, variation.StockStatus)
+	}
+
+	_, err = c.SetVariationStock(ctx, 44, 4702, 1)
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("SetVariationStock(44, 4702) error = %v, want HTTP 404", err)
+	}
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	order, err := c.UpdateOrderStatus(ctx, 5104, "completed")
+	if err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	if order.Status != "completed" || order.DateCompletedGMT == nil {
+		t.Errorf("order = %s completed at %v, want completed with a completion date", order.Status, order.DateCompletedGMT)
+	}
+	if order, _ = c.GetOrder(ctx, 5104); order.Status != "completed" {
+		t.Errorf("stored status = %q, want completed", order.Status)
+	}
+
+	_, err = c.UpdateOrderStatus(ctx, 5104, "shipped")
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("UpdateOrderStatus(shipped) error = %v, want HTTP 400", err)
+	}
+}
+
+func TestCreateOrder(t *testing.T) {
+	_, c := newTestClient(t)
+	ctx := context.Background()
+
+	order, err := c.CreateOrder(ctx, &woocommerce.NewOrder{
+		CustomerID:   23,
+		Status:       "processing",
+		CustomerNote: "Leave with reception",
+		LineItems: []woocommerce.NewOrderLineItem{
+			{ProductID: 44, Quantity: 2},
+			{ProductID: 47, VariationID: 4702, Quantity: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if order.ID != 5105 || order.Status != "processing" || order.CustomerID != 23 {
+		t.Errorf("order = #%d %s for customer %d, want #5105 processing for 23", order.ID, order.Status, order.CustomerID)
+	}
+	if len(order.LineItems) != 2 || order.LineItems[1].SKU != "ESP-1KG-ES" || order.Total != "52.00" {
+		t.Errorf("order lines = %+v total %s, want 2 lines totalling 52.00", order.LineItems, order.Total)
+	}
+	if got, err := c.GetOrder(ctx, order.ID); err != nil || got.CustomerNote != "Leave with reception" {
+		t.Errorf("GetOrder(new) = %+v, %v", got, err)
+	}
+
+	tests := []struct {
+		name  string
+		order woocommerce.NewOrder
+	}{
+		{"unknown product", woocommerce.NewOrder{LineItems: []woocommerce.NewOrderLineItem{{ProductID: 99, Quantity: 1}}}},
+		{"variable product without variation", woocommerce.NewOrder{LineItems: []woocommerce.NewOrderLineItem{{ProductID: 47, Quantity: 1}}}},
+		{"zero quantity", woocommerce.NewOrder{LineItems: []woocommerce.NewOrderLineItem{{ProductID: 44}}}},
+		{"unknown customer", woocommerce.NewOrder{CustomerID: 99, LineItems: []woocommerce.NewOrderLineItem{{ProductID: 44, Quantity: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.CreateOrder(ctx, &tt.order)
+			var reqErr *transport.Error
+			if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusBadRequest {
+				t.Errorf("CreateOrder error = %v, want HTTP 400", err)
+			}
+		})
+	}
+}
+
+func TestCreateOrderNote(t *testing.T) {
+	srv, c := newTestClient(t)
+	ctx := context.Background()
+
+	note, err := c.CreateOrderNote(ctx, 5102, "Shipped with Royal Mail, tracking number RM123456789GB", true)
+	if err != nil {
+		t.Fatalf("CreateOrderNote: %v", err)
+	}
+	if note.ID == 0 || !note.CustomerNote {
+		t.Errorf("note = %+v, want an ID and a customer note", note)
+	}
+	if notes := srv.Notes(5102); len(notes) != 1 || notes[0].Note != note.Note {
+		t.Errorf("stored notes = %+v, want the new note", notes)
+	}
+
+	_, err = c.CreateOrderNote(ctx, 9999, "lost", false)
+	var reqErr *transport.Error
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+		t.Errorf("CreateOrderNote(9999) error = %v, want HTTP 404", err)
+	}
+}
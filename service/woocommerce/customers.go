@@ -0,0 +1,158 @@
+package woocommerce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// Customer represents a registered WooCommerce customer. Guest checkouts have
+// no customer record and only appear on their orders.
+type Customer struct {
+	ID               int             `json:"id"`
+	DateCreated      string          `json:"date_created"`
+	DateCreatedGMT   string          `json:"date_created_gmt"`
+	DateModified     string          `json:"date_modified"`
+	DateModifiedGMT  string          `json:"date_modified_gmt"`
+	Email            string          `json:"email"`
+	FirstName        string          `json:"first_name"`
+	LastName         string          `json:"last_name"`
+	Role             string          `json:"role"`
+	Username         string          `json:"username"`
+	Billing          OrderAddress    `json:"billing"`
+	Shipping         OrderAddress    `json:"shipping"`
+	IsPayingCustomer bool            `json:"is_paying_customer"`
+	AvatarURL        string          `json:"avatar_url"`
+	MetaData         []OrderMetaData `json:"meta_data"`
+}
+
+// CustomersResponse represents the response when fetching multiple customers
+type CustomersResponse struct {
+	Customers  []Customer
+	Pagination *PaginationInfo
+	Headers    http.Header
+}
+
+// CustomerListOptions holds filtering options for listing customers
+type CustomerListOptions struct {
+	// Pagination
+	Page    int
+	PerPage int
+
+	// Filtering
+	Search string // Search by name, email or username
+	Email  string // Exact email address
+	Role   string // Role: "all", "customer", "subscriber", ... Defaults to "customer"
+
+	// Sorting
+	OrderBy string // Sort by: "id", "include", "name", "registered_date"
+	Order   string // Sort order: "asc", "desc"
+
+	// Additional custom parameters
+	Params map[string]string
+}
+
+// ListCustomers retrieves customers with optional filtering
+func (c *Client) ListCustomers(ctx context.Context, options *CustomerListOptions) (*CustomersResponse, error) {
+	params := make(map[string]string)
+	requestOptions := &RequestOptions{
+		Params: params,
+	}
+
+	if options != nil {
+		// Set pagination
+		requestOptions.Page = options.Page
+		requestOptions.PerPage = options.PerPage
+
+		// Set filtering parameters
+		if options.Search != "" {
+			params["search"] = options.Search
+		}
+		if options.Email != "" {
+			params["email"] = options.Email
+		}
+		if options.Role != "" {
+			params["role"] = options.Role
+		}
+		if options.OrderBy != "" {
+			params["orderby"] = options.OrderBy
+		}
+		if options.Order != "" {
+			params["order"] = options.Order
+		}
+
+		// Add any additional custom parameters
+		for key, value := range options.Params {
+			params[key] = value
+		}
+	}
+
+	response, err := c.GET(ctx, "customers", requestOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var customers []Customer
+	if response.Data != nil {
+		jsonData, err := json.Marshal(response.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal response data: %w", err)
+		}
+
+		if err := json.Unmarshal(jsonData, &customers); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal customers: %w", err)
+		}
+	}
+
+	return &CustomersResponse{
+		Customers:  customers,
+		Pagination: response.Pagination,
+		Headers:    response.Headers,
+	}, nil
+}
+
+// AllCustomers iterates over every customer matching options, walking pages until
+// the last one reported by the X-WP-TotalPages header
+func (c *Client) AllCustomers(ctx context.Context, options *CustomerListOptions) iter.Seq2[Customer, error] {
+	opts := CustomerListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.Page, func(page int) ([]Customer, *PaginationInfo, error) {
+		opts.Page = page
+		res, err := c.ListCustomers(ctx, &opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res.Customers, res.Pagination, nil
+	})
+}
+
+// GetCustomer retrieves a single customer by ID
+func (c *Client) GetCustomer(ctx context.Context, customerID int) (*Customer, error) {
+	endpoint := fmt.Sprintf("customers/%d", customerID)
+	response, err := c.GET(ctx, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer Customer
+	if response.Data != nil {
+		jsonData, err := json.Marshal(response.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal response data: %w", err)
+		}
+
+		if err := json.Unmarshal(jsonData, &customer); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal customer: %w", err)
+		}
+	}
+
+	return &customer, nil
+}
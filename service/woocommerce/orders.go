@@ -1,8 +1,10 @@
 package woocommerce
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"net/http"
 )
 
@@ -173,6 +175,9 @@ type OrderListOptions struct {
 	Before   string // Filter orders created before this date (ISO8601 format)
 	Modified string // Filter orders modified after this date (ISO8601 format)
 
+	// DatesAreGMT interprets After, Before and Modified as GMT instead of site time
+	DatesAreGMT bool
+
 	// Sorting
 	OrderBy string // Sort by: "date", "id", "include", "title", "slug"
 	Order   string // Sort order: "asc", "desc"
@@ -183,7 +188,7 @@ type OrderListOptions struct {
 }
 
 // ListOrders retrieves all orders with optional filtering
-func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error) {
+func (c *Client) ListOrders(ctx context.Context, options *OrderListOptions) (*OrdersResponse, error) {
 	params := make(map[string]string)
 	requestOptions := &RequestOptions{
 		Params: params,
@@ -217,6 +222,9 @@ func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error)
 		if options.Modified != "" {
 			params["modified_after"] = options.Modified
 		}
+		if options.DatesAreGMT {
+			params["dates_are_gmt"] = "true"
+		}
 		if options.OrderBy != "" {
 			params["orderby"] = options.OrderBy
 		}
@@ -231,7 +239,7 @@ func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error)
 		}
 	}
 
-	response, err := c.GET("orders", requestOptions)
+	response, err := c.GET(ctx, "orders", requestOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -256,10 +264,31 @@ func (c *Client) ListOrders(options *OrderListOptions) (*OrdersResponse, error)
 	}, nil
 }
 
+// AllOrders iterates over every order matching options, walking pages until
+// the last one reported by the X-WP-TotalPages header
+func (c *Client) AllOrders(ctx context.Context, options *OrderListOptions) iter.Seq2[Order, error] {
+	opts := OrderListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.Page, func(page int) ([]Order, *PaginationInfo, error) {
+		opts.Page = page
+		res, err := c.ListOrders(ctx, &opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res.Orders, res.Pagination, nil
+	})
+}
+
 // GetOrder retrieves a single order by ID
-func (c *Client) GetOrder(orderID int) (*Order, error) {
+func (c *Client) GetOrder(ctx context.Context, orderID int) (*Order, error) {
 	endpoint := fmt.Sprintf("orders/%d", orderID)
-	response, err := c.GET(endpoint, nil)
+	response, err := c.GET(ctx, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -279,6 +308,80 @@ func (c *Client) GetOrder(orderID int) (*Order, error) {
 	return &order, nil
 }
 
+// UpdateOrderStatus moves an order to status, e.g. "completed", and returns
+// the updated order. WooCommerce emails the customer on the transitions it
+// has emails for, such as completion.
+func (c *Client) UpdateOrderStatus(ctx context.Context, orderID int, status string) (*Order, error) {
+	response, err := c.PUT(ctx, fmt.Sprintf("orders/%d", orderID), map[string]string{"status": status}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var order Order
+	if err := decodeData(response.Data, &order); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+	return &order, nil
+}
+
+// NewOrder is an order to create. WooCommerce prices the line items from the
+// products' current prices and totals the order itself.
+type NewOrder struct {
+	CustomerID   int                `json:"customer_id"` // zero for a guest order
+	Status       string             `json:"status,omitempty"`
+	Billing      *OrderAddress      `json:"billing,omitempty"`
+	Shipping     *OrderAddress      `json:"shipping,omitempty"`
+	CustomerNote string             `json:"customer_note,omitempty"`
+	LineItems    []NewOrderLineItem `json:"line_items"`
+	MetaData     []OrderMetaData    `json:"meta_data,omitempty"`
+}
+
+// NewOrderLineItem is a product and quantity to order. VariationID is set for
+// a variation of a variable product, with ProductID its parent.
+type NewOrderLineItem struct {
+	ProductID   int `json:"product_id"`
+	VariationID int `json:"variation_id,omitempty"`
+	Quantity    int `json:"quantity"`
+}
+
+// CreateOrder places an order and returns it as stored by WooCommerce
+func (c *Client) CreateOrder(ctx context.Context, order *NewOrder) (*Order, error) {
+	response, err := c.POST(ctx, "orders", order, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var created Order
+	if err := decodeData(response.Data, &created); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+	return &created, nil
+}
+
+// OrderNote is a note on an order's history
+type OrderNote struct {
+	ID           int    `json:"id,omitempty"`
+	Author       string `json:"author,omitempty"`
+	DateCreated  string `json:"date_created,omitempty"`
+	Note         string `json:"note"`
+	CustomerNote bool   `json:"customer_note"` // emailed to the customer and shown in their account
+}
+
+// CreateOrderNote adds a note to an order. A customer note is also emailed to the customer.
+func (c *Client) CreateOrderNote(ctx context.Context, orderID int, note string, customerNote bool) (*OrderNote, error) {
+	body := OrderNote{Note: note, CustomerNote: customerNote}
+	response, err := c.POST(ctx, fmt.Sprintf("orders/%d/notes", orderID), body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var created OrderNote
+	if err := decodeData(response.Data, &created); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order note: %w", err)
+	}
+	return &created, nil
+}
+
 // IsSubscriptionOrder checks if an order is related to subscriptions
 func (c *Client) IsSubscriptionOrder(order *Order) bool {
 	// Method 1: Check if order was created via subscription
@@ -349,9 +452,9 @@ func parseIntFromString(s string) int {
 }
 
 // ListSubscriptionOrders retrieves only subscription-related orders
-func (c *Client) ListSubscriptionOrders(options *OrderListOptions) (*OrdersResponse, error) {
+func (c *Client) ListSubscriptionOrders(ctx context.Context, options *OrderListOptions) (*OrdersResponse, error) {
 	// Get all orders first
-	ordersResponse, err := c.ListOrders(options)
+	ordersResponse, err := c.ListOrders(ctx, options)
 	if err != nil {
 		return nil, err
 	}
@@ -372,9 +475,9 @@ func (c *Client) ListSubscriptionOrders(options *OrderListOptions) (*OrdersRespo
 }
 
 // ListSubscriptionRenewals retrieves only subscription renewal orders
-func (c *Client) ListSubscriptionRenewals(options *OrderListOptions) (*OrdersResponse, error) {
+func (c *Client) ListSubscriptionRenewals(ctx context.Context, options *OrderListOptions) (*OrdersResponse, error) {
 	// Get all orders first
-	ordersResponse, err := c.ListOrders(options)
+	ordersResponse, err := c.ListOrders(ctx, options)
 	if err != nil {
 		return nil, err
 	}
@@ -395,7 +498,7 @@ func (c *Client) ListSubscriptionRenewals(options *OrderListOptions) (*OrdersRes
 }
 
 // GetLastOrders retrieves the most recent orders, sorted by date ascending
-func (c *Client) GetLastOrders(count int) (*OrdersResponse, error) {
+func (c *Client) GetLastOrders(ctx context.Context, count int) (*OrdersResponse, error) {
 	options := &OrderListOptions{
 		Page:    1,
 		PerPage: count,
@@ -403,10 +506,10 @@ func (c *Client) GetLastOrders(count int) (*OrdersResponse, error) {
 		Order:   "desc",
 	}
 
-	return c.ListOrders(options)
+	return c.ListOrders(ctx, options)
 }
 
 // GetLast10Orders is a convenience method to get the last 10 orders
-func (c *Client) GetLast10Orders() (*OrdersResponse, error) {
-	return c.GetLastOrders(10)
+func (c *Client) GetLast10Orders(ctx context.Context) (*OrdersResponse, error) {
+	return c.GetLastOrders(ctx, 10)
 }
\ No newline at end of file
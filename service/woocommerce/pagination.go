@@ -0,0 +1,43 @@
+package woocommerce
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is the page size used by the All* iterators when none is given
+const DefaultPageSize = 50
+
+// paginate yields every item from a page-numbered endpoint. fetch is called with
+// the page to load and returns its items and the pagination info parsed from the
+// X-WP-Total/X-WP-TotalPages headers. Iteration stops on the first error, after the
+// last page, or when the caller stops ranging.
+func paginate[T any](ctx context.Context, startPage int, fetch func(page int) ([]T, *PaginationInfo, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		page := max(startPage, 1)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			items, pagination, err := fetch(page)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(items) == 0 || pagination == nil || page >= pagination.TotalPages {
+				return
+			}
+			page++
+		}
+	}
+}
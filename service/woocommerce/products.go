@@ -0,0 +1,321 @@
+package woocommerce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// Product types reported by WooCommerce
+const (
+	ProductTypeSimple   = "simple"
+	ProductTypeGrouped  = "grouped"
+	ProductTypeVariable = "variable"
+)
+
+// Product represents a WooCommerce product. Variable products are not sold
+// directly; their variations carry the SKUs and prices.
+type Product struct {
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	Slug            string             `json:"slug"`
+	Permalink       string             `json:"permalink"`
+	DateModifiedGMT string             `json:"date_modified_gmt"`
+	Type            string             `json:"type"`
+	Status          string             `json:"status"`
+	SKU             string             `json:"sku"`
+	Price           string             `json:"price"`
+	RegularPrice    string             `json:"regular_price"`
+	SalePrice       string             `json:"sale_price"`
+	OnSale          bool               `json:"on_sale"`
+	Purchasable     bool               `json:"purchasable"`
+	ManageStock     bool               `json:"manage_stock"`
+	StockQuantity   *int               `json:"stock_quantity"`
+	StockStatus     string             `json:"stock_status"`
+	Weight          string             `json:"weight"`
+	Categories      []ProductCategory  `json:"categories"`
+	Attributes      []ProductAttribute `json:"attributes"`
+	Variations      []int              `json:"variations"`
+	MetaData        []OrderMetaData    `json:"meta_data"`
+}
+
+// ProductCategory is a category a product is filed under
+type ProductCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// ProductAttribute is an attribute of a product, such as grind, and its possible values
+type ProductAttribute struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Position  int      `json:"position"`
+	Visible   bool     `json:"visible"`
+	Variation bool     `json:"variation"` // whether the attribute is used to define variations
+	Options   []string `json:"options"`
+}
+
+// Variation represents one variation of a variable product
+type Variation struct {
+	ID              int                  `json:"id"`
+	ParentID        int                  `json:"parent_id"`
+	DateModifiedGMT string               `json:"date_modified_gmt"`
+	Status          string               `json:"status"`
+	SKU             string               `json:"sku"`
+	Price           string               `json:"price"`
+	RegularPrice    string               `json:"regular_price"`
+	SalePrice       string               `json:"sale_price"`
+	OnSale          bool                 `json:"on_sale"`
+	Purchasable     bool                 `json:"purchasable"`
+	ManageStock     bool                 `json:"manage_stock"`
+	StockQuantity   *int                 `json:"stock_quantity"`
+	StockStatus     string               `json:"stock_status"`
+	Weight          string               `json:"weight"`
+	Attributes      []VariationAttribute `json:"attributes"`
+	MetaData        []OrderMetaData      `json:"meta_data"`
+}
+
+// VariationAttribute is the attribute value chosen for a variation
+type VariationAttribute struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Option string `json:"option"`
+}
+
+// ProductsResponse represents the response when fetching multiple products
+type ProductsResponse struct {
+	Products   []Product
+	Pagination *PaginationInfo
+	Headers    http.Header
+}
+
+// VariationsResponse represents the response when fetching a product's variations
+type VariationsResponse struct {
+	Variations []Variation
+	Pagination *PaginationInfo
+	Headers    http.Header
+}
+
+// ProductListOptions holds filtering options for listing products or variations
+type ProductListOptions struct {
+	// Pagination
+	Page    int
+	PerPage int
+
+	// Filtering
+	Search string // Search by product name
+	SKU    string // Comma-separated list of SKUs
+	Status string // Status: "any", "draft", "pending", "private", "publish"
+	Type   string // Type: "simple", "grouped", "external", "variable" (products only)
+
+	// Sorting
+	OrderBy string // Sort by: "date", "id", "include", "title", "slug", "price"
+	Order   string // Sort order: "asc", "desc"
+
+	// Additional custom parameters
+	Params map[string]string
+}
+
+// requestOptions converts the list options into request parameters
+func (o *ProductListOptions) requestOptions() *RequestOptions {
+	params := make(map[string]string)
+	requestOptions := &RequestOptions{
+		Params: params,
+	}
+	if o == nil {
+		return requestOptions
+	}
+
+	// Set pagination
+	requestOptions.Page = o.Page
+	requestOptions.PerPage = o.PerPage
+
+	// Set filtering parameters
+	if o.Search != "" {
+		params["search"] = o.Search
+	}
+	if o.SKU != "" {
+		params["sku"] = o.SKU
+	}
+	if o.Status != "" {
+		params["status"] = o.Status
+	}
+	if o.Type != "" {
+		params["type"] = o.Type
+	}
+	if o.OrderBy != "" {
+		params["orderby"] = o.OrderBy
+	}
+	if o.Order != "" {
+		params["order"] = o.Order
+	}
+
+	// Add any additional custom parameters
+	for key, value := range o.Params {
+		params[key] = value
+	}
+	return requestOptions
+}
+
+// ListProducts retrieves products with optional filtering
+func (c *Client) ListProducts(ctx context.Context, options *ProductListOptions) (*ProductsResponse, error) {
+	response, err := c.GET(ctx, "products", options.requestOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var products []Product
+	if err := decodeData(response.Data, &products); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal products: %w", err)
+	}
+
+	return &ProductsResponse{
+		Products:   products,
+		Pagination: response.Pagination,
+		Headers:    response.Headers,
+	}, nil
+}
+
+// AllProducts iterates over every product matching options, walking pages until
+// the last one reported by the X-WP-TotalPages header
+func (c *Client) AllProducts(ctx context.Context, options *ProductListOptions) iter.Seq2[Product, error] {
+	opts := ProductListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.Page, func(page int) ([]Product, *PaginationInfo, error) {
+		opts.Page = page
+		res, err := c.ListProducts(ctx, &opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res.Products, res.Pagination, nil
+	})
+}
+
+// GetProduct retrieves a single product by ID
+func (c *Client) GetProduct(ctx context.Context, productID int) (*Product, error) {
+	response, err := c.GET(ctx, fmt.Sprintf("products/%d", productID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var product Product
+	if err := decodeData(response.Data, &product); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, nil
+}
+
+// ListVariations retrieves the variations of a variable product
+func (c *Client) ListVariations(ctx context.Context, productID int, options *ProductListOptions) (*VariationsResponse, error) {
+	response, err := c.GET(ctx, fmt.Sprintf("products/%d/variations", productID), options.requestOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var variations []Variation
+	if err := decodeData(response.Data, &variations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal variations: %w", err)
+	}
+
+	return &VariationsResponse{
+		Variations: variations,
+		Pagination: response.Pagination,
+		Headers:    response.Headers,
+	}, nil
+}
+
+// AllVariations iterates over every variation of a variable product
+func (c *Client) AllVariations(ctx context.Context, productID int, options *ProductListOptions) iter.Seq2[Variation, error] {
+	opts := ProductListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = DefaultPageSize
+	}
+
+	return paginate(ctx, opts.Page, func(page int) ([]Variation, *PaginationInfo, error) {
+		opts.Page = page
+		res, err := c.ListVariations(ctx, productID, &opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res.Variations, res.Pagination, nil
+	})
+}
+
+// stockUpdate is the body that switches on stock management and sets the quantity.
+// WooCommerce derives stock_status from the quantity.
+func stockUpdate(quantity int) map[string]any {
+	return map[string]any{"manage_stock": true, "stock_quantity": quantity}
+}
+
+// SetProductStock sets the stock quantity of a simple product and returns the updated product
+func (c *Client) SetProductStock(ctx context.Context, productID, quantity int) (*Product, error) {
+	response, err := c.PUT(ctx, fmt.Sprintf("products/%d", productID), stockUpdate(quantity), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var product Product
+	if err := decodeData(response.Data, &product); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, nil
+}
+
+// SetVariationStock sets the stock quantity of one variation of a variable product
+func (c *Client) SetVariationStock(ctx context.Context, productID, variationID, quantity int) (*Variation, error) {
+	response, err := c.PUT(ctx, fmt.Sprintf("products/%d/variations/%d", productID, variationID), stockUpdate(quantity), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var variation Variation
+	if err := decodeData(response.Data, &variation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal variation: %w", err)
+	}
+	return &variation, nil
+}
+
+// Currency is a currency supported by the store
+type Currency struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// CurrentCurrency retrieves the currency the store's prices are in
+func (c *Client) CurrentCurrency(ctx context.Context) (*Currency, error) {
+	response, err := c.GET(ctx, "data/currencies/current", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var currency Currency
+	if err := decodeData(response.Data, &currency); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal currency: %w", err)
+	}
+	return &currency, nil
+}
+
+// decodeData converts the generic JSON held in Response.Data into v
+func decodeData(data interface{}, v any) error {
+	if data == nil {
+		return nil
+	}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response data: %w", err)
+	}
+	return json.Unmarshal(jsonData, v)
+}
@@ -0,0 +1,698 @@
+// Package woocommercetest provides an in-memory fake of the WooCommerce REST API for tests.
+//
+// The fake serves /wp-json/wc/v3/orders, customers, products and product
+// variations, including stock updates to products and variations, order
+// creation, status updates and order notes, with basic auth, X-WP-Total and X-WP-TotalPages headers, status/after/before/search
+// filtering and WooCommerce-shaped error bodies, seeded from fixture JSON in testdata.
+package woocommercetest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dukerupert/paddy-cap/service/woocommerce"
+)
+
+// Credentials accepted by the fake
+const (
+	ConsumerKey    = "ck_test"
+	ConsumerSecret = "cs_test"
+)
+
+//go:embed testdata/orders.json
+var ordersFixture []byte
+
+// Orders returns the fixture orders, newest first
+func Orders() []woocommerce.Order {
+	var orders []woocommerce.Order
+	if err := json.Unmarshal(ordersFixture, &orders); err != nil {
+		panic("woocommercetest: invalid orders fixture: " + err.Error())
+	}
+	return orders
+}
+
+//go:embed testdata/customers.json
+var customersFixture []byte
+
+// Customers returns the fixture customers in ID order
+func Customers() []woocommerce.Customer {
+	var customers []woocommerce.Customer
+	if err := json.Unmarshal(customersFixture, &customers); err != nil {
+		panic("woocommercetest: invalid customers fixture: " + err.Error())
+	}
+	return customers
+}
+
+//go:embed testdata/products.json
+var productsFixture []byte
+
+//go:embed testdata/variations.json
+var variationsFixture []byte
+
+// Products returns the fixture products in ID order
+func Products() []woocommerce.Product {
+	var products []woocommerce.Product
+	if err := json.Unmarshal(productsFixture, &products); err != nil {
+		panic("woocommercetest: invalid products fixture: " + err.Error())
+	}
+	return products
+}
+
+// Variations returns the fixture variations of every variable product in Products
+func Variations() []woocommerce.Variation {
+	var variations []woocommerce.Variation
+	if err := json.Unmarshal(variationsFixture, &variations); err != nil {
+		panic("woocommercetest: invalid variations fixture: " + err.Error())
+	}
+	return variations
+}
+
+// Currency is the store currency reported by the fake
+const Currency = "GBP"
+
+// Server is a fake WooCommerce API backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	orders     []woocommerce.Order
+	customers  []woocommerce.Customer
+	products   []woocommerce.Product
+	variations []woocommerce.Variation
+	notes      map[int][]woocommerce.OrderNote // by order ID, oldest first
+	nextNoteID int
+}
+
+// NewServer starts a fake WooCommerce API serving the given orders, customers,
+// products and variations, which are matched to products by ParentID.
+// Callers should Close it when done.
+func NewServer(orders []woocommerce.Order, customers []woocommerce.Customer, products []woocommerce.Product, variations []woocommerce.Variation) *Server {
+	s := &Server{orders: orders, customers: customers, products: products, variations: variations, notes: map[int][]woocommerce.OrderNote{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /wp-json/wc/v3/orders", s.authenticated(s.handleListOrders))
+	mux.HandleFunc("POST /wp-json/wc/v3/orders", s.authenticated(s.handleCreateOrder))
+	mux.HandleFunc("GET /wp-json/wc/v3/orders/{id}", s.authenticated(s.handleGetOrder))
+	mux.HandleFunc("PUT /wp-json/wc/v3/orders/{id}", s.authenticated(s.handleUpdateOrder))
+	mux.HandleFunc("POST /wp-json/wc/v3/orders/{id}/notes", s.authenticated(s.handleCreateOrderNote))
+	mux.HandleFunc("GET /wp-json/wc/v3/customers", s.authenticated(s.handleListCustomers))
+	mux.HandleFunc("GET /wp-json/wc/v3/customers/{id}", s.authenticated(s.handleGetCustomer))
+	mux.HandleFunc("GET /wp-json/wc/v3/products", s.authenticated(s.handleListProducts))
+	mux.HandleFunc("GET /wp-json/wc/v3/products/{id}", s.authenticated(s.handleGetProduct))
+	mux.HandleFunc("PUT /wp-json/wc/v3/products/{id}", s.authenticated(s.handleUpdateProductStock))
+	mux.HandleFunc("GET /wp-json/wc/v3/products/{id}/variations", s.authenticated(s.handleListVariations))
+	mux.HandleFunc("PUT /wp-json/wc/v3/products/{id}/variations/{variation}", s.authenticated(s.handleUpdateVariationStock))
+	mux.HandleFunc("GET /wp-json/wc/v3/data/currencies/current", s.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, woocommerce.Currency{Code: Currency, Name: "British pound", Symbol: "&pound;"})
+	}))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// NewClient returns a woocommerce.Client configured to talk to the fake
+func (s *Server) NewClient() *woocommerce.Client {
+	return woocommerce.NewClient(s.URL, ConsumerKey, ConsumerSecret)
+}
+
+// Notes returns the notes added to an order, oldest first
+func (s *Server) Notes(orderID int) []woocommerce.OrderNote {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.notes[orderID])
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, secret, ok := r.BasicAuth()
+		if !ok || key != ConsumerKey || secret != ConsumerSecret {
+			writeError(w, http.StatusUnauthorized, "woocommerce_rest_cannot_view", "Sorry, you cannot list resources.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, perPage, ok := pageParams(w, q)
+	if !ok {
+		return
+	}
+
+	// WooCommerce compares dates in site time unless dates_are_gmt is set
+	created := func(o woocommerce.Order) string { return o.DateCreated }
+	modified := func(o woocommerce.Order) string { return o.DateModified }
+	if q.Get("dates_are_gmt") == "true" {
+		created = func(o woocommerce.Order) string { return o.DateCreatedGMT }
+		modified = func(o woocommerce.Order) string { return o.DateModifiedGMT }
+	}
+
+	search := strings.ToLower(q.Get("search"))
+	statuses := strings.Split(q.Get("status"), ",")
+
+	s.mu.Lock()
+	matched := slices.DeleteFunc(slices.Clone(s.orders), func(o woocommerce.Order) bool {
+		return (q.Get("status") != "" && q.Get("status") != "any" && !slices.Contains(statuses, o.Status)) ||
+			(q.Get("after") != "" && created(o) <= q.Get("after")) ||
+			(q.Get("before") != "" && created(o) >= q.Get("before")) ||
+			(q.Get("modified_after") != "" && modified(o) <= q.Get("modified_after")) ||
+			(q.Get("customer") != "" && strconv.Itoa(o.CustomerID) != q.Get("customer")) ||
+			(search != "" && !matchesSearch(o, search))
+	})
+	s.mu.Unlock()
+
+	sortOrders(matched, q.Get("orderby"), q.Get("order"))
+	writePage(w, matched, page, perPage)
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+
+	s.mu.Lock()
+	i := slices.IndexFunc(s.orders, func(o woocommerce.Order) bool { return o.ID == id })
+	var order woocommerce.Order
+	if i >= 0 {
+		order = s.orders[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_shop_order_invalid_id", "Invalid ID.")
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// handleCreateOrder places an order for known products, pricing and totalling
+// it the way WooCommerce does for prices that include tax
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	var req woocommerce.NewOrder
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+		return
+	}
+	if req.Status == "" {
+		req.Status = "pending"
+	}
+	if !slices.Contains(orderStatuses, req.Status) {
+		writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): status")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.CustomerID != 0 && !slices.ContainsFunc(s.customers, func(c woocommerce.Customer) bool { return c.ID == req.CustomerID }) {
+		writeError(w, http.StatusBadRequest, "woocommerce_rest_invalid_customer_id", "Customer ID is invalid.")
+		return
+	}
+
+	id, lineID := 0, 0
+	for _, o := range s.orders {
+		id = max(id, o.ID)
+		for _, line := range o.LineItems {
+			lineID = max(lineID, line.ID)
+		}
+	}
+	id++
+
+	now := time.Now()
+	order := woocommerce.Order{
+		ID:               id,
+		Number:           strconv.Itoa(id),
+		CreatedVia:       "rest-api",
+		Status:           req.Status,
+		Currency:         Currency,
+		DateCreated:      now.Format(dateLayout),
+		DateCreatedGMT:   now.UTC().Format(dateLayout),
+		DiscountTotal:    "0.00",
+		ShippingTotal:    "0.00",
+		TotalTax:         "0.00",
+		PricesIncludeTax: true,
+		CustomerID:       req.CustomerID,
+		CustomerNote:     req.CustomerNote,
+		MetaData:         req.MetaData,
+	}
+	if req.Billing != nil {
+		order.Billing = *req.Billing
+	}
+	if req.Shipping != nil {
+		order.Shipping = *req.Shipping
+	}
+
+	var total float64
+	for _, item := range req.LineItems {
+		if item.Quantity <= 0 {
+			writeError(w, http.StatusBadRequest, "woocommerce_rest_invalid_product_quantity", "Product quantity must be a positive float.")
+			return
+		}
+		pi := slices.IndexFunc(s.products, func(p woocommerce.Product) bool { return p.ID == item.ProductID })
+		if pi < 0 {
+			writeError(w, http.StatusBadRequest, "woocommerce_rest_invalid_product_id", "Product ID provided is invalid.")
+			return
+		}
+		product := s.products[pi]
+		name, sku, price := product.Name, product.SKU, product.Price
+		if product.Type == woocommerce.ProductTypeVariable || item.VariationID != 0 {
+			vi := slices.IndexFunc(s.variations, func(v woocommerce.Variation) bool {
+				return v.ID == item.VariationID && v.ParentID == product.ID
+			})
+			if vi < 0 {
+				writeError(w, http.StatusBadRequest, "woocommerce_rest_invalid_variation_id", "Variation ID provided is invalid.")
+				return
+			}
+			variation := s.variations[vi]
+			sku, price = variation.SKU, variation.Price
+			var options []string
+			for _, a := range variation.Attributes {
+				options = append(options, a.Option)
+			}
+			if len(options) > 0 {
+				name += " - " + strings.Join(options, ", ")
+			}
+		}
+
+		unit, _ := strconv.ParseFloat(price, 64)
+		subtotal := fmt.Sprintf("%.2f", unit*float64(item.Quantity))
+		total += unit * float64(item.Quantity)
+		lineID++
+		order.LineItems = append(order.LineItems, woocommerce.OrderLineItem{
+			ID:          lineID,
+			Name:        name,
+			ProductID:   item.ProductID,
+			VariationID: item.VariationID,
+			Quantity:    item.Quantity,
+			Subtotal:    subtotal,
+			SubtotalTax: "0.00",
+			Total:       subtotal,
+			TotalTax:    "0.00",
+			SKU:         sku,
+			Price:       unit,
+		})
+	}
+	order.Total = fmt.Sprintf("%.2f", total)
+
+	s.orders = append([]woocommerce.Order{order}, s.orders...)
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// orderStatuses are the statuses an order can be moved to
+var orderStatuses = []string{"pending", "processing", "on-hold", "completed", "cancelled", "refunded", "failed", "trash", "checkout-draft"}
+
+// handleUpdateOrder changes an order's status, stamping the completion date the
+// way WooCommerce does when an order is completed
+func (s *Server) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+	var update struct {
+		Status *string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+		return
+	}
+	if update.Status != nil && !slices.Contains(orderStatuses, *update.Status) {
+		writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): status")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.orderIndex(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_shop_order_invalid_id", "Invalid ID.")
+		return
+	}
+	o := &s.orders[i]
+	if update.Status != nil && *update.Status != o.Status {
+		o.Status = *update.Status
+		if o.Status == "completed" {
+			now := time.Now()
+			local, gmt := now.Format(dateLayout), now.UTC().Format(dateLayout)
+			o.DateCompleted, o.DateCompletedGMT = &local, &gmt
+		}
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (s *Server) handleCreateOrderNote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+	var note woocommerce.OrderNote
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		writeError(w, http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+		return
+	}
+	if strings.TrimSpace(note.Note) == "" {
+		writeError(w, http.StatusBadRequest, "rest_missing_callback_param", "Missing parameter(s): note")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.orderIndex(id) < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_order_invalid_id", "Invalid order ID.")
+		return
+	}
+	s.nextNoteID++
+	note.ID = s.nextNoteID
+	note.Author = "WooCommerce"
+	note.DateCreated = time.Now().Format(dateLayout)
+	s.notes[id] = append(s.notes[id], note)
+	writeJSON(w, http.StatusCreated, note)
+}
+
+// orderIndex returns the position of the order with the given ID, or -1. s.mu must be held.
+func (s *Server) orderIndex(id int) int {
+	return slices.IndexFunc(s.orders, func(o woocommerce.Order) bool { return o.ID == id })
+}
+
+// dateLayout is how WooCommerce formats dates, without a timezone
+const dateLayout = "2006-01-02T15:04:05"
+
+func (s *Server) handleListCustomers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, perPage, ok := pageParams(w, q)
+	if !ok {
+		return
+	}
+
+	search := strings.ToLower(q.Get("search"))
+
+	s.mu.Lock()
+	matched := slices.DeleteFunc(slices.Clone(s.customers), func(c woocommerce.Customer) bool {
+		return (q.Get("email") != "" && !strings.EqualFold(c.Email, q.Get("email"))) ||
+			(q.Get("role") != "" && q.Get("role") != "all" && c.Role != q.Get("role")) ||
+			(search != "" && !matchesCustomerSearch(c, search))
+	})
+	s.mu.Unlock()
+
+	// WooCommerce lists customers by name, ascending, unless told otherwise
+	cmp := func(a, b woocommerce.Customer) int {
+		if q.Get("orderby") == "id" {
+			return a.ID - b.ID
+		}
+		return strings.Compare(a.FirstName+" "+a.LastName, b.FirstName+" "+b.LastName)
+	}
+	if q.Get("order") == "desc" {
+		slices.SortStableFunc(matched, func(a, b woocommerce.Customer) int { return cmp(b, a) })
+	} else {
+		slices.SortStableFunc(matched, cmp)
+	}
+
+	writePage(w, matched, page, perPage)
+}
+
+func (s *Server) handleGetCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+
+	s.mu.Lock()
+	i := slices.IndexFunc(s.customers, func(c woocommerce.Customer) bool { return c.ID == id })
+	var customer woocommerce.Customer
+	if i >= 0 {
+		customer = s.customers[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_invalid_id", "Invalid resource ID.")
+		return
+	}
+	writeJSON(w, http.StatusOK, customer)
+}
+
+func (s *Server) handleListProducts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, perPage, ok := pageParams(w, q)
+	if !ok {
+		return
+	}
+
+	search := strings.ToLower(q.Get("search"))
+	skus := strings.Split(q.Get("sku"), ",")
+
+	s.mu.Lock()
+	matched := slices.DeleteFunc(slices.Clone(s.products), func(p woocommerce.Product) bool {
+		return (q.Get("status") != "" && q.Get("status") != "any" && p.Status != q.Get("status")) ||
+			(q.Get("type") != "" && p.Type != q.Get("type")) ||
+			(q.Get("sku") != "" && !slices.Contains(skus, p.SKU)) ||
+			(search != "" && !strings.Contains(strings.ToLower(p.Name), search))
+	})
+	s.mu.Unlock()
+
+	writePage(w, matched, page, perPage)
+}
+
+func (s *Server) handleGetProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+
+	s.mu.Lock()
+	i := slices.IndexFunc(s.products, func(p woocommerce.Product) bool { return p.ID == id })
+	var product woocommerce.Product
+	if i >= 0 {
+		product = s.products[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_product_invalid_id", "Invalid ID.")
+		return
+	}
+	writeJSON(w, http.StatusOK, product)
+}
+
+func (s *Server) handleUpdateProductStock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+	update, ok := decodeStockUpdate(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.products, func(p woocommerce.Product) bool { return p.ID == id })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_product_invalid_id", "Invalid ID.")
+		return
+	}
+	p := &s.products[i]
+	p.ManageStock, p.StockQuantity, p.StockStatus = update.apply(p.ManageStock, p.StockQuantity, p.StockStatus)
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleUpdateVariationStock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+	variationID, err := strconv.Atoi(r.PathValue("variation"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+	update, ok := decodeStockUpdate(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.variations, func(v woocommerce.Variation) bool { return v.ID == variationID && v.ParentID == id })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_product_variation_invalid_id", "Invalid ID.")
+		return
+	}
+	v := &s.variations[i]
+	v.ManageStock, v.StockQuantity, v.StockStatus = update.apply(v.ManageStock, v.StockQuantity, v.StockStatus)
+	writeJSON(w, http.StatusOK, v)
+}
+
+// stockUpdate holds the stock fields of a product or variation update
+type stockUpdate struct {
+	ManageStock   *bool `json:"manage_stock"`
+	StockQuantity *int  `json:"stock_quantity"`
+}
+
+// apply returns the stock fields after the update, deriving the stock status
+// from the quantity the way WooCommerce does when stock is managed
+func (u stockUpdate) apply(manage bool, quantity *int, status string) (bool, *int, string) {
+	if u.ManageStock != nil {
+		manage = *u.ManageStock
+	}
+	if u.StockQuantity != nil {
+		quantity = u.StockQuantity
+	}
+	if manage && quantity != nil {
+		status = "instock"
+		if *quantity <= 0 {
+			status = "outofstock"
+		}
+	}
+	return manage, quantity, status
+}
+
+func decodeStockUpdate(w http.ResponseWriter, r *http.Request) (stockUpdate, bool) {
+	var update stockUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+		return stockUpdate{}, false
+	}
+	return update, true
+}
+
+func (s *Server) handleListVariations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+		return
+	}
+	page, perPage, ok := pageParams(w, q)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	found := slices.ContainsFunc(s.products, func(p woocommerce.Product) bool { return p.ID == id })
+	matched := slices.DeleteFunc(slices.Clone(s.variations), func(v woocommerce.Variation) bool {
+		return v.ParentID != id || (q.Get("sku") != "" && v.SKU != q.Get("sku"))
+	})
+	s.mu.Unlock()
+
+	if !found {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_product_invalid_id", "Invalid ID.")
+		return
+	}
+	writePage(w, matched, page, perPage)
+}
+
+// pageParams reads and validates the page and per_page parameters, writing an error if either is invalid
+func pageParams(w http.ResponseWriter, q url.Values) (page, perPage int, ok bool) {
+	page, err := positiveInt(q.Get("page"), 1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): page")
+		return 0, 0, false
+	}
+	perPage, err = positiveInt(q.Get("per_page"), 10)
+	if err != nil || perPage > 100 {
+		writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): per_page")
+		return 0, 0, false
+	}
+	return page, perPage, true
+}
+
+func matchesCustomerSearch(c woocommerce.Customer, search string) bool {
+	fields := []string{c.FirstName, c.LastName, c.Email, c.Username}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSearch(o woocommerce.Order, search string) bool {
+	fields := []string{o.Number, o.Billing.FirstName, o.Billing.LastName, o.Billing.Email, o.Billing.Company}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortOrders applies the orderby/order parameters, defaulting to newest first like WooCommerce
+func sortOrders(orders []woocommerce.Order, orderBy, order string) {
+	cmp := func(a, b woocommerce.Order) int {
+		if orderBy == "id" {
+			return a.ID - b.ID
+		}
+		return strings.Compare(a.DateCreatedGMT, b.DateCreatedGMT)
+	}
+	if order == "asc" {
+		slices.SortStableFunc(orders, cmp)
+		return
+	}
+	slices.SortStableFunc(orders, func(a, b woocommerce.Order) int { return cmp(b, a) })
+}
+
+// writePage writes one page of items along with the X-WP-Total and X-WP-TotalPages headers
+func writePage[T any](w http.ResponseWriter, items []T, page, perPage int) {
+	total := len(items)
+	totalPages := (total + perPage - 1) / perPage
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+
+	w.Header().Set("X-WP-Total", strconv.Itoa(total))
+	w.Header().Set("X-WP-TotalPages", strconv.Itoa(totalPages))
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+func positiveInt(s string, fallback int) (int, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid positive integer %q", s)
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the shape WooCommerce uses for REST errors
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{
+		"code":    code,
+		"message": message,
+		"data":    map[string]int{"status": status},
+	})
+}